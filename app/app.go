@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -17,6 +18,10 @@ import (
 	"github.com/oniharnantyo/golang-backend-example/database"
 	"github.com/oniharnantyo/golang-backend-example/database/migration"
 	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/keystore"
+	"github.com/oniharnantyo/golang-backend-example/middleware"
+	"github.com/oniharnantyo/golang-backend-example/notifier"
+	"github.com/oniharnantyo/golang-backend-example/util"
 	"github.com/pkg/errors"
 
 	"github.com/sirupsen/logrus"
@@ -25,11 +30,32 @@ import (
 	delivery_http_account "github.com/oniharnantyo/golang-backend-example/services/account/delivery/http"
 	repository_account "github.com/oniharnantyo/golang-backend-example/services/account/repository"
 	usecase_account "github.com/oniharnantyo/golang-backend-example/services/account/usecase"
+	delivery_http_auth "github.com/oniharnantyo/golang-backend-example/services/auth/delivery/http"
 	repository_auth "github.com/oniharnantyo/golang-backend-example/services/auth/repository"
 	usecase_auth "github.com/oniharnantyo/golang-backend-example/services/auth/usecase"
 	delivery_http_customer "github.com/oniharnantyo/golang-backend-example/services/customer/delivery/http"
 	repository_customer "github.com/oniharnantyo/golang-backend-example/services/customer/repository"
 	usecase_customer "github.com/oniharnantyo/golang-backend-example/services/customer/usecase"
+	delivery_http_fxrate "github.com/oniharnantyo/golang-backend-example/services/fxrate/delivery/http"
+	repository_fxrate "github.com/oniharnantyo/golang-backend-example/services/fxrate/repository"
+	usecase_fxrate "github.com/oniharnantyo/golang-backend-example/services/fxrate/usecase"
+	delivery_http_hold "github.com/oniharnantyo/golang-backend-example/services/hold/delivery/http"
+	repository_hold "github.com/oniharnantyo/golang-backend-example/services/hold/repository"
+	usecase_hold "github.com/oniharnantyo/golang-backend-example/services/hold/usecase"
+	repository_idempotency "github.com/oniharnantyo/golang-backend-example/services/idempotency/repository"
+	repository_ledger "github.com/oniharnantyo/golang-backend-example/services/ledger/repository"
+	repository_loginattempt "github.com/oniharnantyo/golang-backend-example/services/loginattempt/repository"
+	delivery_http_mfa "github.com/oniharnantyo/golang-backend-example/services/mfa/delivery/http"
+	repository_mfa "github.com/oniharnantyo/golang-backend-example/services/mfa/repository"
+	usecase_mfa "github.com/oniharnantyo/golang-backend-example/services/mfa/usecase"
+	repository_passwordreset "github.com/oniharnantyo/golang-backend-example/services/passwordreset/repository"
+	delivery_http_scheduledtransfer "github.com/oniharnantyo/golang-backend-example/services/scheduledtransfer/delivery/http"
+	repository_scheduledtransfer "github.com/oniharnantyo/golang-backend-example/services/scheduledtransfer/repository"
+	usecase_scheduledtransfer "github.com/oniharnantyo/golang-backend-example/services/scheduledtransfer/usecase"
+	repository_transfer "github.com/oniharnantyo/golang-backend-example/services/transfer/repository"
+	delivery_http_transferlimit "github.com/oniharnantyo/golang-backend-example/services/transferlimit/delivery/http"
+	repository_transferlimit "github.com/oniharnantyo/golang-backend-example/services/transferlimit/repository"
+	usecase_transferlimit "github.com/oniharnantyo/golang-backend-example/services/transferlimit/usecase"
 )
 
 func Run() {
@@ -44,9 +70,16 @@ func Run() {
 
 	redisClient := initRedis()
 
-	accountUseCase, customerUseCase := initService(dbPool, redisClient, logger)
+	authUseCase, accountUseCase, customerUseCase, mfaUseCase, fxRateUseCase, holdUseCase, scheduledTransferUseCase, transferLimitUseCase := initService(dbPool, redisClient, logger)
 
-	initHandler(accountUseCase, customerUseCase, logger)
+	idempotencyRepository := repository_idempotency.NewIdempotencyRepository(redisClient)
+
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+
+	go runScheduler(schedulerCtx, scheduledTransferUseCase, time.Duration(viper.GetInt("scheduler.interval_second"))*time.Second, logger)
+
+	initHandler(authUseCase, accountUseCase, customerUseCase, mfaUseCase, fxRateUseCase, holdUseCase, scheduledTransferUseCase, transferLimitUseCase, idempotencyRepository, logger)
 }
 
 func initConfig() {
@@ -107,31 +140,202 @@ func initRedis() *redis.Client {
 	return client
 }
 
-func initService(dbPool *sql.DB, redisClient *redis.Client, logger *logrus.Logger) (domain.AccountUseCase, domain.CustomerUseCase) {
+// initKeyStore loads the access token keys from security.key_dir. Without a
+// key directory tokens fall back to HS256 with security.access_secret.
+func initKeyStore() domain.KeyStore {
+	keyDir := viper.GetString("security.key_dir")
+	if keyDir == "" {
+		keyStore, err := keystore.NewKeyStore("default",
+			keystore.NewHMACKey("default", viper.GetString("security.access_secret")))
+		if err != nil {
+			log.Fatalf("Error on init key store : %v", err)
+		}
+
+		return keyStore
+	}
+
+	keyStore, err := keystore.LoadDir(keyDir, viper.GetString("security.active_kid"))
+	if err != nil {
+		log.Fatalf("Error on load signing keys : %v", err)
+	}
+
+	return keyStore
+}
+
+func initService(dbPool *sql.DB, redisClient *redis.Client, logger *logrus.Logger) (domain.AuthUseCase, domain.AccountUseCase, domain.CustomerUseCase, domain.MFAUseCase, domain.FXRateUseCase, domain.HoldUseCase, domain.ScheduledTransferUseCase, domain.TransferLimitUseCase) {
 	accountRepository := repository_account.NewAccountRepository(dbPool)
 	customerRepository := repository_customer.NewCustomerRepository(dbPool)
 	authRepository := repository_auth.NewAuthRepository(redisClient)
+	ledgerRepository := repository_ledger.NewLedgerRepository(dbPool)
+	transferRepository := repository_transfer.NewTransferRepository(dbPool)
+	transactionManager := database.NewTransactionManager(dbPool)
+	loginAttemptRepository := repository_loginattempt.NewLoginAttemptRepository(redisClient)
+	passwordResetRepository := repository_passwordreset.NewPasswordResetRepository(redisClient)
+	mfaRepository := repository_mfa.NewMFARepository(dbPool)
+	mfaChallengeRepository := repository_mfa.NewMFAChallengeRepository(redisClient)
+	fxRateRepository := repository_fxrate.NewFXRateRepository(dbPool)
+	holdRepository := repository_hold.NewHoldRepository(dbPool)
+	scheduledTransferRepository := repository_scheduledtransfer.NewScheduledTransferRepository(dbPool)
+	transferLimitRepository := repository_transferlimit.NewTransferLimitRepository(dbPool)
+
+	keyStore := initKeyStore()
 
 	authUseCase := usecase_auth.NewAuthUseCase(authRepository,
-		viper.GetString("security.access_secret"),
+		accountRepository,
+		keyStore,
 		viper.GetInt("security.access_secret_expire_after_minute"),
 		viper.GetString("security.refresh_secret"),
-		viper.GetInt("security.refresh_secret_expire_after_day"))
-	accountUseCase := usecase_account.NewAccountUseCase(authUseCase, accountRepository, customerRepository, logger)
+		viper.GetInt("security.refresh_secret_expire_after_day"),
+		viper.GetString("security.issuer"),
+		viper.GetString("security.audience"))
+	loginPolicy := domain.LoginPolicy{
+		MaxFailures:   viper.GetInt("login.max_failures"),
+		MaxIPFailures: viper.GetInt("login.max_ip_failures"),
+		FailureWindow: time.Duration(viper.GetInt("login.failure_window_minute")) * time.Minute,
+		LockDuration:  time.Duration(viper.GetInt("login.lock_minute")) * time.Minute,
+		BaseDelay:     time.Duration(viper.GetInt("login.base_delay_second")) * time.Second,
+		MaxDelay:      time.Duration(viper.GetInt("login.max_delay_second")) * time.Second,
+	}
+
+	passwordPolicy := domain.PasswordPolicy{
+		MinLength:        viper.GetInt("password.min_length"),
+		RequireUpper:     viper.GetBool("password.require_upper"),
+		RequireLower:     viper.GetBool("password.require_lower"),
+		RequireDigit:     viper.GetBool("password.require_digit"),
+		RequireSymbol:    viper.GetBool("password.require_symbol"),
+		BcryptCost:       viper.GetInt("password.bcrypt_cost"),
+		ResetTokenExpire: time.Duration(viper.GetInt("password.reset_token_expire_minute")) * time.Minute,
+	}
+
+	mfaPolicy := initMFAPolicy()
+
+	fxPolicy := domain.FXPolicy{
+		MaxRateAge: time.Duration(viper.GetInt("fx.max_rate_age_minute")) * time.Minute,
+	}
+
+	schedulerPolicy := domain.SchedulerPolicy{
+		Interval:       time.Duration(viper.GetInt("scheduler.interval_second")) * time.Second,
+		BatchSize:      viper.GetInt("scheduler.batch_size"),
+		MaxAttempts:    viper.GetInt("scheduler.max_attempts"),
+		RetryBaseDelay: time.Duration(viper.GetInt("scheduler.retry_base_minute")) * time.Minute,
+		RetryMaxDelay:  time.Duration(viper.GetInt("scheduler.retry_max_minute")) * time.Minute,
+	}
+
+	limitPolicy := initLimitPolicy()
+
+	fxRateUseCase := usecase_fxrate.NewFXRateUseCase(fxRateRepository, transactionManager, fxPolicy, logger)
+	mfaUseCase := usecase_mfa.NewMFAUseCase(mfaRepository, mfaChallengeRepository, transactionManager, loginAttemptRepository, fxRateUseCase, mfaPolicy, logger)
+	transferLimitUseCase := usecase_transferlimit.NewTransferLimitUseCase(transferLimitRepository, accountRepository, transferRepository, fxRateUseCase, limitPolicy, logger)
+	accountUseCase := usecase_account.NewAccountUseCase(authUseCase, accountRepository, customerRepository, ledgerRepository, transferRepository, transactionManager, loginAttemptRepository, loginPolicy, passwordPolicy, passwordResetRepository, notifier.NewLogNotifier(logger), mfaUseCase, fxRateUseCase, holdRepository, transferLimitUseCase, logger)
 	customerUseCase := usecase_customer.NewCustomerUseCase(customerRepository, logger)
+	holdUseCase := usecase_hold.NewHoldUseCase(holdRepository, accountRepository, logger)
+	scheduledTransferUseCase := usecase_scheduledtransfer.NewScheduledTransferUseCase(scheduledTransferRepository, accountRepository, accountUseCase, mfaUseCase, transactionManager, schedulerPolicy, logger)
 
-	return accountUseCase, customerUseCase
+	return authUseCase, accountUseCase, customerUseCase, mfaUseCase, fxRateUseCase, holdUseCase, scheduledTransferUseCase, transferLimitUseCase
 }
 
-func initHandler(accountUseCase domain.AccountUseCase, customerUseCase domain.CustomerUseCase, logger *logrus.Logger) {
+// runScheduler runs the due scheduled transfers every interval until ctx is
+// done. Replicas can all run it, a transfer is claimed by one of them only;
+// an interval of 0 keeps this replica out.
+// initMFAPolicy reads the [mfa] section. step_up_amount is in minor units of
+// step_up_currency, like the limit tiers.
+func initMFAPolicy() domain.MFAPolicy {
+	return domain.MFAPolicy{
+		Issuer:             viper.GetString("mfa.issuer"),
+		ChallengeExpire:    time.Duration(viper.GetInt("mfa.challenge_expire_minute")) * time.Minute,
+		StepUpAmount:       viper.GetInt64("mfa.step_up_amount"),
+		StepUpCurrency:     strings.ToUpper(viper.GetString("mfa.step_up_currency")),
+		StepUpMaxFailures:  viper.GetInt("mfa.step_up_max_failures"),
+		StepUpLockDuration: time.Duration(viper.GetInt("mfa.step_up_lock_minute")) * time.Minute,
+		RecoveryCodes:      viper.GetInt("mfa.recovery_codes"),
+	}
+}
+
+// initLimitPolicy reads the [limits] section, tier amounts are in minor units
+// of limits.currency.
+func initLimitPolicy() domain.LimitPolicy {
+	limitPolicy := domain.LimitPolicy{
+		DefaultTier: viper.GetString("limits.default_tier"),
+		Currency:    strings.ToUpper(viper.GetString("limits.currency")),
+		Tiers:       map[string]domain.TierLimits{},
+	}
+	for tier := range viper.GetStringMap("limits.tiers") {
+		limitPolicy.Tiers[tier] = domain.TierLimits{
+			PerTransfer: viper.GetInt64(fmt.Sprintf("limits.tiers.%s.per_transfer", tier)),
+			Daily:       viper.GetInt64(fmt.Sprintf("limits.tiers.%s.daily", tier)),
+			Monthly:     viper.GetInt64(fmt.Sprintf("limits.tiers.%s.monthly", tier)),
+			HourlyCount: viper.GetInt(fmt.Sprintf("limits.tiers.%s.hourly_count", tier)),
+			Currencies:  map[string]domain.TierAmounts{},
+		}
+
+		for currency := range viper.GetStringMap(fmt.Sprintf("limits.tiers.%s.currencies", tier)) {
+			key := fmt.Sprintf("limits.tiers.%s.currencies.%s", tier, currency)
+			limitPolicy.Tiers[tier].Currencies[strings.ToUpper(currency)] = domain.TierAmounts{
+				PerTransfer: viper.GetInt64(key + ".per_transfer"),
+				Daily:       viper.GetInt64(key + ".daily"),
+				Monthly:     viper.GetInt64(key + ".monthly"),
+			}
+		}
+	}
+
+	return limitPolicy
+}
+
+func runScheduler(ctx context.Context, scheduledTransferUseCase domain.ScheduledTransferUseCase, interval time.Duration, logger *logrus.Logger) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ran, err := scheduledTransferUseCase.RunDue(ctx)
+			if err != nil {
+				logger.Errorf("%s: %v", "Error on run scheduled transfers", err)
+			}
+			if ran > 0 {
+				logger.Infof("Ran %d scheduled transfers", ran)
+			}
+		}
+	}
+}
+
+func initHandler(authUseCase domain.AuthUseCase, accountUseCase domain.AccountUseCase, customerUseCase domain.CustomerUseCase, mfaUseCase domain.MFAUseCase, fxRateUseCase domain.FXRateUseCase, holdUseCase domain.HoldUseCase, scheduledTransferUseCase domain.ScheduledTransferUseCase, transferLimitUseCase domain.TransferLimitUseCase, idempotencyRepository domain.IdempotencyRepository, logger *logrus.Logger) {
 	ctx := context.Background()
 
 	r := gin.Default()
 
+	trustedProxies, err := middleware.ParseTrustedProxies(viper.GetStringSlice("app.trusted_proxies"))
+	if err != nil {
+		logger.Fatalf("%s: %v", "Error on parse trusted proxies", err)
+	}
+
+	r.Use(middleware.ClientIP(trustedProxies))
+	r.Use(middleware.Idempotency(idempotencyRepository, authUseCase,
+		time.Duration(viper.GetInt("idempotency.expire_after_hour"))*time.Hour,
+		time.Duration(viper.GetInt("idempotency.lock_second"))*time.Second,
+		logger))
+	// Registered after Idempotency, so the rendered errors are what an
+	// Idempotency-Key replays.
+	r.Use(middleware.ErrorHandler())
+
 	http.Handle("/", r)
 
-	delivery_http_account.NewAccountHandler(r, accountUseCase, logger)
-	delivery_http_customer.NewCustomerHandler(r, customerUseCase, logger)
+	cursorSigner := util.NewCursorSigner(viper.GetString("pagination.cursor_secret"))
+
+	delivery_http_account.NewAccountHandler(r, accountUseCase, authUseCase, cursorSigner, logger)
+	delivery_http_auth.NewAuthHandler(r, authUseCase, logger)
+	delivery_http_customer.NewCustomerHandler(r, customerUseCase, authUseCase, cursorSigner, logger)
+	delivery_http_mfa.NewMFAHandler(r, mfaUseCase, authUseCase, logger)
+	delivery_http_fxrate.NewFXRateHandler(r, fxRateUseCase, authUseCase, logger)
+	delivery_http_hold.NewHoldHandler(r, holdUseCase, authUseCase, logger)
+	delivery_http_scheduledtransfer.NewScheduledTransferHandler(r, scheduledTransferUseCase, authUseCase, logger)
+	delivery_http_transferlimit.NewTransferLimitHandler(r, transferLimitUseCase, authUseCase, logger)
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(`:%d`, viper.GetInt("app.port")),
@@ -151,7 +355,7 @@ func initHandler(accountUseCase domain.AccountUseCase, customerUseCase domain.Cu
 
 	// Wait for interrupt signal to gracefully shutdown the server with
 	// a timeout of 5 seconds.
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	// kill (no param) default send syscall.SIGTERM
 	// kill -2 is syscall.SIGINT
 	// kill -9 is syscall.SIGKILL but can't be catch, so don't need add it
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// The step-up threshold and the limit tiers are both minor units of the same
+// currency. Written in different units one of them is off by a factor of 100
+// and leaves the range the tiers span.
+func TestConfig_StepUpAmountInTierUnits(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.SetConfigFile("../.config.toml")
+	assert.NoError(t, viper.ReadInConfig())
+
+	mfaPolicy := initMFAPolicy()
+	limitPolicy := initLimitPolicy()
+
+	assert.Equal(t, limitPolicy.Currency, mfaPolicy.StepUpCurrency)
+	assert.NotEmpty(t, limitPolicy.Tiers)
+
+	var minPerTransfer, maxMonthly int64
+	for _, tier := range limitPolicy.Tiers {
+		if tier.PerTransfer > 0 && (minPerTransfer == 0 || tier.PerTransfer < minPerTransfer) {
+			minPerTransfer = tier.PerTransfer
+		}
+		if tier.Monthly > maxMonthly {
+			maxMonthly = tier.Monthly
+		}
+	}
+
+	assert.GreaterOrEqual(t, mfaPolicy.StepUpAmount, minPerTransfer, "step_up_amount is below every per_transfer limit")
+	assert.LessOrEqual(t, mfaPolicy.StepUpAmount, maxMonthly, "step_up_amount is above every monthly limit")
+}
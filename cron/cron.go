@@ -0,0 +1,198 @@
+// Package cron parses the five field recurrence rules of crontab(5), minute
+// hour day-of-month month day-of-week, and finds the times they match.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearch bounds Next, a rule like "0 0 30 2 *" never matches.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// ErrNoMatch is returned by Next for a rule without a time in the next five
+// years.
+var ErrNoMatch = errors.New("cron: rule never matches")
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var (
+	minuteField = field{"minute", 0, 59}
+	hourField   = field{"hour", 0, 23}
+	domField    = field{"day of month", 1, 31}
+	monthField  = field{"month", 1, 12}
+	// Sunday is both 0 and 7.
+	dowField = field{"day of week", 0, 7}
+)
+
+// Schedule is a parsed rule. Every field is a bit set of the values it
+// matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// lastDOM matches the last day of the month, "L" in the day of month.
+	lastDOM bool
+	// A restricted day of month and day of week match when either does, as
+	// in crontab(5).
+	domAny, dowAny bool
+}
+
+// Parse reads a rule like "0 9 1 * *" or one of @yearly, @monthly, @weekly,
+// @daily and @hourly. Fields take *, numbers, ranges (1-5), steps (*/15,
+// 1-31/2) and lists of these; the day of month also takes L, the last day.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	var s Schedule
+	var err error
+
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return Schedule{}, err
+	}
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return Schedule{}, err
+	}
+
+	dom := fields[2]
+	if dom == "L" {
+		s.lastDOM = true
+	} else if s.dom, err = parseField(dom, domField); err != nil {
+		return Schedule{}, err
+	}
+
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return Schedule{}, err
+	}
+	if s.dow, err = parseField(fields[4], dowField); err != nil {
+		return Schedule{}, err
+	}
+
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	s.domAny = dom == "*"
+	s.dowAny = fields[4] == "*"
+
+	return s, nil
+}
+
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %s %q", f.name, part)
+			}
+			rangeExpr, step = part[:i], n
+		}
+
+		from, to := f.min, f.max
+		if rangeExpr != "*" {
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+
+			var err error
+			from, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s %q", f.name, part)
+			}
+
+			to = from
+			if len(bounds) == 2 {
+				to, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, fmt.Errorf("invalid %s %q", f.name, part)
+				}
+			} else if step > 1 {
+				// 5/15 runs from 5 to the end of the field
+				to = f.max
+			}
+		}
+
+		if from < f.min || to > f.max || from > to {
+			return 0, fmt.Errorf("%s %q is out of range %d-%d", f.name, part, f.min, f.max)
+		}
+
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// Next returns the first minute after t the schedule matches, in the location
+// of t.
+func (s Schedule) Next(t time.Time) (time.Time, error) {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.Add(maxSearch)
+
+	for t.Before(end) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t, nil
+	}
+
+	return time.Time{}, ErrNoMatch
+}
+
+func (s Schedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	if s.lastDOM {
+		dom = t.AddDate(0, 0, 1).Day() == 1
+	}
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,60 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "0 9 1 * *", "*/15 8-17 * * 1-5", "0 0 L * *", "30 6 1,15 * *", "@monthly", "0 0 * * 7"} {
+		_, err := Parse(spec)
+		assert.NoError(t, err, spec)
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every"} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2021, 4, 21, 10, 30, 15, 0, time.UTC)
+
+	cases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2021, 4, 21, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2021, 4, 21, 10, 45, 0, 0, time.UTC)},
+		{"0 9 1 * *", time.Date(2021, 5, 1, 9, 0, 0, 0, time.UTC)},
+		{"0 0 L * *", time.Date(2021, 4, 30, 0, 0, 0, 0, time.UTC)},
+		{"0 0 L 2 *", time.Date(2022, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 8 * * 1-5", time.Date(2021, 4, 22, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 0", time.Date(2021, 4, 25, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 7", time.Date(2021, 4, 25, 8, 0, 0, 0, time.UTC)},
+		// Either the 1st or a Friday
+		{"0 0 1 * 5", time.Date(2021, 4, 23, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		s, err := Parse(c.spec)
+		assert.NoError(t, err, c.spec)
+
+		next, err := s.Next(from)
+		assert.NoError(t, err, c.spec)
+		assert.Equal(t, c.next, next, c.spec)
+	}
+
+	t.Run("Never", func(t *testing.T) {
+		s, err := Parse("0 0 30 2 *")
+		assert.NoError(t, err)
+
+		_, err = s.Next(from)
+		assert.Equal(t, ErrNoMatch, err)
+	})
+}
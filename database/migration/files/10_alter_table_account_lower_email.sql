@@ -0,0 +1,5 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+UPDATE account SET email = LOWER(TRIM(email));
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
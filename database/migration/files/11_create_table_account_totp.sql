@@ -0,0 +1,21 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS account_totp (
+    account_number      INT NOT NULL,
+    secret              VARCHAR(64) NOT NULL,
+    enabled             BOOLEAN NOT NULL DEFAULT FALSE,
+    last_step           BIGINT NOT NULL DEFAULT 0,
+    created_at          TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(account_number)
+);
+
+CREATE TABLE IF NOT EXISTS account_recovery_code (
+    account_number      INT NOT NULL,
+    code_hash           CHAR(64) NOT NULL,
+    used_at             TIMESTAMP,
+    PRIMARY KEY(account_number, code_hash)
+);
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE account_recovery_code;
+DROP TABLE account_totp;
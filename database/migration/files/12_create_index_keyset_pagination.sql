@@ -0,0 +1,10 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE UNIQUE INDEX IF NOT EXISTS account_account_number_idx ON account(account_number);
+CREATE INDEX IF NOT EXISTS customer_name_customer_number_idx ON customer(name, customer_number);
+CREATE INDEX IF NOT EXISTS transfer_created_at_id_idx ON transfer(created_at, id);
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX transfer_created_at_id_idx;
+DROP INDEX customer_name_customer_number_idx;
+DROP INDEX account_account_number_idx;
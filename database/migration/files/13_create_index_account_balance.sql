@@ -0,0 +1,6 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE INDEX IF NOT EXISTS account_balance_account_number_idx ON account(balance, account_number);
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX account_balance_account_number_idx;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE customer ADD COLUMN IF NOT EXISTS name_tsv TSVECTOR
+    GENERATED ALWAYS AS (to_tsvector('simple', COALESCE(name, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS customer_name_tsv_idx ON customer USING GIN(name_tsv);
+CREATE INDEX IF NOT EXISTS customer_name_trgm_idx ON customer USING GIN(name gin_trgm_ops);
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX customer_name_trgm_idx;
+DROP INDEX customer_name_tsv_idx;
+ALTER TABLE customer DROP COLUMN name_tsv;
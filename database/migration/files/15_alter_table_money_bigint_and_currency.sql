@@ -0,0 +1,27 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Amounts are minor units of the currency, rows from before the currency
+-- column are in IDR. They were whole rupiah, so they are multiplied by 100.
+ALTER TABLE account ALTER COLUMN balance TYPE BIGINT USING balance::BIGINT * 100;
+ALTER TABLE account ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE account ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE transfer ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100;
+ALTER TABLE transfer ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE transfer ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE ledger_entry ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100;
+ALTER TABLE ledger_entry ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE ledger_entry ALTER COLUMN currency DROP DEFAULT;
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+-- Back to whole units. Fractions of a unit are cut off, and an entry or
+-- transfer below one unit fails the amount > 0 check instead of vanishing.
+ALTER TABLE ledger_entry DROP COLUMN currency;
+ALTER TABLE ledger_entry ALTER COLUMN amount TYPE INT USING amount / 100;
+
+ALTER TABLE transfer DROP COLUMN currency;
+ALTER TABLE transfer ALTER COLUMN amount TYPE INT USING amount / 100;
+
+ALTER TABLE account DROP COLUMN currency;
+ALTER TABLE account ALTER COLUMN balance TYPE INT USING balance / 100;
@@ -0,0 +1,25 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS fx_rate (
+    base_currency       CHAR(3) NOT NULL,
+    quote_currency      CHAR(3) NOT NULL,
+    rate                NUMERIC NOT NULL CHECK (rate > 0),
+    updated_at          TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(base_currency, quote_currency)
+);
+
+-- What the receiver was credited, in the currency of their account, and the
+-- rate the amount was converted at. Transfers within a currency have no rate.
+ALTER TABLE transfer ADD COLUMN IF NOT EXISTS converted_amount BIGINT;
+ALTER TABLE transfer ADD COLUMN IF NOT EXISTS converted_currency CHAR(3);
+ALTER TABLE transfer ADD COLUMN IF NOT EXISTS fx_rate NUMERIC;
+UPDATE transfer SET converted_amount = amount, converted_currency = currency;
+ALTER TABLE transfer ALTER COLUMN converted_amount SET NOT NULL;
+ALTER TABLE transfer ALTER COLUMN converted_currency SET NOT NULL;
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE transfer DROP COLUMN fx_rate;
+ALTER TABLE transfer DROP COLUMN converted_currency;
+ALTER TABLE transfer DROP COLUMN converted_amount;
+
+DROP TABLE fx_rate;
@@ -0,0 +1,18 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Deposits and withdrawals are kept with the transfers, against the external
+-- account (0). A channel never takes the same reference twice.
+ALTER TABLE transfer ADD COLUMN IF NOT EXISTS type VARCHAR(20) NOT NULL DEFAULT 'transfer';
+ALTER TABLE transfer ADD COLUMN IF NOT EXISTS reference VARCHAR(64);
+ALTER TABLE transfer ADD COLUMN IF NOT EXISTS channel VARCHAR(20);
+ALTER TABLE transfer ADD COLUMN IF NOT EXISTS reason_code VARCHAR(32);
+
+CREATE UNIQUE INDEX IF NOT EXISTS transfer_channel_reference_unique ON transfer(channel, reference) WHERE reference IS NOT NULL;
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX transfer_channel_reference_unique;
+
+ALTER TABLE transfer DROP COLUMN reason_code;
+ALTER TABLE transfer DROP COLUMN channel;
+ALTER TABLE transfer DROP COLUMN reference;
+ALTER TABLE transfer DROP COLUMN type;
@@ -0,0 +1,18 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS account_hold (
+    id                  BIGSERIAL NOT NULL,
+    account_number      INT NOT NULL,
+    amount              BIGINT NOT NULL CHECK (amount > 0),
+    currency            CHAR(3) NOT NULL,
+    reason              VARCHAR(255) NOT NULL,
+    expires_at          TIMESTAMP,
+    created_at          TIMESTAMP NOT NULL DEFAULT NOW(),
+    released_at         TIMESTAMP,
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX IF NOT EXISTS account_hold_account_number_idx ON account_hold(account_number) WHERE released_at IS NULL;
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE account_hold;
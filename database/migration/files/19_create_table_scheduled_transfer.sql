@@ -0,0 +1,26 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS scheduled_transfer (
+    id                      BIGSERIAL NOT NULL,
+    from_account_number     INT NOT NULL,
+    to_account_number       INT NOT NULL,
+    amount                  BIGINT NOT NULL CHECK (amount > 0),
+    currency                CHAR(3) NOT NULL,
+    description             VARCHAR(255),
+    recurrence              VARCHAR(100),
+    next_run_at             TIMESTAMP NOT NULL,
+    status                  VARCHAR(20) NOT NULL,
+    attempts                INT NOT NULL DEFAULT 0,
+    last_error              VARCHAR(64),
+    last_transfer_id        UUID,
+    created_at              TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_transfer_from_account_number_idx ON scheduled_transfer(from_account_number);
+-- The scheduler only looks for active transfers that are due.
+CREATE INDEX IF NOT EXISTS scheduled_transfer_due_idx ON scheduled_transfer(next_run_at) WHERE status = 'active';
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE scheduled_transfer;
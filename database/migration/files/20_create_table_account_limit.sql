@@ -0,0 +1,16 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS account_limit (
+    account_number      INT NOT NULL,
+    tier                VARCHAR(20) NOT NULL,
+    -- A NULL limit is the one of the tier.
+    per_transfer        BIGINT CHECK (per_transfer >= 0),
+    daily               BIGINT CHECK (daily >= 0),
+    monthly             BIGINT CHECK (monthly >= 0),
+    hourly_count        INT CHECK (hourly_count >= 0),
+    updated_at          TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(account_number)
+);
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE account_limit;
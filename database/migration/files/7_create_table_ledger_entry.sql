@@ -0,0 +1,25 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS ledger_entry (
+    id                  BIGSERIAL NOT NULL,
+    transaction_id      UUID NOT NULL,
+    transaction_type    VARCHAR(20) NOT NULL,
+    account_number      INT NOT NULL,
+    entry_type          VARCHAR(6) NOT NULL CHECK (entry_type IN ('debit', 'credit')),
+    amount              INT NOT NULL CHECK (amount > 0),
+    created_at          TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entry_account_number_idx ON ledger_entry(account_number);
+CREATE INDEX IF NOT EXISTS ledger_entry_transaction_id_idx ON ledger_entry(transaction_id);
+
+-- Opening balances of the accounts that existed before the ledger, booked
+-- against the external account (0) so every transaction stays balanced.
+INSERT INTO ledger_entry (transaction_id, transaction_type, account_number, entry_type, amount)
+SELECT md5('opening-' || account_number)::uuid, 'opening', 0, 'debit', balance FROM account WHERE balance > 0;
+INSERT INTO ledger_entry (transaction_id, transaction_type, account_number, entry_type, amount)
+SELECT md5('opening-' || account_number)::uuid, 'opening', account_number, 'credit', balance FROM account WHERE balance > 0;
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE ledger_entry;
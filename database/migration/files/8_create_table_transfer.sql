@@ -0,0 +1,19 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS transfer (
+    id                      UUID NOT NULL,
+    from_account_number     INT NOT NULL,
+    to_account_number       INT NOT NULL,
+    amount                  INT NOT NULL CHECK (amount > 0),
+    status                  VARCHAR(20) NOT NULL,
+    description             VARCHAR(255),
+    created_at              TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(id)
+);
+
+CREATE INDEX IF NOT EXISTS transfer_from_account_number_created_at_idx ON transfer(from_account_number, created_at);
+CREATE INDEX IF NOT EXISTS transfer_to_account_number_created_at_idx ON transfer(to_account_number, created_at);
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE transfer;
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE account ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'customer'
+    CHECK (role IN ('customer', 'teller', 'admin'));
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE account DROP COLUMN role;
@@ -0,0 +1,20 @@
+package database_mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type TransactionMockManager struct {
+	mock.Mock
+}
+
+func (t *TransactionMockManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := t.Called(ctx, fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+
+	return fn(ctx)
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/pkg/errors"
+)
+
+type txKey struct{}
+
+// Executor is the subset of *sql.DB and *sql.Tx used by the repositories,
+// so the same query code runs inside and outside a transaction.
+type Executor interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type transactionManager struct {
+	dbPool *sql.DB
+}
+
+func (t transactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Join the transaction already carried by the context, so nested units of
+	// work commit or roll back together with the outer one.
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.dbPool.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "transactionManager/WithinTransaction/BeginTx")
+	}
+
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Wrapf(err, "transactionManager/WithinTransaction/Rollback: %v", rbErr)
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "transactionManager/WithinTransaction/Commit")
+	}
+
+	return nil
+}
+
+// Conn returns the transaction carried by ctx, or db when there is none.
+func Conn(ctx context.Context, db *sql.DB) Executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return db
+}
+
+func NewTransactionManager(db *sql.DB) domain.TransactionManager {
+	return &transactionManager{
+		dbPool: db,
+	}
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionManager_WithinTransaction(t *testing.T) {
+	t.Run("Commit", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		err = NewTransactionManager(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			assert.NotEqual(t, db, Conn(ctx, db))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		fnErr := errors.New("Unexpected")
+		err = NewTransactionManager(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			return fnErr
+		})
+		assert.Equal(t, fnErr, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		tm := NewTransactionManager(db)
+		err = tm.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			return tm.WithinTransaction(ctx, func(ctx context.Context) error {
+				return nil
+			})
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
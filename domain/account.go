@@ -6,58 +6,155 @@ import (
 	"github.com/oniharnantyo/golang-backend-example/util"
 )
 
+var (
+	// ErrEmailTaken is returned when another account already uses the email.
+	ErrEmailTaken = NewError(KindConflict, "email_taken", "Email already registered")
+	// ErrInvalidEmail is returned for an email that cannot be parsed.
+	ErrInvalidEmail = NewError(KindValidation, "invalid_email", "Invalid email")
+	// The errors below are returned by account lookups, transfers and
+	// registration, their codes are part of the API.
+	ErrAccountNotFound         = NewError(KindNotFound, "account_not_found", "Account not exists")
+	ErrSenderAccountNotFound   = NewError(KindValidation, "sender_account_not_found", "Sender account not found")
+	ErrReceiverAccountNotFound = NewError(KindValidation, "receiver_account_not_found", "Receiver account not found")
+	ErrSameAccountTransfer     = NewError(KindValidation, "same_account_transfer", "Sender and receiver is same account")
+	ErrInsufficientBalance     = NewError(KindInsufficientFunds, "insufficient_balance", "Insufficient balance")
+	ErrNegativeBalance         = NewError(KindValidation, "negative_balance", "Balance cannot be negative")
+	ErrInvalidRole             = NewError(KindValidation, "invalid_role", "Invalid role")
+	ErrInvalidBalanceRange     = NewError(KindValidation, "invalid_balance_range", "balance_lte must not be below balance_gte")
+)
+
+// AccountSort declares the fields accounts can be sorted by.
+var AccountSort = util.SortSpec{
+	Fields: []string{"account_number", "customer_number", "balance", "email"},
+	Unique: "account_number",
+	Order:  "ASC",
+}
+
 type (
 	Account struct {
 		AccountNumber  int    `json:"account_number"`
 		CustomerNumber int    `json:"customer_number"`
-		Balance        int    `json:"balance"`
+		Balance        Money  `json:"balance"`
 		Email          string `json:"email"`
 		Password       string `json:"-"`
+		Role           string `json:"role"`
 	}
 
+	// AccountRegisterParam opens an account. The password is only accepted
+	// here, it is hashed before the account is stored. The currency of the
+	// opening balance is the currency of the account, DefaultCurrency when it
+	// is left out.
+	AccountRegisterParam struct {
+		AccountNumber  int    `json:"account_number" binding:"required"`
+		CustomerNumber int    `json:"customer_number" binding:"required"`
+		Balance        Money  `json:"balance"`
+		Email          string `json:"email" binding:"required"`
+		Password       string `json:"password" binding:"required"`
+		Role           string `json:"role"`
+	}
+
+	// AccountListParam lists accounts, by account number unless Sort says
+	// otherwise. The balance bounds are inclusive. After is the decoded
+	// cursor, the page starts behind that account.
 	AccountListParam struct {
 		util.Filter
+		CustomerNumber int            `json:"customer_number" form:"customer_number" binding:"omitempty,min=1"`
+		BalanceGte     *int64         `json:"balance_gte" form:"balance_gte" binding:"omitempty,min=0"`
+		BalanceLte     *int64         `json:"balance_lte" form:"balance_lte" binding:"omitempty,min=0"`
+		EmailDomain    string         `json:"email_domain" form:"email_domain" binding:"omitempty,fqdn"`
+		After          *AccountCursor `json:"-" form:"-"`
+	}
+
+	// AccountCursor is the sort key an account listing continues after. It
+	// holds every field accounts can be sorted by and the sort it was issued
+	// for.
+	AccountCursor struct {
+		Sort           string `json:"sort"`
+		AccountNumber  int    `json:"account_number"`
+		CustomerNumber int    `json:"customer_number"`
+		Balance        int64  `json:"balance"`
+		Email          string `json:"email"`
 	}
 
 	AccountLoginParam struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email     string `json:"email"`
+		Password  string `json:"password"`
+		UserAgent string `json:"-"`
+		IP        string `json:"-"`
 	}
 
 	TransferParam struct {
 		ToAccountNumber string `json:"to_account_number"`
-		Amount          int    `json:"amount"`
+		Amount          Money  `json:"amount"`
+		Description     string `json:"description"`
+		// OTP is the second factor code transfers above the step-up amount
+		// need.
+		OTP string `json:"otp,omitempty"`
 	}
 
 	DetailByAccountNumberResponse struct {
 		AccountNumber int    `json:"account_number"`
 		CustomerName  string `json:"customer_name"`
-		Balance       int    `json:"balance"`
+		Balance       Money  `json:"balance"`
 	}
 
+	// LoginResponse carries the tokens, or only MFAToken when the account
+	// needs a second factor to finish the login.
 	LoginResponse struct {
-		Token string `json:"token"`
+		Token        string `json:"token,omitempty"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+		MFARequired  bool   `json:"mfa_required,omitempty"`
+		MFAToken     string `json:"mfa_token,omitempty"`
+	}
+
+	RebuildBalanceResponse struct {
+		AccountNumber   int   `json:"account_number"`
+		PreviousBalance Money `json:"previous_balance"`
+		Balance         Money `json:"balance"`
 	}
 )
 
 type (
 	AccountUseCase interface {
-		List(ctx context.Context, param AccountListParam) ([]Account, error)
+		// List returns a page of accounts and the number of all accounts
+		// matching the filter.
+		List(ctx context.Context, param AccountListParam) ([]Account, int, error)
 		GetByAccountNumber(ctx context.Context, accountNumber int) (DetailByAccountNumberResponse, error)
-		Store(ctx context.Context, a *Account) error
+		Register(ctx context.Context, param AccountRegisterParam) (Account, error)
 		Update(ctx context.Context, a *Account) error
 		Delete(ctx context.Context, a *Account) error
-		Transfer(ctx context.Context, fromAccountNumber int, param TransferParam) error
+		Transfer(ctx context.Context, fromAccountNumber int, param TransferParam) (Transfer, error)
+		// Deposit credits the account with money from outside the bank.
+		Deposit(ctx context.Context, accountNumber int, param MovementParam) (Transfer, error)
+		// Withdraw debits money leaving the bank from the account, it never
+		// touches the part of the balance under an active hold.
+		Withdraw(ctx context.Context, accountNumber int, param MovementParam) (Transfer, error)
+		// ListTransfers returns a page of the transfers of the account and the
+		// number of all its transfers matching the filter.
+		ListTransfers(ctx context.Context, accountNumber int, param TransferListParam) ([]Transfer, int, error)
+		ListLedgerEntries(ctx context.Context, accountNumber int, param LedgerEntryListParam) ([]LedgerEntry, int, error)
+		RebuildBalance(ctx context.Context, accountNumber int) (RebuildBalanceResponse, error)
 
 		Login(ctx context.Context, param AccountLoginParam) (LoginResponse, error)
+		LoginMFA(ctx context.Context, param LoginMFAParam) (LoginResponse, error)
+		ChangePassword(ctx context.Context, accountNumber int, param PasswordChangeParam) error
+		RequestPasswordReset(ctx context.Context, param PasswordResetRequestParam) error
+		ResetPassword(ctx context.Context, param PasswordResetParam) error
+		Unlock(ctx context.Context, accountNumber int) error
 	}
 
 	AccountRepository interface {
 		List(ctx context.Context, param AccountListParam) ([]Account, error)
+		Count(ctx context.Context, param AccountListParam) (int, error)
 		GetByAccountNumber(ctx context.Context, accountNumber int) (Account, error)
+		GetByAccountNumberForUpdate(ctx context.Context, accountNumber int) (Account, error)
 		GetByEmail(ctx context.Context, email string) (Account, error)
 		Store(ctx context.Context, a *Account) error
 		Update(ctx context.Context, a *Account) error
+		// UpdateBalance stores the amount of balance, the currency of an
+		// account never changes.
+		UpdateBalance(ctx context.Context, accountNumber int, balance Money) error
+		UpdatePassword(ctx context.Context, accountNumber int, password string) error
 		Delete(ctx context.Context, a *Account) error
 	}
 )
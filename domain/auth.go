@@ -2,11 +2,24 @@ package domain
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 )
 
+const (
+	RoleCustomer = "customer"
+	RoleTeller   = "teller"
+	RoleAdmin    = "admin"
+)
+
+var (
+	ErrMissingToken       = NewError(KindUnauthorized, "missing_token", "Missing bearer token")
+	ErrInvalidToken       = NewError(KindUnauthorized, "invalid_token", "Invalid token")
+	ErrRefreshTokenReused = NewError(KindUnauthorized, "refresh_token_reused", "Refresh token reused")
+)
+
 type (
 	Auth struct {
 		AccessToken          string `json:"access_token"`
@@ -15,28 +28,80 @@ type (
 		RefreshUuid          string `json:"refresh_uuid"`
 		AccessTokenExpireAt  int64  `json:"access_token_expire_at"`
 		RefreshTokenExpireAt int64  `json:"refresh_token_expire_at"`
+		SessionID            string `json:"session_id"`
 	}
 
+	// AccessClaims keeps the token body minimal: sub is the account number,
+	// jti the UUID the token is stored under, sid the session it belongs to
+	// and role what the account may do.
 	AccessClaims struct {
 		jwt.StandardClaims
-		AccessUUID string   `json:"access_uuid"`
-		Account    *Account `json:"account"`
+		SessionID string `json:"sid"`
+		Role      string `json:"role"`
 	}
 
 	RefreshClaims struct {
 		jwt.StandardClaims
-		RefreshUUID string   `json:"refresh_uuid"`
-		Account     *Account `json:"account"`
+		SessionID string `json:"sid"`
+	}
+
+	// Session is the family of token pairs started by one login. Every refresh
+	// rotates the pair but keeps the session.
+	Session struct {
+		ID            string    `json:"id"`
+		AccountNumber int       `json:"account_number"`
+		AccessUuid    string    `json:"access_uuid"`
+		RefreshUuid   string    `json:"refresh_uuid"`
+		UserAgent     string    `json:"user_agent"`
+		Role          string    `json:"role"`
+		CreatedAt     time.Time `json:"created_at"`
+		RefreshedAt   time.Time `json:"refreshed_at"`
+	}
+
+	SessionResponse struct {
+		ID          string    `json:"id"`
+		UserAgent   string    `json:"user_agent"`
+		CreatedAt   time.Time `json:"created_at"`
+		RefreshedAt time.Time `json:"refreshed_at"`
+		Current     bool      `json:"current"`
+	}
+
+	RefreshParam struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
 	}
 )
 
 type (
 	AuthUseCase interface {
-		CreateAuth(ctx context.Context, account Account) (Auth, error)
+		CreateAuth(ctx context.Context, account Account, userAgent string) (Auth, error)
+		ValidateAccessToken(ctx context.Context, token string) (AccessClaims, error)
+		Refresh(ctx context.Context, refreshToken string) (Auth, error)
+		Logout(ctx context.Context, claims AccessClaims) error
+		LogoutAll(ctx context.Context, accountNumber int) error
+		ListSessions(ctx context.Context, accountNumber int) ([]Session, error)
+		JWKS() JWKS
 	}
 
 	AuthRepository interface {
 		Get(ctx context.Context, id string) (string, error)
 		Set(ctx context.Context, key, value string, expire time.Duration) error
+		Delete(ctx context.Context, keys ...string) (int64, error)
+		AddSession(ctx context.Context, accountNumber int, sessionID string, expire time.Duration) error
+		ListSessions(ctx context.Context, accountNumber int) ([]string, error)
+		RemoveSessions(ctx context.Context, accountNumber int, sessionIDs ...string) error
 	}
 )
+
+// AccountNumber returns the account number carried in the sub claim.
+func (c AccessClaims) AccountNumber() int {
+	accountNumber, _ := strconv.Atoi(c.Subject)
+
+	return accountNumber
+}
+
+// AccountNumber returns the account number carried in the sub claim.
+func (c RefreshClaims) AccountNumber() int {
+	accountNumber, _ := strconv.Atoi(c.Subject)
+
+	return accountNumber
+}
@@ -6,18 +6,63 @@ import (
 	"github.com/oniharnantyo/golang-backend-example/util"
 )
 
+var (
+	// ErrCustomerNotFound is returned when no customer has the number.
+	ErrCustomerNotFound = NewError(KindNotFound, "customer_not_found", "Customer not found")
+	// ErrInvalidSearch is returned for a search without a word to look for.
+	ErrInvalidSearch = NewError(KindValidation, "invalid_search", "Search needs a letter or digit")
+)
+
 type Customer struct {
 	CustomerNumber int    `json:"account_number"`
 	Name           string `json:"name"`
 }
 
+// CustomerSort declares the fields customers can be sorted by.
+var CustomerSort = util.SortSpec{
+	Fields: []string{"name", "customer_number"},
+	Unique: "customer_number",
+	Order:  "ASC",
+}
+
+// CustomerListParam lists customers, by name unless Sort says otherwise. After is the decoded cursor,
+// the page starts behind that customer.
 type CustomerListParam struct {
 	util.Filter
+	After *CustomerCursor `json:"-" form:"-"`
+}
+
+// CustomerCursor is the sort key a customer listing continues after, for the
+// sort it was issued for. Names are not unique, the customer number breaks
+// ties.
+type CustomerCursor struct {
+	Sort           string `json:"sort"`
+	Name           string `json:"name"`
+	CustomerNumber int    `json:"customer_number"`
+}
+
+// CustomerSearchParam searches customers by name. Every word of Q matches
+// the beginning of a word of the name, names close to Q match as well so
+// typos still find the customer.
+type CustomerSearchParam struct {
+	Q     string `json:"q" form:"q" binding:"required"`
+	Limit int    `json:"limit" form:"limit"`
+}
+
+// CustomerSearchResult is a customer a search found, results with a higher
+// Rank match better.
+type CustomerSearchResult struct {
+	Customer
+	Rank float64 `json:"rank"`
 }
 
 type (
 	CustomerUseCase interface {
-		List(ctx context.Context, param CustomerListParam) ([]Customer, error)
+		// List returns a page of customers and the number of all customers
+		// matching the filter.
+		List(ctx context.Context, param CustomerListParam) ([]Customer, int, error)
+		// Search returns the customers matching param, best match first.
+		Search(ctx context.Context, param CustomerSearchParam) ([]CustomerSearchResult, error)
 		GetByCustomerNumber(ctx context.Context, accountNumber int) (Customer, error)
 		Store(ctx context.Context, a *Customer) error
 		Update(ctx context.Context, a *Customer) error
@@ -26,6 +71,8 @@ type (
 
 	CustomerRepository interface {
 		List(ctx context.Context, param CustomerListParam) ([]Customer, error)
+		Count(ctx context.Context, param CustomerListParam) (int, error)
+		Search(ctx context.Context, param CustomerSearchParam) ([]CustomerSearchResult, error)
 		GetByCustomerNumber(ctx context.Context, customerNumber int) (Customer, error)
 		Store(ctx context.Context, a *Customer) error
 		Update(ctx context.Context, a *Customer) error
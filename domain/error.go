@@ -0,0 +1,48 @@
+package domain
+
+// ErrorKind groups errors by how the caller should react to them, the HTTP
+// layer answers every kind with one status.
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindInsufficientFunds
+	KindUnprocessable
+	KindTooManyRequests
+)
+
+// Error is an error the use cases expect and clients can act on. Code is
+// stable and meant for programs, Message for people. Details lists single
+// problems, e.g. every broken password rule.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Details []string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func NewError(kind ErrorKind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+var (
+	ErrInternal   = NewError(KindInternal, "internal_error", "Something went wrong")
+	ErrBadRequest = NewError(KindValidation, "bad_request", "Bad request")
+	ErrNotFound   = NewError(KindNotFound, "not_found", "Not found")
+	ErrForbidden  = NewError(KindForbidden, "forbidden", "Forbidden")
+	// ErrInvalidCursor is returned for a page cursor that was tampered with or
+	// belongs to another listing.
+	ErrInvalidCursor = NewError(KindValidation, "invalid_cursor", "Invalid cursor")
+	// ErrInvalidSort is returned for a sort on a field the listing does not
+	// allow.
+	ErrInvalidSort = NewError(KindValidation, "invalid_sort", "Invalid sort")
+)
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"regexp"
+	"time"
+)
+
+var (
+	// ErrFXRateNotFound is returned for a conversion between two currencies
+	// without an exchange rate.
+	ErrFXRateNotFound = NewError(KindUnprocessable, "fx_rate_not_found", "No exchange rate for the currencies")
+	// ErrFXRateStale is returned for a conversion at a rate older than
+	// FXPolicy.MaxRateAge.
+	ErrFXRateStale = NewError(KindUnprocessable, "fx_rate_stale", "Exchange rate is out of date")
+	// ErrInvalidFXRate is returned for a rate that is not positive, has too
+	// many digits or converts a currency into itself.
+	ErrInvalidFXRate = NewError(KindValidation, "invalid_fx_rate", "Invalid exchange rate")
+)
+
+// rateFormat limits rates to what the rate column holds without rounding.
+var rateFormat = regexp.MustCompile(`^[0-9]{1,12}(\.[0-9]{1,12})?$`)
+
+type (
+	// FXRate is how many units of QuoteCurrency one unit of BaseCurrency is
+	// worth. Rate is a decimal string, so it is never rounded by a float.
+	FXRate struct {
+		BaseCurrency  string    `json:"base_currency" binding:"required"`
+		QuoteCurrency string    `json:"quote_currency" binding:"required"`
+		Rate          string    `json:"rate" binding:"required"`
+		UpdatedAt     time.Time `json:"updated_at"`
+	}
+
+	FXRateImportResponse struct {
+		Imported int `json:"imported"`
+	}
+
+	// FXPolicy configures currency conversions. Rates older than MaxRateAge
+	// are not used, zero accepts rates of any age.
+	FXPolicy struct {
+		MaxRateAge time.Duration
+	}
+)
+
+// Validate returns ErrInvalidCurrency for a currency accounts cannot hold and
+// ErrInvalidFXRate for any other problem of r.
+func (r FXRate) Validate() error {
+	err := NewMoney(0, r.BaseCurrency).Validate()
+	if err != nil {
+		return err
+	}
+
+	err = NewMoney(0, r.QuoteCurrency).Validate()
+	if err != nil {
+		return err
+	}
+
+	if r.BaseCurrency == r.QuoteCurrency {
+		return ErrInvalidFXRate
+	}
+
+	_, err = r.Ratio()
+	return err
+}
+
+// Ratio returns the rate as an exact fraction.
+func (r FXRate) Ratio() (*big.Rat, error) {
+	if !rateFormat.MatchString(r.Rate) {
+		return nil, ErrInvalidFXRate
+	}
+
+	ratio, ok := new(big.Rat).SetString(r.Rate)
+	if !ok || ratio.Sign() <= 0 {
+		return nil, ErrInvalidFXRate
+	}
+
+	return ratio, nil
+}
+
+type (
+	FXRateUseCase interface {
+		List(ctx context.Context) ([]FXRate, error)
+		// Store adds the rate of a currency pair or replaces it.
+		Store(ctx context.Context, r *FXRate) error
+		// Import stores the rates of a CSV with a base_currency,
+		// quote_currency,rate line per rate and returns how many it stored.
+		// A rate is only stored when every line is valid.
+		Import(ctx context.Context, r io.Reader) (int, error)
+		Delete(ctx context.Context, baseCurrency, quoteCurrency string) error
+		// Convert converts amount into currency at the stored rate, which it
+		// returns as well. It fails with ErrFXRateNotFound and ErrFXRateStale
+		// when there is no rate that may be used.
+		Convert(ctx context.Context, amount Money, currency string) (Money, FXRate, error)
+	}
+
+	FXRateRepository interface {
+		List(ctx context.Context) ([]FXRate, error)
+		Get(ctx context.Context, baseCurrency, quoteCurrency string) (FXRate, error)
+		Store(ctx context.Context, r *FXRate) error
+		Delete(ctx context.Context, baseCurrency, quoteCurrency string) error
+	}
+)
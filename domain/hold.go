@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	// ErrHoldNotFound is returned for a hold that does not exist on the
+	// account, was already released or has expired.
+	ErrHoldNotFound = NewError(KindNotFound, "hold_not_found", "Hold not exists")
+	// ErrInvalidHoldExpiry is returned for a hold that would expire before it
+	// is placed.
+	ErrInvalidHoldExpiry = NewError(KindValidation, "invalid_hold_expiry", "expires_at must be in the future")
+)
+
+type (
+	// Hold sets aside part of the balance of an account, such as a card
+	// authorisation or a legal freeze. It stops holding the amount once it is
+	// released or ExpiresAt passes.
+	Hold struct {
+		ID            int64      `json:"id"`
+		AccountNumber int        `json:"account_number"`
+		Amount        Money      `json:"amount"`
+		Reason        string     `json:"reason"`
+		ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+		CreatedAt     time.Time  `json:"created_at"`
+	}
+
+	HoldParam struct {
+		Amount    Money      `json:"amount"`
+		Reason    string     `json:"reason" binding:"required,max=255"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+)
+
+type (
+	HoldUseCase interface {
+		// Place holds the amount on the account, it may exceed the balance.
+		Place(ctx context.Context, accountNumber int, param HoldParam) (Hold, error)
+		ListActive(ctx context.Context, accountNumber int) ([]Hold, error)
+		Release(ctx context.Context, accountNumber int, id int64) error
+	}
+
+	HoldRepository interface {
+		Store(ctx context.Context, h *Hold) error
+		ListActive(ctx context.Context, accountNumber int) ([]Hold, error)
+		// Release returns sql.ErrNoRows when the account has no such active
+		// hold.
+		Release(ctx context.Context, accountNumber int, id int64) error
+		// TotalActive sums the active holds of the account in minor units of
+		// its currency.
+		TotalActive(ctx context.Context, accountNumber int) (int64, error)
+	}
+)
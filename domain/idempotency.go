@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	// ErrIdempotencyKeyReused is returned when an Idempotency-Key comes back
+	// with a different request.
+	ErrIdempotencyKeyReused = NewError(KindUnprocessable, "idempotency_key_reused", "Idempotency-Key was already used for a different request")
+	// ErrIdempotencyInProgress is returned while the first request with the
+	// key has not been answered yet.
+	ErrIdempotencyInProgress = NewError(KindConflict, "idempotency_in_progress", "A request with this Idempotency-Key is still being processed")
+)
+
+type (
+	// IdempotencyRecord is what is kept for an Idempotency-Key. A zero Status
+	// means the first request is still being processed.
+	IdempotencyRecord struct {
+		Fingerprint string `json:"fingerprint"`
+		Status      int    `json:"status"`
+		ContentType string `json:"content_type"`
+		Body        []byte `json:"body"`
+	}
+)
+
+type (
+	IdempotencyRepository interface {
+		Get(ctx context.Context, key string) (IdempotencyRecord, bool, error)
+		Lock(ctx context.Context, key string, record IdempotencyRecord, expire time.Duration) (bool, error)
+		Save(ctx context.Context, key string, record IdempotencyRecord, expire time.Duration) error
+		Delete(ctx context.Context, key string) error
+	}
+)
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+var ErrUnknownKey = errors.New("Unknown signing key")
+
+type (
+	// SigningKey is one key of a KeyStore. Private is nil for keys that are
+	// only kept to verify tokens signed before a rotation.
+	SigningKey struct {
+		Kid     string
+		Method  jwt.SigningMethod
+		Private interface{}
+		Public  interface{}
+	}
+
+	// JWK is the public part of a signing key as described in RFC 7517.
+	JWK struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		N   string `json:"n,omitempty"`
+		E   string `json:"e,omitempty"`
+		Crv string `json:"crv,omitempty"`
+		X   string `json:"x,omitempty"`
+	}
+
+	JWKS struct {
+		Keys []JWK `json:"keys"`
+	}
+)
+
+type (
+	KeyStore interface {
+		// SigningKey returns the active key new tokens are signed with.
+		SigningKey() SigningKey
+		// VerificationKey returns the key a token with the given kid header
+		// was signed with.
+		VerificationKey(kid string) (SigningKey, error)
+		// JWKS returns the public keys that may be published.
+		JWKS() JWKS
+	}
+)
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/util"
+)
+
+const (
+	// LedgerExternalAccountNumber is the contra account for money entering or
+	// leaving the bank, such as opening balances, deposits, withdrawals and
+	// both sides of a currency conversion.
+	LedgerExternalAccountNumber = 0
+
+	LedgerEntryDebit  = "debit"
+	LedgerEntryCredit = "credit"
+
+	LedgerTransactionOpening    = "opening"
+	LedgerTransactionTransfer   = "transfer"
+	LedgerTransactionDeposit    = "deposit"
+	LedgerTransactionWithdrawal = "withdrawal"
+)
+
+type (
+	LedgerEntry struct {
+		ID              int64     `json:"id"`
+		TransactionID   string    `json:"transaction_id"`
+		TransactionType string    `json:"transaction_type"`
+		AccountNumber   int       `json:"account_number"`
+		EntryType       string    `json:"entry_type"`
+		Amount          Money     `json:"amount"`
+		CreatedAt       time.Time `json:"created_at"`
+	}
+
+	// LedgerEntryListParam pages the entries of an account by offset, newest
+	// first. The ledger has no cursor, search or sort.
+	LedgerEntryListParam struct {
+		util.Filter
+	}
+)
+
+type (
+	LedgerRepository interface {
+		Store(ctx context.Context, entries []LedgerEntry) error
+		ListByAccountNumber(ctx context.Context, accountNumber int, param LedgerEntryListParam) ([]LedgerEntry, error)
+		CountByAccountNumber(ctx context.Context, accountNumber int) (int, error)
+		// GetBalance sums the entries of the account in minor units of its
+		// currency.
+		GetBalance(ctx context.Context, accountNumber int) (int64, error)
+	}
+)
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidCredentials is returned for an unknown email and for a wrong
+// password alike, so a login does not reveal which emails are registered.
+var ErrInvalidCredentials = NewError(KindUnauthorized, "invalid_credentials", "Invalid email or password")
+
+type (
+	// LoginPolicy limits failed logins. Every failure of an email delays its
+	// next attempt by BaseDelay doubled per failure, up to MaxDelay, and
+	// MaxFailures failures within FailureWindow lock it for LockDuration.
+	// An IP reaching MaxIPFailures within FailureWindow is locked as well.
+	LoginPolicy struct {
+		MaxFailures   int
+		MaxIPFailures int
+		FailureWindow time.Duration
+		LockDuration  time.Duration
+		BaseDelay     time.Duration
+		MaxDelay      time.Duration
+	}
+
+	// LoginThrottledError is returned while an email or IP has to wait before
+	// it may try to log in again.
+	LoginThrottledError struct {
+		RetryAfter time.Duration
+	}
+)
+
+func (e LoginThrottledError) Error() string {
+	return fmt.Sprintf("Too many login attempts, retry after %s", e.RetryAfter)
+}
+
+type (
+	// LoginAttemptRepository counts failed logins per subject, an email or an
+	// IP, and blocks subjects for a while.
+	LoginAttemptRepository interface {
+		// RegisterFailure counts a failure and returns the failures within the
+		// window started by the first one.
+		RegisterFailure(ctx context.Context, subject string, window time.Duration) (int64, error)
+		Block(ctx context.Context, subject string, duration time.Duration) error
+		// BlockedFor returns how long the subject is still blocked, zero when
+		// it is not.
+		BlockedFor(ctx context.Context, subject string) (time.Duration, error)
+		// Reset clears the failures and the block of the subject.
+		Reset(ctx context.Context, subject string) error
+	}
+)
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrMFARequired is returned when an action needs a second factor code
+	// and none was given.
+	ErrMFARequired = NewError(KindForbidden, "mfa_required", "Two-factor code required")
+	// ErrInvalidMFACode is returned for a wrong, reused or expired code.
+	ErrInvalidMFACode = NewError(KindUnauthorized, "invalid_mfa_code", "Invalid two-factor code")
+	// ErrMFANotEnrolled is returned when confirming or using TOTP before it
+	// was enrolled.
+	ErrMFANotEnrolled = NewError(KindForbidden, "mfa_not_enrolled", "Two-factor authentication is not enrolled")
+	// ErrMFAAlreadyEnabled is returned when enrolling a second time.
+	ErrMFAAlreadyEnabled = NewError(KindConflict, "mfa_already_enabled", "Two-factor authentication is already enabled")
+	// ErrInvalidMFAToken is returned for an unknown, used or expired login
+	// challenge.
+	ErrInvalidMFAToken = NewError(KindUnauthorized, "invalid_mfa_token", "Invalid or expired MFA token")
+)
+
+type stepUpVerifiedKey struct{}
+
+// WithStepUpVerified marks the transfers made with ctx as past the step-up
+// already, like a scheduled transfer whose code was checked when it was
+// scheduled.
+func WithStepUpVerified(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stepUpVerifiedKey{}, true)
+}
+
+// StepUpVerified reports whether ctx was marked by WithStepUpVerified.
+func StepUpVerified(ctx context.Context) bool {
+	verified, _ := ctx.Value(stepUpVerifiedKey{}).(bool)
+	return verified
+}
+
+type (
+	// MFAPolicy configures two-factor authentication. Transfers of at least
+	// StepUpAmount minor units of StepUpCurrency need a code, zero turns the
+	// step-up off. Transfers in another currency are compared with the
+	// amount converted at the stored exchange rate, without one they always
+	// need a code. StepUpMaxFailures wrong step-up codes within
+	// StepUpLockDuration lock the step-up of the account for
+	// StepUpLockDuration, zero failures never lock.
+	MFAPolicy struct {
+		Issuer             string
+		ChallengeExpire    time.Duration
+		StepUpAmount       int64
+		StepUpCurrency     string
+		StepUpMaxFailures  int
+		StepUpLockDuration time.Duration
+		RecoveryCodes      int
+	}
+
+	// StepUpThrottledError is returned while the step-up of an account is
+	// locked after too many wrong codes.
+	StepUpThrottledError struct {
+		RetryAfter time.Duration
+	}
+
+	// TOTP is the authenticator secret of an account. It is only used once
+	// Enabled is set by confirming a first code. LastStep is the time step of
+	// the last accepted code, a code is never accepted twice.
+	TOTP struct {
+		AccountNumber int
+		Secret        string
+		Enabled       bool
+		LastStep      int64
+	}
+
+	TOTPEnrollment struct {
+		Secret string `json:"secret"`
+		URI    string `json:"otpauth_uri"`
+	}
+
+	TOTPConfirmParam struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	RecoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	// LoginMFAParam completes a login that answered with an MFA challenge.
+	// Code is a TOTP code or an unused recovery code.
+	LoginMFAParam struct {
+		MFAToken  string `json:"mfa_token" binding:"required"`
+		Code      string `json:"code" binding:"required"`
+		UserAgent string `json:"-"`
+		IP        string `json:"-"`
+	}
+)
+
+func (e StepUpThrottledError) Error() string {
+	return fmt.Sprintf("Too many two-factor attempts, retry after %s", e.RetryAfter)
+}
+
+type (
+	MFAUseCase interface {
+		Enroll(ctx context.Context, accountNumber int) (TOTPEnrollment, error)
+		// Confirm enables TOTP with a first code and returns fresh recovery
+		// codes, they are only shown this once.
+		Confirm(ctx context.Context, accountNumber int, code string) (RecoveryCodesResponse, error)
+		Enabled(ctx context.Context, accountNumber int) (bool, error)
+		// Verify accepts a TOTP code or an unused recovery code.
+		Verify(ctx context.Context, accountNumber int, code string) error
+		// VerifyStepUp checks the code when the amount needs a step-up and
+		// does nothing otherwise.
+		VerifyStepUp(ctx context.Context, accountNumber int, amount Money, code string) error
+		CreateChallenge(ctx context.Context, accountNumber int) (string, error)
+		ConsumeChallenge(ctx context.Context, token string) (int, error)
+	}
+
+	MFARepository interface {
+		GetTOTP(ctx context.Context, accountNumber int) (TOTP, error)
+		// StoreTOTP replaces the secret of the account.
+		StoreTOTP(ctx context.Context, t *TOTP) error
+		EnableTOTP(ctx context.Context, accountNumber int) error
+		// UseStep records the step of an accepted code. It returns false when
+		// the step is not newer than the last one, i.e. the code was used.
+		UseStep(ctx context.Context, accountNumber int, step int64) (bool, error)
+		// StoreRecoveryCodes replaces the recovery codes of the account.
+		StoreRecoveryCodes(ctx context.Context, accountNumber int, hashes []string) error
+		// UseRecoveryCode marks the code used. It returns false for an unknown
+		// or already used code.
+		UseRecoveryCode(ctx context.Context, accountNumber int, hash string) (bool, error)
+	}
+
+	// MFAChallengeRepository keeps the login challenges between the password
+	// and the code step.
+	MFAChallengeRepository interface {
+		Store(ctx context.Context, token string, accountNumber int, expire time.Duration) error
+		// Consume deletes the challenge and returns its account, a challenge
+		// can only be answered once.
+		Consume(ctx context.Context, token string) (int, error)
+	}
+)
@@ -0,0 +1,263 @@
+package domain
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultCurrency is the currency of accounts opened without one. Balances
+// from before accounts had a currency are in it as well.
+const DefaultCurrency = "IDR"
+
+var (
+	ErrInvalidAmount    = NewError(KindValidation, "invalid_amount", "Amount must be positive")
+	ErrInvalidCurrency  = NewError(KindValidation, "invalid_currency", "Unknown currency")
+	ErrCurrencyMismatch = NewError(KindValidation, "currency_mismatch", "Currencies do not match")
+	ErrAmountOverflow   = NewError(KindValidation, "amount_overflow", "Amount is too large")
+)
+
+// Rounding modes of converted amounts.
+const (
+	roundHalfEven = iota
+	roundHalfUp
+	roundDown
+)
+
+// currency describes a currency accounts can hold. exponent is its ISO 4217
+// minor unit, 2 means an amount of 1050 is 10.50. Converted amounts are
+// rounded to a multiple of increment minor units with the rounding mode.
+type currency struct {
+	exponent  int
+	increment int64
+	rounding  int
+}
+
+// currencies are the currencies accounts can hold. Rupiah cents are not in
+// use, converted rupiah are rounded to whole rupiah, and won is never
+// rounded up so a conversion does not credit more than it was worth.
+var currencies = map[string]currency{
+	"AUD": {exponent: 2, increment: 1, rounding: roundHalfEven},
+	"BHD": {exponent: 3, increment: 1, rounding: roundHalfUp},
+	"EUR": {exponent: 2, increment: 1, rounding: roundHalfEven},
+	"GBP": {exponent: 2, increment: 1, rounding: roundHalfEven},
+	"IDR": {exponent: 2, increment: 100, rounding: roundHalfUp},
+	"JPY": {exponent: 0, increment: 1, rounding: roundHalfUp},
+	"KRW": {exponent: 0, increment: 1, rounding: roundDown},
+	"KWD": {exponent: 3, increment: 1, rounding: roundHalfUp},
+	"MYR": {exponent: 2, increment: 1, rounding: roundHalfEven},
+	"SGD": {exponent: 2, increment: 1, rounding: roundHalfEven},
+	"USD": {exponent: 2, increment: 1, rounding: roundHalfEven},
+}
+
+// Money is an amount in the minor units of an ISO 4217 currency. It is
+// written to JSON as {"amount":"10.50","currency":"USD"}, the amount as a
+// decimal string so no client parses it into a float.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Validate returns ErrInvalidCurrency unless the currency is one accounts can
+// hold.
+func (m Money) Validate() error {
+	if _, ok := currencies[m.Currency]; !ok {
+		return ErrInvalidCurrency
+	}
+
+	return nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns m + o. It fails with ErrCurrencyMismatch for amounts in
+// different currencies and with ErrAmountOverflow when the sum does not fit.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	if (o.Amount > 0 && m.Amount > math.MaxInt64-o.Amount) ||
+		(o.Amount < 0 && m.Amount < math.MinInt64-o.Amount) {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o, failing like Add.
+func (m Money) Sub(o Money) (Money, error) {
+	if o.Amount == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return m.Add(Money{Amount: -o.Amount, Currency: o.Currency})
+}
+
+// Convert returns m in currency, where one unit of m.Currency is worth rate
+// units of currency. The result is rounded by the rules of currency.
+func (m Money) Convert(currency string, rate *big.Rat) (Money, error) {
+	from, ok := currencies[m.Currency]
+	if !ok {
+		return Money{}, ErrInvalidCurrency
+	}
+
+	to, ok := currencies[currency]
+	if !ok {
+		return Money{}, ErrInvalidCurrency
+	}
+
+	// Minor units of m times the rate, scaled to the minor units of currency
+	// and counted in increments.
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate)
+	converted.Mul(converted, pow10(to.exponent))
+	converted.Quo(converted, pow10(from.exponent))
+	converted.Quo(converted, new(big.Rat).SetInt64(to.increment))
+
+	amount := round(converted, to.rounding)
+	amount.Mul(amount, big.NewInt(to.increment))
+	if !amount.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{Amount: amount.Int64(), Currency: currency}, nil
+}
+
+func pow10(n int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil))
+}
+
+// round rounds r to an integer with the rounding mode. Halves are measured
+// away from zero, so negative amounts round like positive ones.
+func round(r *big.Rat, rounding int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(new(big.Int).Abs(r.Num()), r.Denom(), new(big.Int))
+
+	half := new(big.Int).Lsh(remainder, 1).Cmp(r.Denom())
+	switch {
+	case rounding == roundHalfUp && half >= 0,
+		rounding == roundHalfEven && (half > 0 || half == 0 && quotient.Bit(0) == 1):
+		quotient.Add(quotient, big.NewInt(1))
+	}
+
+	if r.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+
+	return quotient
+}
+
+// String formats m like 10.50 USD.
+func (m Money) String() string {
+	return m.decimal() + " " + m.Currency
+}
+
+// decimal formats the amount with the minor units of the currency.
+func (m Money) decimal() string {
+	exponent := currencies[m.Currency].exponent
+
+	sign := ""
+	amount := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		amount = uint64(-(m.Amount + 1)) + 1
+	}
+
+	digits := strconv.FormatUint(amount, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount, err := json.Marshal(m.decimal())
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(moneyJSON{Amount: amount, Currency: m.Currency})
+}
+
+// UnmarshalJSON reads the amount from a decimal string or number with at
+// most as many decimals as the currency has minor units. An unknown currency
+// is left for Validate.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	var raw moneyJSON
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(raw.Currency))
+
+	decimal := strings.Trim(string(raw.Amount), `"`)
+	if decimal == "" || decimal == "null" {
+		*m = Money{Currency: currency}
+		return nil
+	}
+
+	amount, err := parseDecimal(decimal, currencies[currency].exponent)
+	if err != nil {
+		return err
+	}
+
+	*m = Money{Amount: amount, Currency: currency}
+	return nil
+}
+
+// parseDecimal reads s, like -10.50, into minor units.
+func parseDecimal(s string, exponent int) (int64, error) {
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+
+	if len(fraction) > exponent {
+		return 0, errors.Errorf("amount %q has more than %d decimals", s, exponent)
+	}
+
+	negative := strings.HasPrefix(whole, "-")
+	digits := strings.TrimPrefix(whole, "-") + fraction + strings.Repeat("0", exponent-len(fraction))
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return 0, errors.Errorf("amount %q is not a decimal", s)
+	}
+
+	amount, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil || amount > math.MaxInt64 {
+		return 0, ErrAmountOverflow
+	}
+
+	if negative {
+		return -int64(amount), nil
+	}
+
+	return int64(amount), nil
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+type (
+	// Notifier delivers messages to account holders, e.g. by email. The
+	// message is addressed to the email of the account.
+	Notifier interface {
+		Notify(ctx context.Context, to string, subject string, body string) error
+	}
+)
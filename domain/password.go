@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrWrongPassword is returned when the current password given to change
+	// it does not match.
+	ErrWrongPassword = NewError(KindUnauthorized, "wrong_password", "Wrong password")
+	// ErrInvalidResetToken is returned for an unknown, used or expired
+	// password reset token.
+	ErrInvalidResetToken = NewError(KindValidation, "invalid_reset_token", "Invalid or expired reset token")
+)
+
+// bcryptMaxPasswordBytes is the longest password bcrypt hashes, anything
+// after it would be ignored silently.
+const bcryptMaxPasswordBytes = 72
+
+type (
+	// PasswordPolicy holds the strength rules new passwords must meet and the
+	// bcrypt cost they are hashed with.
+	PasswordPolicy struct {
+		MinLength     int
+		RequireUpper  bool
+		RequireLower  bool
+		RequireDigit  bool
+		RequireSymbol bool
+		BcryptCost    int
+		// ResetTokenExpire is how long a password reset token can be used.
+		ResetTokenExpire time.Duration
+	}
+
+	PasswordChangeParam struct {
+		OldPassword string `json:"old_password" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+
+	PasswordResetRequestParam struct {
+		Email string `json:"email" binding:"required"`
+	}
+
+	PasswordResetParam struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+)
+
+// Validate returns a validation Error with code weak_password when the
+// password breaks any rule, its Details list every broken rule.
+func (p PasswordPolicy) Validate(password string) error {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var violations []string
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if len(password) > bcryptMaxPasswordBytes {
+		violations = append(violations, fmt.Sprintf("must be at most %d bytes", bcryptMaxPasswordBytes))
+	}
+	if p.RequireUpper && !upper {
+		violations = append(violations, "must contain an upper case letter")
+	}
+	if p.RequireLower && !lower {
+		violations = append(violations, "must contain a lower case letter")
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, "must contain a symbol")
+	}
+
+	if len(violations) > 0 {
+		return &Error{
+			Kind:    KindValidation,
+			Code:    "weak_password",
+			Message: "Password does not meet the policy: " + strings.Join(violations, ", "),
+			Details: violations,
+		}
+	}
+
+	return nil
+}
+
+type (
+	// PasswordResetRepository keeps password reset tokens until they are used
+	// or expire.
+	PasswordResetRepository interface {
+		Store(ctx context.Context, token string, accountNumber int, expire time.Duration) error
+		// Consume deletes the token and returns its account, a token can only
+		// be consumed once.
+		Consume(ctx context.Context, token string) (int, error)
+	}
+)
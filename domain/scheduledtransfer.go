@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// An active scheduled transfer runs at NextRunAt. A one-off transfer is
+	// completed once it ran, or failed when it ran out of attempts.
+	ScheduledTransferStatusActive    = "active"
+	ScheduledTransferStatusCompleted = "completed"
+	ScheduledTransferStatusFailed    = "failed"
+	ScheduledTransferStatusCancelled = "cancelled"
+)
+
+var (
+	ErrScheduledTransferNotFound = NewError(KindNotFound, "scheduled_transfer_not_found", "Scheduled transfer not exists")
+	// ErrScheduledTransferClosed is returned for a change to a scheduled
+	// transfer that was completed or cancelled.
+	ErrScheduledTransferClosed = NewError(KindConflict, "scheduled_transfer_closed", "Scheduled transfer is completed or cancelled")
+	// ErrInvalidRecurrence is returned for a recurrence that is no cron rule
+	// or never matches.
+	ErrInvalidRecurrence = NewError(KindValidation, "invalid_recurrence", "Invalid recurrence")
+	// ErrInvalidRunAt is returned for a run_at in the past, or a one-off
+	// transfer without one.
+	ErrInvalidRunAt = NewError(KindValidation, "invalid_run_at", "run_at must be in the future")
+)
+
+type (
+	// ScheduledTransfer is a transfer to run at NextRunAt, again at every
+	// match of Recurrence, a cron rule in UTC, when it has one. LastError is
+	// the error code of the last failed attempt.
+	ScheduledTransfer struct {
+		ID                int64     `json:"id"`
+		FromAccountNumber int       `json:"from_account_number"`
+		ToAccountNumber   int       `json:"to_account_number"`
+		Amount            Money     `json:"amount"`
+		Description       string    `json:"description,omitempty"`
+		Recurrence        string    `json:"recurrence,omitempty"`
+		NextRunAt         time.Time `json:"next_run_at"`
+		Status            string    `json:"status"`
+		Attempts          int       `json:"attempts"`
+		LastError         string    `json:"last_error,omitempty"`
+		LastTransferID    string    `json:"last_transfer_id,omitempty"`
+		CreatedAt         time.Time `json:"created_at"`
+		UpdatedAt         time.Time `json:"updated_at"`
+	}
+
+	// ScheduledTransferParam schedules a transfer for RunAt. With a
+	// Recurrence RunAt may be left out, the transfer then first runs at the
+	// next match of the rule. OTP is checked once, for every run.
+	ScheduledTransferParam struct {
+		ToAccountNumber string    `json:"to_account_number" binding:"required"`
+		Amount          Money     `json:"amount"`
+		Description     string    `json:"description" binding:"max=255"`
+		RunAt           time.Time `json:"run_at"`
+		Recurrence      string    `json:"recurrence" binding:"max=100"`
+		OTP             string    `json:"otp,omitempty"`
+	}
+
+	// SchedulerPolicy configures the scheduler. Every Interval it runs up to
+	// BatchSize due transfers. A failed run is retried after RetryBaseDelay,
+	// doubled on every further failure up to RetryMaxDelay, MaxAttempts times
+	// in all.
+	SchedulerPolicy struct {
+		Interval       time.Duration
+		BatchSize      int
+		MaxAttempts    int
+		RetryBaseDelay time.Duration
+		RetryMaxDelay  time.Duration
+	}
+)
+
+type (
+	ScheduledTransferUseCase interface {
+		List(ctx context.Context, accountNumber int) ([]ScheduledTransfer, error)
+		Get(ctx context.Context, accountNumber int, id int64) (ScheduledTransfer, error)
+		Store(ctx context.Context, accountNumber int, param ScheduledTransferParam) (ScheduledTransfer, error)
+		// Update replaces the plan of an active or failed scheduled transfer
+		// and makes it active again.
+		Update(ctx context.Context, accountNumber int, id int64, param ScheduledTransferParam) (ScheduledTransfer, error)
+		Cancel(ctx context.Context, accountNumber int, id int64) error
+		// RunDue runs the transfers that are due and returns how many it ran,
+		// whether they succeeded or not.
+		RunDue(ctx context.Context) (int, error)
+	}
+
+	ScheduledTransferRepository interface {
+		List(ctx context.Context, accountNumber int) ([]ScheduledTransfer, error)
+		Get(ctx context.Context, accountNumber int, id int64) (ScheduledTransfer, error)
+		Store(ctx context.Context, s *ScheduledTransfer) error
+		// Update stores the plan of an active or failed scheduled transfer,
+		// it returns sql.ErrNoRows for any other.
+		Update(ctx context.Context, s *ScheduledTransfer) error
+		// ClaimDue locks the active scheduled transfer that is due the
+		// longest, skipping those another transaction holds, and returns
+		// sql.ErrNoRows when there is none. It needs a transaction.
+		ClaimDue(ctx context.Context, now time.Time) (ScheduledTransfer, error)
+		// UpdateRun stores the outcome of a run of a transfer that was due at
+		// dueAt, it returns sql.ErrNoRows when the transfer has moved on since.
+		UpdateRun(ctx context.Context, s *ScheduledTransfer, dueAt time.Time) error
+	}
+)
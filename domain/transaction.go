@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+type (
+	TransactionManager interface {
+		WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	}
+)
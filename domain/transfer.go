@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/util"
+)
+
+const (
+	TransferStatusCompleted = "completed"
+
+	// A deposit comes from and a withdrawal goes to
+	// LedgerExternalAccountNumber.
+	TransferTypeTransfer   = "transfer"
+	TransferTypeDeposit    = "deposit"
+	TransferTypeWithdrawal = "withdrawal"
+
+	ChannelBranch = "branch"
+	ChannelATM    = "atm"
+	ChannelOnline = "online"
+)
+
+var (
+	// ErrInvalidDateRange is returned when a listing ends before it starts.
+	ErrInvalidDateRange = NewError(KindValidation, "invalid_date_range", "end_date must not be before start_date")
+	// ErrDuplicateReference is returned for a deposit or withdrawal with a
+	// reference the channel already used, so a retried request never moves
+	// the money twice.
+	ErrDuplicateReference = NewError(KindConflict, "duplicate_reference", "Reference already used on this channel")
+)
+
+// TransferSort declares the fields transfers can be sorted by, the newest
+// come first by default.
+var TransferSort = util.SortSpec{
+	Fields: []string{"created_at", "amount"},
+	Unique: "id",
+	Order:  "DESC",
+}
+
+type (
+	// Transfer debits Amount from the sender and credits ConvertedAmount,
+	// Amount in the currency of the receiving account, to the receiver. FXRate
+	// is the rate it was converted at, empty when both accounts hold the same
+	// currency. Deposits and withdrawals are stored as transfers too, with
+	// the Reference, Channel and ReasonCode they were made with.
+	Transfer struct {
+		ID                string    `json:"id"`
+		Type              string    `json:"type"`
+		FromAccountNumber int       `json:"from_account_number"`
+		ToAccountNumber   int       `json:"to_account_number"`
+		Amount            Money     `json:"amount"`
+		ConvertedAmount   Money     `json:"converted_amount"`
+		FXRate            string    `json:"fx_rate,omitempty"`
+		Status            string    `json:"status"`
+		Description       string    `json:"description,omitempty"`
+		Reference         string    `json:"reference,omitempty"`
+		Channel           string    `json:"channel,omitempty"`
+		ReasonCode        string    `json:"reason_code,omitempty"`
+		CreatedAt         time.Time `json:"created_at"`
+		UpdatedAt         time.Time `json:"updated_at"`
+	}
+
+	// MovementParam is a deposit to or a withdrawal from an account. The
+	// reference is unique per channel.
+	MovementParam struct {
+		Amount      Money  `json:"amount"`
+		Reference   string `json:"reference" binding:"required,max=64"`
+		Channel     string `json:"channel" binding:"required,oneof=branch atm online"`
+		ReasonCode  string `json:"reason_code" binding:"required,max=32"`
+		Description string `json:"description"`
+	}
+
+	// TransferListParam filters transfers by the day they were created, both
+	// ends of the range are inclusive.
+	TransferListParam struct {
+		util.Filter
+		StartDate time.Time       `json:"start_date" form:"start_date" time_format:"2006-01-02" time_utc:"1"`
+		EndDate   time.Time       `json:"end_date" form:"end_date" time_format:"2006-01-02" time_utc:"1"`
+		After     *TransferCursor `json:"-" form:"-"`
+	}
+
+	// TransferCursor is the sort key a transfer listing continues after, for
+	// the sort it was issued for. The id breaks ties between transfers of the
+	// same instant or amount.
+	TransferCursor struct {
+		Sort      string    `json:"sort"`
+		CreatedAt time.Time `json:"created_at"`
+		Amount    int64     `json:"amount"`
+		ID        string    `json:"id"`
+	}
+)
+
+type (
+	TransferRepository interface {
+		Store(ctx context.Context, t *Transfer) error
+		ListByAccountNumber(ctx context.Context, accountNumber int, param TransferListParam) ([]Transfer, error)
+		CountByAccountNumber(ctx context.Context, accountNumber int, param TransferListParam) (int, error)
+		// OutgoingTotal sums the transfers and withdrawals that left the
+		// account from since.
+		OutgoingTotal(ctx context.Context, accountNumber int, since time.Time) (int64, error)
+		// OutgoingCount counts the transfers the account sent from since,
+		// withdrawals left out.
+		OutgoingCount(ctx context.Context, accountNumber int, since time.Time) (int, error)
+	}
+)
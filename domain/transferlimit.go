@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// The limits a transfer can exceed, TransferLimitExceededError.Limit is one
+// of them.
+const (
+	TransferLimitPerTransfer = "per_transfer"
+	TransferLimitDaily       = "daily"
+	TransferLimitMonthly     = "monthly"
+	TransferLimitHourlyCount = "hourly_count"
+)
+
+// TransferLimitExceededCode is the error code of TransferLimitExceededError.
+const TransferLimitExceededCode = "transfer_limit_exceeded"
+
+var (
+	// ErrInvalidTier is returned for a tier LimitPolicy does not know.
+	ErrInvalidTier = NewError(KindValidation, "invalid_tier", "Unknown account tier")
+	// ErrInvalidTransferLimit is returned for a negative limit.
+	ErrInvalidTransferLimit = NewError(KindValidation, "invalid_transfer_limit", "Transfer limits must not be negative")
+	// ErrTransferLimitsUnavailable is returned when the tier has no amounts in
+	// the currency of the account and they cannot be converted for lack of a
+	// usable exchange rate.
+	ErrTransferLimitsUnavailable = NewError(KindUnprocessable, "transfer_limits_unavailable", "No transfer limits for the account currency")
+)
+
+type (
+	// TierLimits are the transfer limits of an account tier. The amounts are
+	// in minor units of LimitPolicy.Currency. Accounts in a currency of
+	// Currencies get the amounts given there, accounts in any other currency
+	// get them converted at the stored exchange rate. Zero is no limit.
+	TierLimits struct {
+		PerTransfer int64
+		Daily       int64
+		Monthly     int64
+		HourlyCount int
+		Currencies  map[string]TierAmounts
+	}
+
+	// TierAmounts are the amount limits of a tier in minor units of one
+	// currency.
+	TierAmounts struct {
+		PerTransfer int64
+		Daily       int64
+		Monthly     int64
+	}
+
+	// LimitPolicy configures the transfer limits. Accounts without a tier of
+	// their own are in DefaultTier.
+	LimitPolicy struct {
+		DefaultTier string
+		Currency    string
+		Tiers       map[string]TierLimits
+	}
+
+	// AccountLimit is the tier of an account and the limits it overrides, a
+	// nil limit is the one of the tier.
+	AccountLimit struct {
+		AccountNumber int
+		Tier          string
+		PerTransfer   *int64
+		Daily         *int64
+		Monthly       *int64
+		HourlyCount   *int
+		UpdatedAt     time.Time
+	}
+
+	// AccountLimitParam puts an account in a tier, the default tier when Tier
+	// is left out, and overrides its limits. The amounts are in the currency
+	// of the account. A limit left out is the one of the tier, zero is no
+	// limit.
+	AccountLimitParam struct {
+		Tier        string `json:"tier" binding:"max=20"`
+		PerTransfer *Money `json:"per_transfer"`
+		Daily       *Money `json:"daily"`
+		Monthly     *Money `json:"monthly"`
+		HourlyCount *int   `json:"hourly_count" binding:"omitempty,min=0"`
+	}
+
+	// TransferLimits are the limits in effect for an account, zero is no
+	// limit. Overrides names the limits that are not the ones of the tier.
+	TransferLimits struct {
+		AccountNumber int      `json:"account_number"`
+		Tier          string   `json:"tier"`
+		PerTransfer   Money    `json:"per_transfer"`
+		Daily         Money    `json:"daily"`
+		Monthly       Money    `json:"monthly"`
+		HourlyCount   int      `json:"hourly_count"`
+		Overrides     []string `json:"overrides,omitempty"`
+	}
+
+	// TransferLimitExceededError is returned for a transfer over a limit of
+	// the sender. Remaining is what the amount limits still allow,
+	// RemainingCount how many transfers the hourly count does.
+	TransferLimitExceededError struct {
+		Limit          string `json:"limit"`
+		Remaining      *Money `json:"remaining,omitempty"`
+		RemainingCount *int   `json:"remaining_count,omitempty"`
+	}
+)
+
+func (e TransferLimitExceededError) Error() string {
+	if e.Remaining != nil {
+		return fmt.Sprintf("Transfer limit %s exceeded, %s remaining", e.Limit, e.Remaining)
+	}
+
+	return fmt.Sprintf("Transfer limit %s exceeded", e.Limit)
+}
+
+type (
+	TransferLimitUseCase interface {
+		Get(ctx context.Context, accountNumber int) (TransferLimits, error)
+		Set(ctx context.Context, accountNumber int, param AccountLimitParam) (TransferLimits, error)
+		// Reset puts the account back in the default tier without overrides.
+		Reset(ctx context.Context, accountNumber int) error
+		// Check returns a TransferLimitExceededError when a transfer of
+		// amount from the account breaks one of its limits. It counts the
+		// transfers already stored, so it has to run in the transaction of
+		// the debit after the sender account is locked.
+		Check(ctx context.Context, account Account, amount Money) error
+	}
+
+	TransferLimitRepository interface {
+		// Get returns sql.ErrNoRows for an account in the default tier
+		// without overrides.
+		Get(ctx context.Context, accountNumber int) (AccountLimit, error)
+		Upsert(ctx context.Context, limit AccountLimit) error
+		// Delete returns sql.ErrNoRows when the account had no limits of its
+		// own.
+		Delete(ctx context.Context, accountNumber int) error
+	}
+)
@@ -0,0 +1,196 @@
+package keystore
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+type keyStore struct {
+	activeKid string
+	keys      map[string]domain.SigningKey
+}
+
+func (k keyStore) SigningKey() domain.SigningKey {
+	return k.keys[k.activeKid]
+}
+
+func (k keyStore) VerificationKey(kid string) (domain.SigningKey, error) {
+	key, ok := k.keys[kid]
+	if !ok {
+		return domain.SigningKey{}, errors.Wrap(domain.ErrUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+// JWKS lists the asymmetric keys, the active one first. Symmetric keys are
+// never published.
+func (k keyStore) JWKS() domain.JWKS {
+	kids := make([]string, 0, len(k.keys))
+	for kid := range k.keys {
+		kids = append(kids, kid)
+	}
+	sort.Slice(kids, func(i, j int) bool {
+		if kids[i] == k.activeKid || kids[j] == k.activeKid {
+			return kids[i] == k.activeKid
+		}
+		return kids[i] < kids[j]
+	})
+
+	jwks := domain.JWKS{Keys: make([]domain.JWK, 0, len(kids))}
+	for _, kid := range kids {
+		key := k.keys[kid]
+
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, domain.JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, domain.JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+
+	return jwks
+}
+
+// NewKeyStore builds a key store signing with the key named activeKid. The
+// other keys are only used to verify tokens issued before a rotation.
+func NewKeyStore(activeKid string, keys ...domain.SigningKey) (domain.KeyStore, error) {
+	store := keyStore{
+		activeKid: activeKid,
+		keys:      make(map[string]domain.SigningKey, len(keys)),
+	}
+
+	for _, key := range keys {
+		if _, ok := store.keys[key.Kid]; ok {
+			return nil, errors.Errorf("duplicate key id %q", key.Kid)
+		}
+		store.keys[key.Kid] = key
+	}
+
+	active, ok := store.keys[activeKid]
+	if !ok {
+		return nil, errors.Wrap(domain.ErrUnknownKey, activeKid)
+	}
+
+	if active.Private == nil {
+		return nil, errors.Errorf("active key %q has no private key", activeKid)
+	}
+
+	return store, nil
+}
+
+// NewHMACKey returns an HS256 key, used when no key files are configured.
+func NewHMACKey(kid, secret string) domain.SigningKey {
+	return domain.SigningKey{
+		Kid:     kid,
+		Method:  jwt.SigningMethodHS256,
+		Private: []byte(secret),
+		Public:  []byte(secret),
+	}
+}
+
+// LoadDir reads every *.pem file of dir as one key named after the file, so
+// keys/2021-04.pem gets the kid "2021-04". Private keys may sign and verify,
+// public keys only verify.
+func LoadDir(dir, activeKid string) (domain.KeyStore, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]domain.SigningKey, 0, len(files))
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+
+		key, err := ParsePEM(kid, data)
+		if err != nil {
+			return nil, errors.Wrap(err, file)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return NewKeyStore(activeKid, keys...)
+}
+
+// ParsePEM parses an RSA or Ed25519 key. RSA keys sign with RS256 and Ed25519
+// keys with EdDSA.
+func ParsePEM(kid string, data []byte) (domain.SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return domain.SigningKey{}, errors.New("no PEM block found")
+	}
+
+	var (
+		private interface{}
+		public  interface{}
+		err     error
+	)
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return domain.SigningKey{}, errors.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		public = &key.PublicKey
+	case ed25519.PrivateKey:
+		public = key.Public()
+	case nil:
+	default:
+		return domain.SigningKey{}, errors.Errorf("unsupported private key %T", private)
+	}
+
+	switch public.(type) {
+	case *rsa.PublicKey:
+		return domain.SigningKey{Kid: kid, Method: jwt.SigningMethodRS256, Private: private, Public: public}, nil
+	case ed25519.PublicKey:
+		return domain.SigningKey{Kid: kid, Method: SigningMethodEdDSA, Private: private, Public: public}, nil
+	default:
+		return domain.SigningKey{}, errors.Errorf("unsupported public key %T", public)
+	}
+}
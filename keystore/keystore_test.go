@@ -0,0 +1,143 @@
+package keystore
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePEM(t *testing.T, dir, kid, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+
+	err := ioutil.WriteFile(filepath.Join(dir, kid+".pem"), data, 0600)
+	assert.NoError(t, err)
+}
+
+func TestLoadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	assert.NoError(t, err)
+	writePEM(t, dir, "2021-04", "PRIVATE KEY", edDER)
+
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	rsaDER, err := x509.MarshalPKIXPublicKey(&rsaPrivate.PublicKey)
+	assert.NoError(t, err)
+	writePEM(t, dir, "2021-01", "PUBLIC KEY", rsaDER)
+
+	t.Run("Success", func(t *testing.T) {
+		keyStore, err := LoadDir(dir, "2021-04")
+		assert.NoError(t, err)
+
+		signingKey := keyStore.SigningKey()
+		assert.Equal(t, "2021-04", signingKey.Kid)
+		assert.Equal(t, "EdDSA", signingKey.Method.Alg())
+
+		retired, err := keyStore.VerificationKey("2021-01")
+		assert.NoError(t, err)
+		assert.Equal(t, "RS256", retired.Method.Alg())
+		assert.Nil(t, retired.Private)
+
+		assert.Equal(t, domain.JWKS{Keys: []domain.JWK{
+			{
+				Kty: "OKP",
+				Kid: "2021-04",
+				Use: "sig",
+				Alg: "EdDSA",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(edPublic),
+			},
+			{
+				Kty: "RSA",
+				Kid: "2021-01",
+				Use: "sig",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(rsaPrivate.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaPrivate.E)).Bytes()),
+			},
+		}}, keyStore.JWKS())
+	})
+
+	t.Run("Active-key-without-private-key", func(t *testing.T) {
+		_, err := LoadDir(dir, "2021-01")
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown-active-key", func(t *testing.T) {
+		_, err := LoadDir(dir, "2020-12")
+		assert.Equal(t, domain.ErrUnknownKey, errors.Cause(err))
+	})
+}
+
+func TestParsePEM(t *testing.T) {
+	t.Run("PKCS1-private-key", func(t *testing.T) {
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(t, err)
+
+		data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+
+		key, err := ParsePEM("rsa", data)
+		assert.NoError(t, err)
+		assert.Equal(t, jwt.SigningMethodRS256, key.Method)
+		assert.Equal(t, &private.PublicKey, key.Public)
+	})
+
+	t.Run("Unsupported-block", func(t *testing.T) {
+		data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("cert")})
+
+		_, err := ParsePEM("cert", data)
+		assert.Error(t, err)
+	})
+
+	t.Run("Not-PEM", func(t *testing.T) {
+		_, err := ParsePEM("garbage", []byte("garbage"))
+		assert.Error(t, err)
+	})
+}
+
+func TestNewKeyStore(t *testing.T) {
+	t.Run("HMAC-keys-are-not-published", func(t *testing.T) {
+		keyStore, err := NewKeyStore("default", NewHMACKey("default", "secret"))
+		assert.NoError(t, err)
+		assert.Empty(t, keyStore.JWKS().Keys)
+	})
+
+	t.Run("Duplicate-kid", func(t *testing.T) {
+		_, err := NewKeyStore("default", NewHMACKey("default", "secret"), NewHMACKey("default", "other"))
+		assert.Error(t, err)
+	})
+}
+
+func TestSigningMethodEdDSA(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	signature, err := SigningMethodEdDSA.Sign("header.payload", private)
+	assert.NoError(t, err)
+
+	assert.NoError(t, SigningMethodEdDSA.Verify("header.payload", signature, public))
+	assert.Equal(t, jwt.ErrSignatureInvalid, SigningMethodEdDSA.Verify("header.tampered", signature, public))
+	assert.Equal(t, jwt.ErrInvalidKeyType, SigningMethodEdDSA.Verify("header.payload", signature, []byte("secret")))
+
+	method := jwt.GetSigningMethod("EdDSA")
+	assert.Equal(t, SigningMethodEdDSA, method)
+}
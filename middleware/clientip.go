@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ClientIPKey = "client_ip"
+
+// ParseTrustedProxies parses the IPs and CIDR ranges of the proxies in front
+// of the service.
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, &net.ParseError{Type: "IP address", Text: proxy}
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+// ClientIP stores the IP of the client in the gin context. X-Forwarded-For is
+// only read from a trusted proxy, and only up to the first address that is
+// not a trusted proxy itself, everything left of it is what the client sent.
+// Without trusted proxies the client is the peer of the connection.
+func ClientIP(trustedProxies []*net.IPNet) gin.HandlerFunc {
+	trusted := func(ip net.IP) bool {
+		for _, network := range trustedProxies {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(ctx *gin.Context) {
+		ip := remoteIP(ctx)
+
+		if parsed := net.ParseIP(ip); parsed != nil && trusted(parsed) {
+			forwarded := strings.Split(strings.Join(ctx.Request.Header.Values("X-Forwarded-For"), ","), ",")
+			for i := len(forwarded) - 1; i >= 0; i-- {
+				hop := net.ParseIP(strings.TrimSpace(forwarded[i]))
+				if hop == nil {
+					break
+				}
+
+				ip = hop.String()
+				if !trusted(hop) {
+					break
+				}
+			}
+		}
+
+		ctx.Set(ClientIPKey, ip)
+
+		ctx.Next()
+	}
+}
+
+// GetClientIP returns the IP stored by ClientIP, or the peer of the
+// connection without it.
+func GetClientIP(ctx *gin.Context) string {
+	if ip, ok := ctx.Get(ClientIPKey); ok {
+		if ip, ok := ip.(string); ok {
+			return ip
+		}
+	}
+
+	return remoteIP(ctx)
+}
+
+func remoteIP(ctx *gin.Context) string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(ctx.Request.RemoteAddr))
+	if err != nil {
+		return ""
+	}
+
+	return host
+}
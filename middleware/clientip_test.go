@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	networks, err := ParseTrustedProxies([]string{"10.0.0.1", "172.16.0.0/12", "::1"})
+	assert.NoError(t, err)
+	assert.Len(t, networks, 3)
+	assert.Equal(t, "10.0.0.1/32", networks[0].String())
+	assert.Equal(t, "172.16.0.0/12", networks[1].String())
+	assert.Equal(t, "::1/128", networks[2].String())
+
+	_, err = ParseTrustedProxies([]string{"proxy"})
+	assert.Error(t, err)
+}
+
+func TestClientIP(t *testing.T) {
+	trustedProxies, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name         string
+		behindProxy  bool
+		remoteAddr   string
+		forwardedFor []string
+		ip           string
+	}{
+		{"No-proxy", false, "203.0.113.7:51234", nil, "203.0.113.7"},
+		{"Spoofed-without-trusted-proxy", false, "203.0.113.7:51234", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"Spoofed-from-untrusted-peer", true, "203.0.113.7:51234", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"Trusted-proxy", true, "10.0.0.2:51234", []string{"203.0.113.7"}, "203.0.113.7"},
+		{"Spoofed-through-trusted-proxy", true, "10.0.0.2:51234", []string{"198.51.100.1, 203.0.113.7"}, "203.0.113.7"},
+		{"Proxy-chain", true, "10.0.0.2:51234", []string{"198.51.100.1, 203.0.113.7", "10.0.0.3"}, "203.0.113.7"},
+		{"Invalid-hop", true, "10.0.0.2:51234", []string{"not-an-ip"}, "10.0.0.2"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var proxies []*net.IPNet
+			if c.behindProxy {
+				proxies = trustedProxies
+			}
+
+			var ip string
+			r := gin.New()
+			r.Use(ClientIP(proxies))
+			r.GET("/", func(ctx *gin.Context) {
+				ip = GetClientIP(ctx)
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = c.remoteAddr
+			for _, forwardedFor := range c.forwardedFor {
+				req.Header.Add("X-Forwarded-For", forwardedFor)
+			}
+
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, c.ip, ip)
+		})
+	}
+}
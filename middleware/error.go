@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+var errorStatus = map[domain.ErrorKind]int{
+	domain.KindInternal:          http.StatusInternalServerError,
+	domain.KindValidation:        http.StatusBadRequest,
+	domain.KindUnauthorized:      http.StatusUnauthorized,
+	domain.KindForbidden:         http.StatusForbidden,
+	domain.KindNotFound:          http.StatusNotFound,
+	domain.KindConflict:          http.StatusConflict,
+	domain.KindInsufficientFunds: http.StatusBadRequest,
+	domain.KindUnprocessable:     http.StatusUnprocessableEntity,
+	domain.KindTooManyRequests:   http.StatusTooManyRequests,
+}
+
+// ErrorHandler renders the last error a handler added with ctx.Error, unless
+// the handler already wrote a response. It has to be registered after
+// Idempotency, so the rendered error is what gets remembered.
+func ErrorHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		renderError(ctx, ctx.Errors.Last().Err)
+	}
+}
+
+// renderError answers with the status of the error kind and its code. Errors
+// that are not a domain.Error are internal and their message is not shown.
+func renderError(ctx *gin.Context, err error) {
+	cause := errors.Cause(err)
+
+	if throttled, ok := cause.(domain.LoginThrottledError); ok {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		cause = domain.NewError(domain.KindTooManyRequests, "too_many_login_attempts", "Too many login attempts")
+	}
+
+	if throttled, ok := cause.(domain.StepUpThrottledError); ok {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		cause = domain.NewError(domain.KindTooManyRequests, "too_many_mfa_attempts", "Too many two-factor attempts")
+	}
+
+	// The remaining allowance is data for programs, not only a message
+	if exceeded, ok := cause.(domain.TransferLimitExceededError); ok {
+		ctx.AbortWithStatusJSON(errorStatus[domain.KindUnprocessable], util.Response{
+			Data:   exceeded,
+			Code:   domain.TransferLimitExceededCode,
+			Errors: []string{exceeded.Error()},
+		})
+		return
+	}
+
+	if cause == sql.ErrNoRows {
+		cause = domain.ErrNotFound
+	}
+
+	domainErr, ok := cause.(*domain.Error)
+	if !ok {
+		domainErr = domain.ErrInternal
+	}
+
+	messages := domainErr.Details
+	if len(messages) == 0 {
+		messages = []string{domainErr.Message}
+	}
+
+	ctx.AbortWithStatusJSON(errorStatus[domainErr.Kind], util.Response{
+		Code:   domainErr.Code,
+		Errors: messages,
+	})
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serveError(handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.GET("/", handler)
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestErrorHandler(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		body   string
+	}{
+		{"not-found", domain.ErrAccountNotFound, http.StatusNotFound, `{"code":"account_not_found","errors":["Account not exists"]}`},
+		{"conflict", domain.ErrEmailTaken, http.StatusConflict, `{"code":"email_taken","errors":["Email already registered"]}`},
+		{"insufficient-funds", domain.ErrInsufficientBalance, http.StatusBadRequest, `{"code":"insufficient_balance","errors":["Insufficient balance"]}`},
+		{"unauthorized", domain.ErrInvalidCredentials, http.StatusUnauthorized, `{"code":"invalid_credentials","errors":["Invalid email or password"]}`},
+		{"wrapped", errors.Wrap(domain.ErrCustomerNotFound, "lookup"), http.StatusNotFound, `{"code":"customer_not_found","errors":["Customer not found"]}`},
+		{"details", &domain.Error{Kind: domain.KindValidation, Code: "weak_password", Message: "weak", Details: []string{"must contain a digit"}}, http.StatusBadRequest, `{"code":"weak_password","errors":["must contain a digit"]}`},
+		{"no-rows", sql.ErrNoRows, http.StatusNotFound, `{"code":"not_found","errors":["Not found"]}`},
+		{"unknown", errors.New("pq: connection refused"), http.StatusInternalServerError, `{"code":"internal_error","errors":["Something went wrong"]}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := serveError(func(ctx *gin.Context) {
+				ctx.Error(c.err)
+			})
+
+			assert.Equal(t, c.status, rec.Code)
+			assert.Equal(t, c.body, rec.Body.String())
+		})
+	}
+
+	t.Run("Login-throttled", func(t *testing.T) {
+		rec := serveError(func(ctx *gin.Context) {
+			ctx.Error(domain.LoginThrottledError{RetryAfter: 1500 * time.Millisecond})
+		})
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+		assert.Equal(t, `{"code":"too_many_login_attempts","errors":["Too many login attempts"]}`, rec.Body.String())
+	})
+
+	t.Run("Step-up-throttled", func(t *testing.T) {
+		rec := serveError(func(ctx *gin.Context) {
+			ctx.Error(domain.StepUpThrottledError{RetryAfter: 10 * time.Minute})
+		})
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "600", rec.Header().Get("Retry-After"))
+		assert.Equal(t, `{"code":"too_many_mfa_attempts","errors":["Too many two-factor attempts"]}`, rec.Body.String())
+	})
+
+	t.Run("Transfer-limit-exceeded", func(t *testing.T) {
+		remaining := domain.NewMoney(15000, "IDR")
+		rec := serveError(func(ctx *gin.Context) {
+			ctx.Error(errors.Wrap(domain.TransferLimitExceededError{Limit: domain.TransferLimitDaily, Remaining: &remaining}, "transfer"))
+		})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.JSONEq(t, `{"code":"transfer_limit_exceeded","errors":["Transfer limit daily exceeded, 150.00 IDR remaining"],"data":{"limit":"daily","remaining":{"amount":"150.00","currency":"IDR"}}}`, rec.Body.String())
+	})
+
+	t.Run("Response-already-written", func(t *testing.T) {
+		rec := serveError(func(ctx *gin.Context) {
+			ctx.Error(domain.ErrInternal)
+			ctx.Status(http.StatusNoContent)
+			ctx.Writer.WriteHeaderNow()
+		})
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+}
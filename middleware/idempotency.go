@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sirupsen/logrus"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response of a POST request that carries an
+// Idempotency-Key header already seen within expire, instead of running the
+// handler again. Keys are per account, so only requests with a valid access
+// token are remembered, and reusing one for a different request is rejected
+// with 422. While the first request runs, the key is locked for
+// lockExpire only, so a request that never finishes does not hold it for
+// long.
+func Idempotency(repo domain.IdempotencyRepository, authUseCase domain.AuthUseCase, expire, lockExpire time.Duration, logger *logrus.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if ctx.Request.Method != http.MethodPost || key == "" {
+			ctx.Next()
+			return
+		}
+
+		caller, ok := idempotencyCaller(ctx, authUseCase)
+		if !ok {
+			ctx.Next()
+			return
+		}
+		key = caller.Subject + ":" + key
+
+		body, err := ioutil.ReadAll(ctx.Request.Body)
+		if err != nil {
+			renderError(ctx, domain.ErrBadRequest)
+			return
+		}
+		ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		fingerprint := requestFingerprint(ctx, caller, body)
+
+		record, found, err := repo.Get(ctx, key)
+		if err != nil {
+			renderError(ctx, err)
+			return
+		}
+
+		if !found {
+			locked, err := repo.Lock(ctx, key, domain.IdempotencyRecord{Fingerprint: fingerprint}, lockExpire)
+			if err != nil {
+				renderError(ctx, err)
+				return
+			}
+
+			if locked {
+				serveAndRemember(ctx, repo, key, fingerprint, expire, logger)
+				return
+			}
+
+			// Another request took the key between Get and Lock.
+			record, found, err = repo.Get(ctx, key)
+			if err != nil || !found {
+				renderError(ctx, domain.ErrIdempotencyInProgress)
+				return
+			}
+		}
+
+		if record.Fingerprint != fingerprint {
+			renderError(ctx, domain.ErrIdempotencyKeyReused)
+			return
+		}
+
+		if record.Status == 0 {
+			renderError(ctx, domain.ErrIdempotencyInProgress)
+			return
+		}
+
+		ctx.Header("Idempotent-Replayed", "true")
+		ctx.Data(record.Status, record.ContentType, record.Body)
+		ctx.Abort()
+	}
+}
+
+// idempotencyCaller returns the claims of the access token of the request.
+// It is false without a valid token, the route answers those itself.
+func idempotencyCaller(ctx *gin.Context, authUseCase domain.AuthUseCase) (domain.AccessClaims, bool) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return domain.AccessClaims{}, false
+	}
+
+	claims, err := authUseCase.ValidateAccessToken(ctx, token)
+	if err != nil {
+		return domain.AccessClaims{}, false
+	}
+
+	return claims, true
+}
+
+func serveAndRemember(ctx *gin.Context, repo domain.IdempotencyRepository, key, fingerprint string, expire time.Duration, logger *logrus.Logger) {
+	writer := idempotencyWriter{ResponseWriter: ctx.Writer, body: &bytes.Buffer{}}
+	ctx.Writer = writer
+
+	remembered := false
+	defer func() {
+		if remembered {
+			return
+		}
+
+		// Server errors and panics are not final, release the key so the
+		// client can retry. A panic carries on to the recovery middleware.
+		err := repo.Delete(ctx, key)
+		if err != nil {
+			logger.Errorf("%s : %v", "middleware/Idempotency/Delete", err)
+		}
+	}()
+
+	ctx.Next()
+
+	if writer.Status() >= http.StatusInternalServerError {
+		return
+	}
+
+	// A response that could not be saved keeps the lock until it expires,
+	// so a retry does not run the request again right away.
+	remembered = true
+
+	err := repo.Save(ctx, key, domain.IdempotencyRecord{
+		Fingerprint: fingerprint,
+		Status:      writer.Status(),
+		ContentType: writer.Header().Get("Content-Type"),
+		Body:        writer.body.Bytes(),
+	}, expire)
+	if err != nil {
+		logger.Errorf("%s : %v", "middleware/Idempotency/Save", err)
+	}
+}
+
+// requestFingerprint identifies a request by its target, caller and body, so a
+// key replayed against another endpoint does not match. The caller is its
+// account and role rather than its token, which changes on every refresh.
+func requestFingerprint(ctx *gin.Context, caller domain.AccessClaims, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Request.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.Request.URL.Path))
+	hash.Write([]byte{0})
+	hash.Write([]byte(caller.Subject))
+	hash.Write([]byte{0})
+	hash.Write([]byte(caller.Role))
+	hash.Write([]byte{0})
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,296 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	auth_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/auth/usecase/mock"
+	idempotency_repository_mock "github.com/oniharnantyo/golang-backend-example/services/idempotency/repository/mock"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var caller = domain.AccessClaims{
+	StandardClaims: jwt.StandardClaims{Subject: "555001"},
+	Role:           domain.RoleCustomer,
+}
+
+// authTokens lets every token through as caller.
+func authTokens() *auth_usecase_mock.AuthMockUseCase {
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockAuthUseCase.On("ValidateAccessToken", mock.Anything, mock.Anything).Return(caller, nil)
+
+	return mockAuthUseCase
+}
+
+func newIdempotencyRouter(repo domain.IdempotencyRepository, calls *int) *gin.Engine {
+	r := gin.New()
+	r.Use(Idempotency(repo, authTokens(), time.Hour, time.Minute, logrus.New()))
+	r.POST("/transfer", func(ctx *gin.Context) {
+		*calls++
+		ctx.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	return r
+}
+
+func newIdempotencyRequest(key, body string) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, "/transfer", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer token")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	return req
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Run("Without-key", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+		calls := 0
+
+		rec := httptest.NewRecorder()
+		newIdempotencyRouter(repo, &calls).ServeHTTP(rec, newIdempotencyRequest("", `{"amount":100}`))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, 1, calls)
+		repo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Without-token", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+		calls := 0
+
+		req := newIdempotencyRequest("key-1", `{"amount":100}`)
+		req.Header.Del("Authorization")
+
+		rec := httptest.NewRecorder()
+		newIdempotencyRouter(repo, &calls).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, 1, calls)
+		repo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+
+	t.Run("First-request", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+		calls := 0
+
+		repo.On("Get", mock.Anything, "555001:key-1").Return(domain.IdempotencyRecord{}, false, nil).Once()
+		repo.On("Lock", mock.Anything, "555001:key-1", mock.AnythingOfType("domain.IdempotencyRecord"), time.Minute).Return(true, nil).Once()
+		repo.On("Save", mock.Anything, "555001:key-1", mock.MatchedBy(func(record domain.IdempotencyRecord) bool {
+			return record.Status == http.StatusCreated && string(record.Body) == `{"id":"1"}`
+		}), time.Hour).Return(nil).Once()
+
+		rec := httptest.NewRecorder()
+		newIdempotencyRouter(repo, &calls).ServeHTTP(rec, newIdempotencyRequest("key-1", `{"amount":100}`))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, 1, calls)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+		calls := 0
+
+		req := newIdempotencyRequest("key-1", `{"amount":100}`)
+		fingerprint := requestFingerprint(&gin.Context{Request: req}, caller, []byte(`{"amount":100}`))
+
+		repo.On("Get", mock.Anything, "555001:key-1").Return(domain.IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      http.StatusCreated,
+			ContentType: "application/json; charset=utf-8",
+			Body:        []byte(`{"id":"1"}`),
+		}, true, nil).Once()
+
+		rec := httptest.NewRecorder()
+		newIdempotencyRouter(repo, &calls).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `{"id":"1"}`, rec.Body.String())
+		assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, 0, calls)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Replay-after-refresh", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+		calls := 0
+
+		first := newIdempotencyRequest("key-1", `{"amount":100}`)
+		fingerprint := requestFingerprint(&gin.Context{Request: first}, caller, []byte(`{"amount":100}`))
+
+		repo.On("Get", mock.Anything, "555001:key-1").Return(domain.IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      http.StatusCreated,
+			ContentType: "application/json; charset=utf-8",
+			Body:        []byte(`{"id":"1"}`),
+		}, true, nil).Once()
+
+		retry := newIdempotencyRequest("key-1", `{"amount":100}`)
+		retry.Header.Set("Authorization", "Bearer refreshed-token")
+
+		rec := httptest.NewRecorder()
+		newIdempotencyRouter(repo, &calls).ServeHTTP(rec, retry)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("Other-account", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+		calls := 0
+
+		repo.On("Get", mock.Anything, "555002:key-1").Return(domain.IdempotencyRecord{}, false, nil).Once()
+		repo.On("Lock", mock.Anything, "555002:key-1", mock.AnythingOfType("domain.IdempotencyRecord"), time.Minute).Return(true, nil).Once()
+		repo.On("Save", mock.Anything, "555002:key-1", mock.AnythingOfType("domain.IdempotencyRecord"), time.Hour).Return(nil).Once()
+
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{
+			StandardClaims: jwt.StandardClaims{Subject: "555002"},
+			Role:           domain.RoleCustomer,
+		}, nil)
+
+		r := gin.New()
+		r.Use(Idempotency(repo, mockAuthUseCase, time.Hour, time.Minute, logrus.New()))
+		r.POST("/transfer", func(ctx *gin.Context) {
+			calls++
+			ctx.JSON(http.StatusCreated, gin.H{"id": "2"})
+		})
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, newIdempotencyRequest("key-1", `{"amount":100}`))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, 1, calls)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid-token", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+		calls := 0
+
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{}, domain.ErrInvalidToken)
+
+		r := gin.New()
+		r.Use(Idempotency(repo, mockAuthUseCase, time.Hour, time.Minute, logrus.New()))
+		r.POST("/transfer", func(ctx *gin.Context) {
+			calls++
+			ctx.Status(http.StatusUnauthorized)
+		})
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, newIdempotencyRequest("key-1", `{"amount":100}`))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, 1, calls)
+		repo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Different-body", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+		calls := 0
+
+		repo.On("Get", mock.Anything, "555001:key-1").Return(domain.IdempotencyRecord{
+			Fingerprint: "other",
+			Status:      http.StatusCreated,
+		}, true, nil).Once()
+
+		rec := httptest.NewRecorder()
+		newIdempotencyRouter(repo, &calls).ServeHTTP(rec, newIdempotencyRequest("key-1", `{"amount":200}`))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("In-progress", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+		calls := 0
+
+		req := newIdempotencyRequest("key-1", `{"amount":100}`)
+		fingerprint := requestFingerprint(&gin.Context{Request: req}, caller, []byte(`{"amount":100}`))
+
+		repo.On("Get", mock.Anything, "555001:key-1").Return(domain.IdempotencyRecord{Fingerprint: fingerprint}, true, nil).Once()
+
+		rec := httptest.NewRecorder()
+		newIdempotencyRouter(repo, &calls).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("Server-error-releases-key", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+
+		repo.On("Get", mock.Anything, "555001:key-1").Return(domain.IdempotencyRecord{}, false, nil).Once()
+		repo.On("Lock", mock.Anything, "555001:key-1", mock.AnythingOfType("domain.IdempotencyRecord"), time.Minute).Return(true, nil).Once()
+		repo.On("Delete", mock.Anything, "555001:key-1").Return(nil).Once()
+
+		r := gin.New()
+		r.Use(Idempotency(repo, authTokens(), time.Hour, time.Minute, logrus.New()))
+		r.POST("/transfer", func(ctx *gin.Context) {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+		})
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, newIdempotencyRequest("key-1", `{"amount":100}`))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Panic-releases-key", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+
+		repo.On("Get", mock.Anything, "555001:key-1").Return(domain.IdempotencyRecord{}, false, nil).Once()
+		repo.On("Lock", mock.Anything, "555001:key-1", mock.AnythingOfType("domain.IdempotencyRecord"), time.Minute).Return(true, nil).Once()
+		repo.On("Delete", mock.Anything, "555001:key-1").Return(nil).Once()
+
+		r := gin.New()
+		r.Use(gin.Recovery())
+		r.Use(Idempotency(repo, authTokens(), time.Hour, time.Minute, logrus.New()))
+		r.POST("/transfer", func(ctx *gin.Context) {
+			panic("handler failed")
+		})
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, newIdempotencyRequest("key-1", `{"amount":100}`))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Save-failed-keeps-lock", func(t *testing.T) {
+		repo := new(idempotency_repository_mock.IdempotencyMockRepository)
+		calls := 0
+
+		repo.On("Get", mock.Anything, "555001:key-1").Return(domain.IdempotencyRecord{}, false, nil).Once()
+		repo.On("Lock", mock.Anything, "555001:key-1", mock.AnythingOfType("domain.IdempotencyRecord"), time.Minute).Return(true, nil).Once()
+		repo.On("Save", mock.Anything, "555001:key-1", mock.AnythingOfType("domain.IdempotencyRecord"), time.Hour).Return(errors.New("redis: connection refused")).Once()
+
+		rec := httptest.NewRecorder()
+		newIdempotencyRouter(repo, &calls).ServeHTTP(rec, newIdempotencyRequest("key-1", `{"amount":100}`))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, 1, calls)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
@@ -1,54 +1,66 @@
 package middleware
 
 import (
-	"fmt"
-	"net/http"
+	"strings"
 
-	"github.com/oniharnantyo/golang-backend-example/util"
+	"github.com/oniharnantyo/golang-backend-example/domain"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
+const AccessClaimsKey = "access_claims"
+
 type jwtHeader struct {
 	Authorization string `header:"Authorization"`
 }
 
-func JWT() gin.HandlerFunc {
+// JWT only lets requests with a valid, unrevoked Bearer access token through
+// and stores its domain.AccessClaims in the gin context.
+func JWT(authUseCase domain.AuthUseCase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var h jwtHeader
+		token, ok := bearerToken(ctx)
+		if !ok {
+			renderError(ctx, domain.ErrMissingToken)
+			return
+		}
 
-		err := ctx.ShouldBindHeader(&h)
+		claims, err := authUseCase.ValidateAccessToken(ctx, token)
 		if err != nil {
-			errs, ok := err.(validator.ValidationErrors)
-			if !ok {
-				ctx.JSON(http.StatusForbidden, util.Response{
-					Data: nil,
-					Errors: []string{
-						"Something went wrong",
-					},
-				})
-
-				ctx.Abort()
-				return
-			}
-
-			var errsMessage []string
-			for _, err := range errs {
-				errsMessage = append(errsMessage, err.Error())
-			}
-
-			ctx.JSON(http.StatusForbidden, util.Response{
-				Data:   nil,
-				Errors: errsMessage,
-			})
-
-			ctx.Abort()
+			renderError(ctx, domain.ErrInvalidToken)
 			return
 		}
 
-		fmt.Println(h)
+		ctx.Set(AccessClaimsKey, claims)
 
 		ctx.Next()
 	}
 }
+
+// bearerToken returns the token of the Bearer Authorization header.
+func bearerToken(ctx *gin.Context) (string, bool) {
+	var h jwtHeader
+
+	err := ctx.ShouldBindHeader(&h)
+	if err != nil {
+		return "", false
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(h.Authorization, "Bearer "))
+	if !strings.HasPrefix(h.Authorization, "Bearer ") || token == "" {
+		return "", false
+	}
+
+	return token, true
+}
+
+// GetAccessClaims returns the claims stored by JWT.
+func GetAccessClaims(ctx *gin.Context) (domain.AccessClaims, bool) {
+	value, ok := ctx.Get(AccessClaimsKey)
+	if !ok {
+		return domain.AccessClaims{}, false
+	}
+
+	claims, ok := value.(domain.AccessClaims)
+
+	return claims, ok
+}
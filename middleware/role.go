@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets requests through whose access token carries one of
+// roles. It must run after JWT.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, ok := GetAccessClaims(ctx)
+		if !ok || !hasRole(claims.Role, roles) {
+			forbidden(ctx)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequireOwnerOrRole lets the owner of the account named by the param
+// wildcard through, as well as tokens carrying one of roles. It must run
+// after JWT.
+func RequireOwnerOrRole(param string, roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, ok := GetAccessClaims(ctx)
+		if !ok {
+			forbidden(ctx)
+			return
+		}
+
+		if hasRole(claims.Role, roles) {
+			ctx.Next()
+			return
+		}
+
+		accountNumber, err := strconv.Atoi(ctx.Param(param))
+		if err != nil || accountNumber != claims.AccountNumber() {
+			forbidden(ctx)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func hasRole(role string, roles []string) bool {
+	for _, r := range roles {
+		if role == r {
+			return true
+		}
+	}
+
+	return false
+}
+
+func forbidden(ctx *gin.Context) {
+	renderError(ctx, domain.ErrForbidden)
+}
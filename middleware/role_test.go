@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRoleRouter(claims *domain.AccessClaims, handlers ...gin.HandlerFunc) *gin.Engine {
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		if claims != nil {
+			ctx.Set(AccessClaimsKey, *claims)
+		}
+	})
+
+	handlers = append(handlers, func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	r.GET("/account/:account_number", handlers...)
+
+	return r
+}
+
+func serveRole(r *gin.Engine, path string) int {
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+func claimsFor(accountNumber, role string) *domain.AccessClaims {
+	return &domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Subject: accountNumber},
+		Role:           role,
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	t.Run("Allowed-role", func(t *testing.T) {
+		r := newRoleRouter(claimsFor("555001", domain.RoleTeller), RequireRole(domain.RoleTeller, domain.RoleAdmin))
+		assert.Equal(t, http.StatusOK, serveRole(r, "/account/555001"))
+	})
+
+	t.Run("Other-role", func(t *testing.T) {
+		r := newRoleRouter(claimsFor("555001", domain.RoleCustomer), RequireRole(domain.RoleTeller, domain.RoleAdmin))
+		assert.Equal(t, http.StatusForbidden, serveRole(r, "/account/555001"))
+	})
+
+	t.Run("Without-claims", func(t *testing.T) {
+		r := newRoleRouter(nil, RequireRole(domain.RoleAdmin))
+		assert.Equal(t, http.StatusForbidden, serveRole(r, "/account/555001"))
+	})
+}
+
+func TestRequireOwnerOrRole(t *testing.T) {
+	t.Run("Owner", func(t *testing.T) {
+		r := newRoleRouter(claimsFor("555001", domain.RoleCustomer), RequireOwnerOrRole("account_number", domain.RoleAdmin))
+		assert.Equal(t, http.StatusOK, serveRole(r, "/account/555001"))
+	})
+
+	t.Run("Other-account", func(t *testing.T) {
+		r := newRoleRouter(claimsFor("555002", domain.RoleCustomer), RequireOwnerOrRole("account_number", domain.RoleAdmin))
+		assert.Equal(t, http.StatusForbidden, serveRole(r, "/account/555001"))
+	})
+
+	t.Run("Allowed-role", func(t *testing.T) {
+		r := newRoleRouter(claimsFor("555002", domain.RoleAdmin), RequireOwnerOrRole("account_number", domain.RoleAdmin))
+		assert.Equal(t, http.StatusOK, serveRole(r, "/account/555001"))
+	})
+
+	t.Run("Without-claims", func(t *testing.T) {
+		r := newRoleRouter(nil, RequireOwnerOrRole("account_number", domain.RoleAdmin))
+		assert.Equal(t, http.StatusForbidden, serveRole(r, "/account/555001"))
+	})
+}
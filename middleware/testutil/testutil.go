@@ -0,0 +1,15 @@
+// Package testutil holds helpers for the handler tests.
+package testutil
+
+import (
+	"github.com/oniharnantyo/golang-backend-example/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewRouter returns an engine that renders handler errors like the app does.
+func NewRouter() *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	return r
+}
@@ -0,0 +1,28 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logNotifier writes messages to the log instead of delivering them. It is
+// meant for development, the messages may carry secrets like reset tokens.
+type logNotifier struct {
+	logger *logrus.Logger
+}
+
+func (n logNotifier) Notify(ctx context.Context, to string, subject string, body string) error {
+	n.logger.WithFields(logrus.Fields{
+		"to":      to,
+		"subject": subject,
+	}).Info(body)
+
+	return nil
+}
+
+func NewLogNotifier(logger *logrus.Logger) domain.Notifier {
+	return &logNotifier{logger: logger}
+}
@@ -0,0 +1,17 @@
+package notifier_mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type NotifierMock struct {
+	mock.Mock
+}
+
+func (n *NotifierMock) Notify(ctx context.Context, to string, subject string, body string) error {
+	args := n.Called(ctx, to, subject, body)
+
+	return args.Error(0)
+}
@@ -1,15 +1,11 @@
 package delivery_http_account
 
 import (
-	"database/sql"
-	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
-
-	"github.com/oniharnantyo/golang-backend-example/util"
 
 	"github.com/oniharnantyo/golang-backend-example/middleware"
+	"github.com/oniharnantyo/golang-backend-example/util"
 
 	"github.com/oniharnantyo/golang-backend-example/domain"
 
@@ -20,21 +16,45 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Cursor kinds keep a cursor of one listing from being used on another.
+const (
+	accountCursorKind  = "account"
+	transferCursorKind = "transfer"
+)
+
 type AccountHandler struct {
 	accountUseCase domain.AccountUseCase
+	cursorSigner   util.CursorSigner
 	logger         *logrus.Logger
 }
 
-func NewAccountHandler(r *gin.Engine, ctx domain.AccountUseCase, l *logrus.Logger) *gin.Engine {
-	handler := &AccountHandler{accountUseCase: ctx, logger: l}
+func NewAccountHandler(r *gin.Engine, ctx domain.AccountUseCase, au domain.AuthUseCase, cs util.CursorSigner, l *logrus.Logger) *gin.Engine {
+	handler := &AccountHandler{accountUseCase: ctx, cursorSigner: cs, logger: l}
+
+	auth := middleware.JWT(au)
+	staff := middleware.RequireRole(domain.RoleTeller, domain.RoleAdmin)
+	admin := middleware.RequireRole(domain.RoleAdmin)
+	ownerOrStaff := middleware.RequireOwnerOrRole("account_number", domain.RoleTeller, domain.RoleAdmin)
 
-	r.GET("/account", handler.HandlerGetAccountList)
-	r.GET("/account/:account_number", handler.HandlerGetAccountByAccountNumber)
-	r.POST("/account", handler.HandlerAccountStore)
-	r.PUT("/account", handler.HandlerAccountUpdate)
-	r.DELETE("/account", handler.HandlerAccountDelete)
+	r.GET("/account", auth, staff, handler.HandlerGetAccountList)
+	r.GET("/account/:account_number", auth, ownerOrStaff, handler.HandlerGetAccountByAccountNumber)
+	r.POST("/account", auth, staff, handler.HandlerAccountStore)
+	r.PUT("/account", auth, admin, handler.HandlerAccountUpdate)
+	r.DELETE("/account", auth, admin, handler.HandlerAccountDelete)
 	r.POST("/account/login", handler.HandlerLogin)
-	r.POST("/account/:from_account_number/transfer", middleware.JWT(), handler.HandlerAccountTransfer)
+	r.POST("/account/login/mfa", handler.HandlerLoginMFA)
+	r.POST("/account/password", auth, handler.HandlerAccountChangePassword)
+	r.POST("/account/password/reset", handler.HandlerAccountRequestPasswordReset)
+	r.POST("/account/password/reset/confirm", handler.HandlerAccountResetPassword)
+	// POST routes below /account share the :account_number wildcard, gin
+	// does not allow differently named wildcards on the same segment.
+	r.POST("/account/:account_number/transfer", auth, handler.HandlerAccountTransfer)
+	r.POST("/account/:account_number/deposit", auth, staff, handler.HandlerAccountDeposit)
+	r.POST("/account/:account_number/withdraw", auth, staff, handler.HandlerAccountWithdraw)
+	r.GET("/account/:account_number/transfers", auth, ownerOrStaff, handler.HandlerGetAccountTransfers)
+	r.GET("/account/:account_number/ledger", auth, ownerOrStaff, handler.HandlerGetAccountLedger)
+	r.POST("/account/:account_number/ledger/rebuild", auth, admin, handler.HandlerAccountRebuildBalance)
+	r.POST("/account/:account_number/unlock", auth, admin, handler.HandlerAccountUnlock)
 
 	return r
 }
@@ -44,20 +64,75 @@ func (a *AccountHandler) HandlerGetAccountList(ctx *gin.Context) {
 	err := ctx.ShouldBind(&filter)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountList/ShouldBindQuery", err)
-		ctx.String(http.StatusBadRequest, "Bad request")
+		ctx.Error(domain.ErrBadRequest)
 		return
 	}
 
-	fmt.Println(filter)
+	if filter.BalanceGte != nil && filter.BalanceLte != nil && *filter.BalanceLte < *filter.BalanceGte {
+		ctx.Error(domain.ErrInvalidBalanceRange)
+		return
+	}
+
+	filter.Paginate()
 
-	accounts, err := a.accountUseCase.List(ctx, filter)
+	sort, err := domain.AccountSort.Parse(filter.Sort, filter.Order)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountList/ParseSort", err)
+		ctx.Error(domain.ErrInvalidSort)
+		return
+	}
+
+	if filter.Cursor != "" {
+		var after domain.AccountCursor
+		err = a.cursorSigner.Decode(accountCursorKind, filter.Cursor, &after)
+		if err == nil && after.Sort != util.FormatSort(sort) {
+			err = util.ErrInvalidCursor
+		}
+		if err != nil {
+			a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountList/DecodeCursor", err)
+			ctx.Error(domain.ErrInvalidCursor)
+			return
+		}
+		filter.After = &after
+	}
+
+	// One account more than the page tells whether a next page exists.
+	page := filter
+	page.Limit++
+
+	accounts, total, err := a.accountUseCase.List(ctx, page)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountList/List", err)
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	var nextCursor string
+	if len(accounts) > filter.Limit {
+		accounts = accounts[:filter.Limit]
+		last := accounts[len(accounts)-1]
+		nextCursor, err = a.cursorSigner.Encode(accountCursorKind, domain.AccountCursor{
+			Sort:           util.FormatSort(sort),
+			AccountNumber:  last.AccountNumber,
+			CustomerNumber: last.CustomerNumber,
+			Balance:        last.Balance.Amount,
+			Email:          last.Email,
+		})
+		if err != nil {
+			a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountList/EncodeCursor", err)
+			ctx.Error(err)
+			return
+		}
+	}
+
+	if accounts == nil {
+		accounts = []domain.Account{}
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{
+		Data: accounts,
+		Meta: util.NewMeta(ctx.Request.URL, filter.Filter, total, nextCursor),
+	})
 	return
 }
 
@@ -65,59 +140,62 @@ func (a *AccountHandler) HandlerGetAccountByAccountNumber(ctx *gin.Context) {
 	customerNumber, err := strconv.Atoi(ctx.Param("account_number"))
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountByAccountNumber/parseAccountNumber", err)
-		ctx.AbortWithError(http.StatusBadRequest, errors.New("Account not exists"))
+		ctx.Error(domain.ErrBadRequest)
 		return
 	}
 
 	account, err := a.accountUseCase.GetByAccountNumber(ctx, customerNumber)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountByAccountNumber/GetByAccountNumber", err)
-		if errors.Cause(err) == sql.ErrNoRows {
-			ctx.AbortWithError(http.StatusNotFound, errors.New("Account not exists"))
-			return
-		}
-		ctx.AbortWithStatus(http.StatusInternalServerError)
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, util.Response{Data: account})
 	return
 }
 
 func (a *AccountHandler) HandlerAccountStore(ctx *gin.Context) {
-	var param domain.Account
-	err := ctx.Bind(&param)
+	var param domain.AccountRegisterParam
+	err := ctx.ShouldBindJSON(&param)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountStore/ParseBodyData", err)
-		ctx.AbortWithError(http.StatusBadRequest, err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	// Tellers open customer accounts, only admins hand out staff roles.
+	claims, _ := middleware.GetAccessClaims(ctx)
+	if param.Role != "" && param.Role != domain.RoleCustomer && claims.Role != domain.RoleAdmin {
+		ctx.Error(domain.ErrForbidden)
 		return
 	}
 
-	err = a.accountUseCase.Store(ctx, &param)
+	account, err := a.accountUseCase.Register(ctx, param)
 	if err != nil {
-		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountStore/Store", err)
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountStore/Register", err)
+		ctx.Error(err)
 		return
 	}
 
-	ctx.Status(http.StatusCreated)
+	ctx.JSON(http.StatusCreated, util.Response{Data: account})
 	return
 }
 
 func (a *AccountHandler) HandlerAccountUpdate(ctx *gin.Context) {
 	var param domain.Account
 
-	err := ctx.Bind(&param)
+	err := ctx.ShouldBind(&param)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountUpdate/ParseBodyData", err)
-		ctx.AbortWithError(http.StatusBadRequest, err)
+		ctx.Error(domain.ErrBadRequest)
 		return
 	}
 
 	err = a.accountUseCase.Update(ctx, &param)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountUpdate/Store", err)
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		ctx.Error(err)
 		return
 	}
 
@@ -128,17 +206,17 @@ func (a *AccountHandler) HandlerAccountUpdate(ctx *gin.Context) {
 func (a *AccountHandler) HandlerAccountDelete(ctx *gin.Context) {
 	var param domain.Account
 
-	err := ctx.Bind(&param)
+	err := ctx.ShouldBind(&param)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountDelete/ParseBodyData", err)
-		ctx.AbortWithError(http.StatusBadRequest, err)
+		ctx.Error(domain.ErrBadRequest)
 		return
 	}
 
 	err = a.accountUseCase.Delete(ctx, &param)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountDelete/Store", err)
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		ctx.Error(err)
 		return
 	}
 
@@ -147,55 +225,355 @@ func (a *AccountHandler) HandlerAccountDelete(ctx *gin.Context) {
 }
 
 func (a *AccountHandler) HandlerAccountTransfer(ctx *gin.Context) {
-	fromAccountNumber, err := strconv.Atoi(ctx.Param("from_account_number"))
+	fromAccountNumber, err := strconv.Atoi(ctx.Param("account_number"))
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountTransfer/parseFromAccountNumber", err)
-		ctx.AbortWithError(http.StatusBadRequest, err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	// Only the owner of the sender account may move money out of it
+	claims, ok := middleware.GetAccessClaims(ctx)
+	if !ok || claims.AccountNumber() != fromAccountNumber {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountTransfer/checkOwner", errors.New("caller does not own sender account"))
+		ctx.Error(domain.ErrForbidden)
 		return
 	}
 
 	var param domain.TransferParam
-	err = ctx.Bind(&param)
+	err = ctx.ShouldBind(&param)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountTransfer/ParseBodyData", err)
-		ctx.AbortWithError(http.StatusBadRequest, err)
+		ctx.Error(domain.ErrBadRequest)
 		return
 	}
 
-	err = a.accountUseCase.Transfer(ctx, fromAccountNumber, param)
+	transfer, err := a.accountUseCase.Transfer(ctx, fromAccountNumber, param)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountTransfer/Transfer", err)
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		ctx.Error(err)
 		return
 	}
 
-	ctx.Status(http.StatusNoContent)
+	ctx.JSON(http.StatusCreated, util.Response{Data: transfer})
+}
+
+func (a *AccountHandler) HandlerAccountDeposit(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountDeposit/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	var param domain.MovementParam
+	err = ctx.ShouldBindJSON(&param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountDeposit/ParseBodyData", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	deposit, err := a.accountUseCase.Deposit(ctx, accountNumber, param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountDeposit/Deposit", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, util.Response{Data: deposit})
+}
+
+func (a *AccountHandler) HandlerAccountWithdraw(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountWithdraw/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	var param domain.MovementParam
+	err = ctx.ShouldBindJSON(&param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountWithdraw/ParseBodyData", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	withdrawal, err := a.accountUseCase.Withdraw(ctx, accountNumber, param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountWithdraw/Withdraw", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, util.Response{Data: withdrawal})
+}
+
+func (a *AccountHandler) HandlerGetAccountTransfers(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountTransfers/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	var param domain.TransferListParam
+	err = ctx.ShouldBindQuery(&param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountTransfers/ShouldBindQuery", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	if !param.StartDate.IsZero() && !param.EndDate.IsZero() && param.EndDate.Before(param.StartDate) {
+		ctx.Error(domain.ErrInvalidDateRange)
+		return
+	}
+
+	param.Paginate()
+
+	sort, err := domain.TransferSort.Parse(param.Sort, param.Order)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountTransfers/ParseSort", err)
+		ctx.Error(domain.ErrInvalidSort)
+		return
+	}
+
+	if param.Cursor != "" {
+		var after domain.TransferCursor
+		err = a.cursorSigner.Decode(transferCursorKind, param.Cursor, &after)
+		if err == nil && after.Sort != util.FormatSort(sort) {
+			err = util.ErrInvalidCursor
+		}
+		if err != nil {
+			a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountTransfers/DecodeCursor", err)
+			ctx.Error(domain.ErrInvalidCursor)
+			return
+		}
+		param.After = &after
+	}
+
+	// One transfer more than the page tells whether a next page exists.
+	page := param
+	page.Limit++
+
+	transfers, total, err := a.accountUseCase.ListTransfers(ctx, accountNumber, page)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountTransfers/ListTransfers", err)
+		ctx.Error(err)
+		return
+	}
+
+	var nextCursor string
+	if len(transfers) > param.Limit {
+		transfers = transfers[:param.Limit]
+		last := transfers[len(transfers)-1]
+		nextCursor, err = a.cursorSigner.Encode(transferCursorKind, domain.TransferCursor{
+			Sort:      util.FormatSort(sort),
+			CreatedAt: last.CreatedAt,
+			Amount:    last.Amount.Amount,
+			ID:        last.ID,
+		})
+		if err != nil {
+			a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountTransfers/EncodeCursor", err)
+			ctx.Error(err)
+			return
+		}
+	}
+
+	if transfers == nil {
+		transfers = []domain.Transfer{}
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{
+		Data: transfers,
+		Meta: util.NewMeta(ctx.Request.URL, param.Filter, total, nextCursor),
+	})
+}
+
+func (a *AccountHandler) HandlerGetAccountLedger(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountLedger/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	var param domain.LedgerEntryListParam
+	err = ctx.ShouldBindQuery(&param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountLedger/ShouldBindQuery", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	// The ledger is only paged by offset, newest entry first.
+	switch {
+	case param.Cursor != "":
+		ctx.Error(domain.ErrInvalidCursor)
+		return
+	case param.Sort != "" || param.Order != "":
+		ctx.Error(domain.ErrInvalidSort)
+		return
+	case param.Search != "":
+		ctx.Error(domain.ErrInvalidSearch)
+		return
+	}
+
+	param.Paginate()
+
+	entries, total, err := a.accountUseCase.ListLedgerEntries(ctx, accountNumber, param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerGetAccountLedger/ListLedgerEntries", err)
+		ctx.Error(err)
+		return
+	}
+
+	if entries == nil {
+		entries = []domain.LedgerEntry{}
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{
+		Data: entries,
+		Meta: util.NewMeta(ctx.Request.URL, param.Filter, total, ""),
+	})
+}
+
+func (a *AccountHandler) HandlerAccountRebuildBalance(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountRebuildBalance/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	response, err := a.accountUseCase.RebuildBalance(ctx, accountNumber)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountRebuildBalance/RebuildBalance", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: response})
 }
 
 func (a *AccountHandler) HandlerLogin(ctx *gin.Context) {
 	var param domain.AccountLoginParam
-	err := ctx.Bind(&param)
+	err := ctx.ShouldBind(&param)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerLogin/Bind", err)
-		ctx.AbortWithError(http.StatusBadRequest, err)
+		ctx.Error(domain.ErrBadRequest)
 		return
 	}
+	param.UserAgent = ctx.Request.UserAgent()
+	param.IP = middleware.GetClientIP(ctx)
 
 	response, err := a.accountUseCase.Login(ctx, param)
 	if err != nil {
 		a.logger.Errorf("%s : %v", "AccountHandler/HandlerLogin/Login", err)
-		if strings.Contains(err.Error(), "sql: no rows in result set") {
-			ctx.JSON(http.StatusBadRequest, util.Response{
-				Errors: []string{"Email not found"},
-			})
-		} else if strings.Contains(err.Error(), "hashedPassword is not the hash of the given password") {
-			ctx.JSON(http.StatusUnauthorized, util.Response{
-				Errors: []string{"Invalid Password"},
-			})
-		}
-		ctx.Abort()
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	ctx.JSON(http.StatusOK, util.Response{Data: response})
+}
+
+func (a *AccountHandler) HandlerLoginMFA(ctx *gin.Context) {
+	var param domain.LoginMFAParam
+	err := ctx.ShouldBindJSON(&param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerLoginMFA/Bind", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+	param.UserAgent = ctx.Request.UserAgent()
+	param.IP = middleware.GetClientIP(ctx)
+
+	response, err := a.accountUseCase.LoginMFA(ctx, param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerLoginMFA/LoginMFA", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: response})
+}
+
+func (a *AccountHandler) HandlerAccountChangePassword(ctx *gin.Context) {
+	var param domain.PasswordChangeParam
+	err := ctx.ShouldBindJSON(&param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountChangePassword/ParseBodyData", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	claims, _ := middleware.GetAccessClaims(ctx)
+	err = a.accountUseCase.ChangePassword(ctx, claims.AccountNumber(), param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountChangePassword/ChangePassword", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (a *AccountHandler) HandlerAccountRequestPasswordReset(ctx *gin.Context) {
+	var param domain.PasswordResetRequestParam
+	err := ctx.ShouldBindJSON(&param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountRequestPasswordReset/ParseBodyData", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	err = a.accountUseCase.RequestPasswordReset(ctx, param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountRequestPasswordReset/RequestPasswordReset", err)
+		ctx.Error(err)
+		return
+	}
+
+	// Accepted for unknown emails as well, the answer does not tell whether
+	// the email is registered.
+	ctx.Status(http.StatusAccepted)
+}
+
+func (a *AccountHandler) HandlerAccountResetPassword(ctx *gin.Context) {
+	var param domain.PasswordResetParam
+	err := ctx.ShouldBindJSON(&param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountResetPassword/ParseBodyData", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	err = a.accountUseCase.ResetPassword(ctx, param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountResetPassword/ResetPassword", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (a *AccountHandler) HandlerAccountUnlock(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountUnlock/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	err = a.accountUseCase.Unlock(ctx, accountNumber)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AccountHandler/HandlerAccountUnlock/Unlock", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
 }
@@ -8,11 +8,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware"
+	"github.com/oniharnantyo/golang-backend-example/middleware/testutil"
 	account_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/account/usecase/mock"
+	auth_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/auth/usecase/mock"
+	"github.com/oniharnantyo/golang-backend-example/util"
 
-	"github.com/gin-gonic/gin"
+	"github.com/dgrijalva/jwt-go"
 
 	"github.com/sirupsen/logrus"
 
@@ -21,8 +26,20 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/bxcodec/faker"
+
+	"github.com/pkg/errors"
 )
 
+func authAs(accountNumber string, role string) *auth_usecase_mock.AuthMockUseCase {
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Subject: accountNumber},
+		Role:           role,
+	}, nil)
+
+	return mockAuthUseCase
+}
+
 func TestAccountHandler_HandlerGetAccountList(t *testing.T) {
 	var mockAccount domain.Account
 	logger := logrus.New()
@@ -35,19 +52,177 @@ func TestAccountHandler_HandlerGetAccountList(t *testing.T) {
 	mockAccounts := make([]domain.Account, 0)
 	mockAccounts = append(mockAccounts, mockAccount)
 
-	mockAccountUseCase.On("List", mock.Anything, mock.AnythingOfType("domain.AccountListParam")).Return(mockAccounts, nil).Once()
+	t.Run("Success", func(t *testing.T) {
+		mockAccountUseCase.On("List", mock.Anything, domain.AccountListParam{
+			Filter: util.Filter{Limit: 2, Offset: 1, Order: "asc"},
+		}).Return(mockAccounts, 3, nil).Once()
 
-	r := gin.Default()
-	r = NewAccountHandler(r, mockAccountUseCase, logger)
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
-	req, err := http.NewRequest(http.MethodGet, "/account?limit=10&offset=0&search=&order=asc", nil)
-	assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodGet, "/account?limit=1&offset=1&search=&order=asc", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
 
-	rec := httptest.NewRecorder()
+		rec := httptest.NewRecorder()
 
-	r.ServeHTTP(rec, req)
-	assert.Equal(t, 200, rec.Code)
-	mockAccountUseCase.AssertExpectations(t)
+		r.ServeHTTP(rec, req)
+
+		var response struct {
+			Data []domain.Account `json:"data"`
+			Meta util.Meta        `json:"meta"`
+		}
+		err = json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 200, rec.Code)
+		assert.Len(t, response.Data, 1)
+		assert.Equal(t, util.Meta{
+			Total:  3,
+			Limit:  1,
+			Offset: 1,
+			Next:   "/account?limit=1&offset=2&order=asc&search=",
+			Prev:   "/account?limit=1&offset=0&order=asc&search=",
+		}, response.Meta)
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Default-limit", func(t *testing.T) {
+		mockAccountUseCase.On("List", mock.Anything, domain.AccountListParam{
+			Filter: util.Filter{Limit: util.DefaultLimit + 1},
+		}).Return([]domain.Account(nil), 0, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/account", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, 200, rec.Code)
+		assert.Equal(t, `{"data":[],"meta":{"total":0,"limit":20,"offset":0}}`, rec.Body.String())
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		after := domain.AccountCursor{Sort: "-balance,-account_number", AccountNumber: 555001, Balance: 10000}
+		cursor, err := cursorSigner.Encode(accountCursorKind, after)
+		assert.NoError(t, err)
+
+		mockAccountUseCase.On("List", mock.Anything, domain.AccountListParam{
+			Filter: util.Filter{Limit: 2, Cursor: cursor, Sort: "-balance"},
+			After:  &after,
+		}).Return([]domain.Account{
+			{AccountNumber: 555002, CustomerNumber: 1002, Balance: domain.NewMoney(9000, "IDR"), Email: "mail@email.com"},
+			{AccountNumber: 555003},
+		}, 3, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/account?limit=1&sort=-balance&cursor="+cursor, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		var response struct {
+			Data []domain.Account `json:"data"`
+			Meta util.Meta        `json:"meta"`
+		}
+		err = json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 200, rec.Code)
+		assert.Len(t, response.Data, 1)
+
+		var next domain.AccountCursor
+		assert.NoError(t, cursorSigner.Decode(accountCursorKind, response.Meta.NextCursor, &next))
+		assert.Equal(t, domain.AccountCursor{
+			Sort:           "-balance,-account_number",
+			AccountNumber:  555002,
+			CustomerNumber: 1002,
+			Balance:        9000,
+			Email:          "mail@email.com",
+		}, next)
+		assert.Equal(t, "/account?cursor="+response.Meta.NextCursor+"&limit=1&sort=-balance", response.Meta.Next)
+		assert.Empty(t, response.Meta.Prev)
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		balanceGte, balanceLte := int64(0), int64(5000)
+
+		mockAccountUseCase.On("List", mock.Anything, domain.AccountListParam{
+			Filter:         util.Filter{Limit: util.DefaultLimit + 1, Sort: "-balance,account_number"},
+			CustomerNumber: 1001,
+			BalanceGte:     &balanceGte,
+			BalanceLte:     &balanceLte,
+			EmailDomain:    "email.com",
+		}).Return([]domain.Account(nil), 0, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/account?customer_number=1001&balance_gte=0&balance_lte=5000&email_domain=email.com&sort=-balance,account_number", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	cases := []struct {
+		name  string
+		query func() string
+		body  string
+	}{
+		{"Invalid-cursor", func() string {
+			cursor, _ := cursorSigner.Encode(transferCursorKind, domain.AccountCursor{Sort: "account_number", AccountNumber: 555001})
+			return "cursor=" + cursor
+		}, `{"code":"invalid_cursor","errors":["Invalid cursor"]}`},
+		{"Cursor-of-other-sort", func() string {
+			cursor, _ := cursorSigner.Encode(accountCursorKind, domain.AccountCursor{Sort: "account_number", AccountNumber: 555001})
+			return "sort=-balance&cursor=" + cursor
+		}, `{"code":"invalid_cursor","errors":["Invalid cursor"]}`},
+		{"Invalid-sort", func() string {
+			return "sort=password"
+		}, `{"code":"invalid_sort","errors":["Invalid sort"]}`},
+		{"Balance-range-reversed", func() string {
+			return "balance_gte=500&balance_lte=100"
+		}, `{"code":"invalid_balance_range","errors":["balance_lte must not be below balance_gte"]}`},
+		{"Invalid-email-domain", func() string {
+			return "email_domain=not%20a%20domain"
+		}, `{"code":"bad_request","errors":["Bad request"]}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := testutil.NewRouter()
+			r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+			req, err := http.NewRequest(http.MethodGet, "/account?"+c.query(), nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, c.body, rec.Body.String())
+		})
+	}
 }
 
 func TestAccountHandler_HandlerGetAccountByAccountNumber(t *testing.T) {
@@ -62,11 +237,12 @@ func TestAccountHandler_HandlerGetAccountByAccountNumber(t *testing.T) {
 
 		mockAccountUseCase.On("GetByAccountNumber", mock.Anything, mock.AnythingOfType("int")).Return(domain.DetailByAccountNumberResponse{}, nil).Once()
 
-		r := gin.Default()
-		r = NewAccountHandler(r, mockAccountUseCase, logger)
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 		req, err := http.NewRequest(http.MethodGet, "/account/1001", nil)
 		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
 
 		rec := httptest.NewRecorder()
 
@@ -86,11 +262,12 @@ func TestAccountHandler_HandlerGetAccountByAccountNumber(t *testing.T) {
 
 		mockAccountUseCase.On("GetByAccountNumber", mock.Anything, mock.AnythingOfType("int")).Return(domain.DetailByAccountNumberResponse{}, sql.ErrNoRows).Once()
 
-		r := gin.Default()
-		r = NewAccountHandler(r, mockAccountUseCase, logger)
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 		req, err := http.NewRequest(http.MethodGet, "/account/1", nil)
 		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
 
 		rec := httptest.NewRecorder()
 
@@ -102,30 +279,75 @@ func TestAccountHandler_HandlerGetAccountByAccountNumber(t *testing.T) {
 }
 
 func TestAccountHandler_HandlerAccountStore(t *testing.T) {
-	var mockAccount domain.Account
 	logger := logrus.New()
 
-	err := faker.FakeData(&mockAccount)
+	param := domain.AccountRegisterParam{
+		AccountNumber:  555002,
+		CustomerNumber: 1001,
+		Balance:        domain.NewMoney(10000, "IDR"),
+		Email:          "mail@email.com",
+		Password:       "Str0ngPassword",
+	}
+
+	reqBody, err := json.Marshal(param)
 	assert.NoError(t, err)
 
-	mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		body   string
+	}{
+		{"success", nil, http.StatusCreated, `{"data":{"account_number":555002,"customer_number":1001,"balance":{"amount":"100.00","currency":"IDR"},"email":"mail@email.com","role":"customer"}}`},
+		{"weak-password", &domain.Error{Kind: domain.KindValidation, Code: "weak_password", Details: []string{"must contain a digit"}}, http.StatusBadRequest, `{"code":"weak_password","errors":["must contain a digit"]}`},
+		{"invalid-email", domain.ErrInvalidEmail, http.StatusBadRequest, `{"code":"invalid_email","errors":["Invalid email"]}`},
+		{"email-taken", domain.ErrEmailTaken, http.StatusConflict, `{"code":"email_taken","errors":["Email already registered"]}`},
+	}
 
-	mockAccountUseCase.On("Store", mock.Anything, mock.AnythingOfType("*domain.Account")).Return(nil).Once()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+			mockAccountUseCase.On("Register", mock.Anything, param).Return(domain.Account{
+				AccountNumber:  param.AccountNumber,
+				CustomerNumber: param.CustomerNumber,
+				Balance:        param.Balance,
+				Email:          param.Email,
+				Password:       "hash",
+				Role:           domain.RoleCustomer,
+			}, c.err).Once()
+
+			r := testutil.NewRouter()
+			r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+			req, err := http.NewRequest(http.MethodPost, "/account", bytes.NewBuffer(reqBody))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+			assert.Equal(t, c.status, rec.Code)
+			assert.Equal(t, c.body, rec.Body.String())
+			mockAccountUseCase.AssertExpectations(t)
+		})
+	}
 
-	r := gin.Default()
-	r = NewAccountHandler(r, mockAccountUseCase, logger)
+	t.Run("missing-password", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
 
-	reqBody, err := json.Marshal(mockAccount)
-	assert.NoError(t, err)
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
-	req, err := http.NewRequest(http.MethodPost, "/account", bytes.NewBuffer(reqBody))
-	assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, "/account", bytes.NewBufferString(`{"account_number":555002,"customer_number":1001,"email":"mail@email.com"}`))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
 
-	rec := httptest.NewRecorder()
+		rec := httptest.NewRecorder()
 
-	r.ServeHTTP(rec, req)
-	assert.Equal(t, http.StatusCreated, rec.Code)
-	mockAccountUseCase.AssertExpectations(t)
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockAccountUseCase.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+	})
 }
 
 func TestAccountHandler_HandlerAccountUpdate(t *testing.T) {
@@ -139,14 +361,15 @@ func TestAccountHandler_HandlerAccountUpdate(t *testing.T) {
 
 	mockAccountUseCase.On("Update", mock.Anything, mock.AnythingOfType("*domain.Account")).Return(nil).Once()
 
-	r := gin.Default()
-	r = NewAccountHandler(r, mockAccountUseCase, logger)
+	r := testutil.NewRouter()
+	r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 	reqBody, err := json.Marshal(mockAccount)
 	assert.NoError(t, err)
 
 	req, err := http.NewRequest(http.MethodPut, "/account", bytes.NewBuffer(reqBody))
 	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
 
 	rec := httptest.NewRecorder()
 
@@ -166,14 +389,15 @@ func TestAccountHandler_HandlerAccountDelete(t *testing.T) {
 
 	mockAccountUseCase.On("Delete", mock.Anything, mock.AnythingOfType("*domain.Account")).Return(nil).Once()
 
-	r := gin.Default()
-	r = NewAccountHandler(r, mockAccountUseCase, logger)
+	r := testutil.NewRouter()
+	r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 	reqBody, err := json.Marshal(mockAccount)
 	assert.NoError(t, err)
 
 	req, err := http.NewRequest(http.MethodDelete, "/account", bytes.NewBuffer(reqBody))
 	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
 
 	rec := httptest.NewRecorder()
 
@@ -182,24 +406,498 @@ func TestAccountHandler_HandlerAccountDelete(t *testing.T) {
 	mockAccountUseCase.AssertExpectations(t)
 }
 
+func TestAccountHandler_HandlerAccountTransfer(t *testing.T) {
+	logger := logrus.New()
+
+	transfer := domain.Transfer{
+		ID:                "6f1c1a4e-3f0a-4d4b-8f59-1f1d2b7c8a90",
+		FromAccountNumber: 555001,
+		ToAccountNumber:   555002,
+		Amount:            domain.NewMoney(100, "IDR"),
+		Status:            domain.TransferStatusCompleted,
+		Description:       "rent",
+	}
+
+	newRequest := func(fromAccountNumber string) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, "/account/"+fromAccountNumber+"/transfer",
+			bytes.NewBufferString(`{"to_account_number":"555002","amount":{"amount":"1.00","currency":"idr"},"description":"rent"}`))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer token")
+
+		return req
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+
+		mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{
+			StandardClaims: jwt.StandardClaims{Subject: "555001"},
+		}, nil).Once()
+		mockAccountUseCase.On("Transfer", mock.Anything, 555001, domain.TransferParam{
+			ToAccountNumber: "555002",
+			Amount:          domain.NewMoney(100, "IDR"),
+			Description:     "rent",
+		}).Return(transfer, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, mockAuthUseCase, cursorSigner, logger)
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, newRequest("555001"))
+
+		var response struct {
+			Data domain.Transfer `json:"data"`
+		}
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, transfer.ID, response.Data.ID)
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Step-up-required", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAccountUseCase.On("Transfer", mock.Anything, 555001, mock.AnythingOfType("domain.TransferParam")).Return(domain.Transfer{}, domain.ErrMFARequired).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleCustomer), cursorSigner, logger)
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, newRequest("555001"))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, `{"code":"mfa_required","errors":["Two-factor code required"]}`, rec.Body.String())
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Insufficient-balance", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAccountUseCase.On("Transfer", mock.Anything, 555001, mock.AnythingOfType("domain.TransferParam")).Return(domain.Transfer{}, domain.ErrInsufficientBalance).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleCustomer), cursorSigner, logger)
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, newRequest("555001"))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"code":"insufficient_balance","errors":["Insufficient balance"]}`, rec.Body.String())
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid-receiver-account-number", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAccountUseCase.On("Transfer", mock.Anything, 555001, mock.MatchedBy(func(param domain.TransferParam) bool {
+			return param.ToAccountNumber == "55500x"
+		})).Return(domain.Transfer{}, domain.ErrBadRequest).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleCustomer), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/account/555001/transfer",
+			bytes.NewBufferString(`{"to_account_number":"55500x","amount":{"amount":"1.00","currency":"idr"}}`))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `{"code":"bad_request","errors":["Bad request"]}`, rec.Body.String())
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Not-account-owner", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+
+		mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{
+			StandardClaims: jwt.StandardClaims{Subject: "555002"},
+		}, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, mockAuthUseCase, cursorSigner, logger)
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, newRequest("555001"))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockAccountUseCase.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid-token", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+
+		mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{}, errors.New("Invalid token")).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, mockAuthUseCase, cursorSigner, logger)
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, newRequest("555001"))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockAccountUseCase.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Missing-token", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, mockAuthUseCase, cursorSigner, logger)
+
+		req := newRequest("555001")
+		req.Header.Del("Authorization")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockAuthUseCase.AssertNotCalled(t, "ValidateAccessToken", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAccountHandler_HandlerAccountDeposit(t *testing.T) {
+	logger := logrus.New()
+
+	createdAt := time.Date(2021, 4, 20, 10, 0, 0, 0, time.UTC)
+	deposit := domain.Transfer{
+		ID:                "6f1c1a4e-3f0a-4d4b-8f59-1f1d2b7c8a90",
+		Type:              domain.TransferTypeDeposit,
+		FromAccountNumber: domain.LedgerExternalAccountNumber,
+		ToAccountNumber:   555001,
+		Amount:            domain.NewMoney(2500, "IDR"),
+		ConvertedAmount:   domain.NewMoney(2500, "IDR"),
+		Status:            domain.TransferStatusCompleted,
+		Reference:         "BR-0001",
+		Channel:           domain.ChannelBranch,
+		ReasonCode:        "CASH",
+		CreatedAt:         createdAt,
+		UpdatedAt:         createdAt,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAccountUseCase.On("Deposit", mock.Anything, 555001, domain.MovementParam{
+			Amount:     domain.NewMoney(2500, "IDR"),
+			Reference:  "BR-0001",
+			Channel:    domain.ChannelBranch,
+			ReasonCode: "CASH",
+		}).Return(deposit, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555009", domain.RoleTeller), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/account/555001/deposit",
+			bytes.NewBufferString(`{"amount":{"amount":"25.00","currency":"IDR"},"reference":"BR-0001","channel":"branch","reason_code":"CASH"}`))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"data":{"id":"6f1c1a4e-3f0a-4d4b-8f59-1f1d2b7c8a90","type":"deposit","from_account_number":0,"to_account_number":555001,`+
+			`"amount":{"amount":"25.00","currency":"IDR"},"converted_amount":{"amount":"25.00","currency":"IDR"},"status":"completed",`+
+			`"reference":"BR-0001","channel":"branch","reason_code":"CASH","created_at":"2021-04-20T10:00:00Z","updated_at":"2021-04-20T10:00:00Z"}}`, rec.Body.String())
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	cases := []struct {
+		name   string
+		role   string
+		body   string
+		status int
+	}{
+		{"Unknown-channel", domain.RoleTeller, `{"amount":{"amount":"25.00","currency":"IDR"},"reference":"BR-0001","channel":"mail","reason_code":"CASH"}`, http.StatusBadRequest},
+		{"Missing-reference", domain.RoleTeller, `{"amount":{"amount":"25.00","currency":"IDR"},"channel":"branch","reason_code":"CASH"}`, http.StatusBadRequest},
+		{"Customer", domain.RoleCustomer, `{"amount":{"amount":"25.00","currency":"IDR"},"reference":"BR-0001","channel":"branch","reason_code":"CASH"}`, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+
+			r := testutil.NewRouter()
+			r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", c.role), cursorSigner, logger)
+
+			req, err := http.NewRequest(http.MethodPost, "/account/555001/deposit", bytes.NewBufferString(c.body))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+			mockAccountUseCase.AssertNotCalled(t, "Deposit", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAccountHandler_HandlerAccountWithdraw(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Success", nil, http.StatusCreated},
+		{"Insufficient-balance", domain.ErrInsufficientBalance, http.StatusBadRequest},
+		{"Duplicate-reference", domain.ErrDuplicateReference, http.StatusConflict},
+		{"Account-not-found", domain.ErrAccountNotFound, http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+			mockAccountUseCase.On("Withdraw", mock.Anything, 555001, mock.AnythingOfType("domain.MovementParam")).
+				Return(domain.Transfer{Type: domain.TransferTypeWithdrawal}, c.err).Once()
+
+			r := testutil.NewRouter()
+			r = NewAccountHandler(r, mockAccountUseCase, authAs("555009", domain.RoleTeller), cursorSigner, logger)
+
+			req, err := http.NewRequest(http.MethodPost, "/account/555001/withdraw",
+				bytes.NewBufferString(`{"amount":{"amount":"40.00","currency":"IDR"},"reference":"ATM-0001","channel":"atm","reason_code":"CASH"}`))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+			mockAccountUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountHandler_HandlerGetAccountTransfers(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+
+		mockAccountUseCase.On("ListTransfers", mock.Anything, 555001, mock.MatchedBy(func(param domain.TransferListParam) bool {
+			return param.Limit == 11 &&
+				param.StartDate.Equal(time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)) &&
+				param.EndDate.Equal(time.Date(2021, 4, 30, 0, 0, 0, 0, time.UTC))
+		})).Return([]domain.Transfer{}, 0, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/555001/transfers?limit=10&offset=0&start_date=2021-04-01&end_date=2021-04-30", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid-date-range", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/555001/transfers?start_date=2021-04-30&end_date=2021-04-01", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockAccountUseCase.AssertNotCalled(t, "ListTransfers", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAccountHandler_HandlerGetAccountLedger(t *testing.T) {
+	logger := logrus.New()
+
+	entries := []domain.LedgerEntry{
+		{
+			ID:              1,
+			TransactionType: domain.LedgerTransactionOpening,
+			AccountNumber:   555001,
+			EntryType:       domain.LedgerEntryCredit,
+			Amount:          domain.NewMoney(10000, "IDR"),
+		},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAccountUseCase.On("ListLedgerEntries", mock.Anything, 555001, domain.LedgerEntryListParam{
+			Filter: util.Filter{Limit: 10, Offset: 0},
+		}).Return(entries, 1, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/555001/ledger?limit=10&offset=0", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Default-limit", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAccountUseCase.On("ListLedgerEntries", mock.Anything, 555001, domain.LedgerEntryListParam{
+			Filter: util.Filter{Limit: util.DefaultLimit},
+		}).Return(entries, 1, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/555001/ledger", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var body struct {
+			Data []domain.LedgerEntry `json:"data"`
+			Meta util.Meta            `json:"meta"`
+		}
+		err = json.Unmarshal(rec.Body.Bytes(), &body)
+		assert.NoError(t, err)
+		assert.Len(t, body.Data, 1)
+		assert.Equal(t, util.Meta{Total: 1, Limit: util.DefaultLimit}, body.Meta)
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	unsupported := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{"Cursor", "cursor=abc", "invalid_cursor"},
+		{"Sort", "sort=amount", "invalid_sort"},
+		{"Search", "search=opening", "invalid_search"},
+	}
+
+	for _, c := range unsupported {
+		t.Run(c.name, func(t *testing.T) {
+			mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+
+			r := testutil.NewRouter()
+			r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+			req, err := http.NewRequest(http.MethodGet, "/account/555001/ledger?"+c.query, nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), c.code)
+			mockAccountUseCase.AssertNotCalled(t, "ListLedgerEntries", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAccountHandler_HandlerAccountRebuildBalance(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+
+		mockAccountUseCase.On("RebuildBalance", mock.Anything, 555001).Return(domain.RebuildBalanceResponse{
+			AccountNumber:   555001,
+			PreviousBalance: domain.NewMoney(10000, "IDR"),
+			Balance:         domain.NewMoney(9000, "IDR"),
+		}, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/account/555001/ledger/rebuild", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		resp, err := ioutil.ReadAll(rec.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"data":{"account_number":555001,"previous_balance":{"amount":"100.00","currency":"IDR"},"balance":{"amount":"90.00","currency":"IDR"}}}`, string(resp))
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Account-not-found", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+
+		mockAccountUseCase.On("RebuildBalance", mock.Anything, 1).Return(domain.RebuildBalanceResponse{}, sql.ErrNoRows).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/account/1/ledger/rebuild", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockAccountUseCase.AssertExpectations(t)
+	})
+}
+
 func TestAccountHandler_HandlerAccountLogin(t *testing.T) {
 	logger := logrus.New()
 
 	account := domain.Account{
 		AccountNumber:  1,
 		CustomerNumber: 1,
-		Balance:        1000,
+		Balance:        domain.NewMoney(1000, "IDR"),
 		Email:          "mail@email.com",
 		Password:       "$2y$12$55Pvvir6aXTbi3tE5toEyuUMgPCJ1uytiVREzrSHDgXoNFva7kLOK",
 	}
 
 	mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
 
-	t.Run("account-not-found", func(t *testing.T) {
-		mockAccountUseCase.On("Login", mock.Anything, mock.AnythingOfType("domain.AccountLoginParam")).Return(domain.LoginResponse{}, sql.ErrNoRows).Once()
+	t.Run("invalid-credentials", func(t *testing.T) {
+		mockAccountUseCase.On("Login", mock.Anything, mock.AnythingOfType("domain.AccountLoginParam")).Return(domain.LoginResponse{}, domain.ErrInvalidCredentials).Once()
 
-		r := gin.Default()
-		r = NewAccountHandler(r, mockAccountUseCase, logger)
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 		accountMarshal, err := json.Marshal(&account)
 		assert.NoError(t, err)
@@ -211,15 +909,60 @@ func TestAccountHandler_HandlerAccountLogin(t *testing.T) {
 
 		r.ServeHTTP(rec, req)
 
-		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, `{"code":"invalid_credentials","errors":["Invalid email or password"]}`, rec.Body.String())
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("throttled", func(t *testing.T) {
+		mockAccountUseCase.On("Login", mock.Anything, mock.MatchedBy(func(param domain.AccountLoginParam) bool {
+			return param.IP == "10.0.0.1"
+		})).Return(domain.LoginResponse{}, domain.LoginThrottledError{RetryAfter: 1500 * time.Millisecond}).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, new(auth_usecase_mock.AuthMockUseCase), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/account/login", bytes.NewBufferString(`{"email":"mail@email.com","password":"secret"}`))
+		assert.NoError(t, err)
+		req.RemoteAddr = "10.0.0.1:51234"
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("spoofed-forwarded-for", func(t *testing.T) {
+		mockAccountUseCase.On("Login", mock.Anything, mock.MatchedBy(func(param domain.AccountLoginParam) bool {
+			return param.IP == "10.0.0.1"
+		})).Return(domain.LoginResponse{}, domain.ErrInvalidCredentials).Once()
+
+		r := testutil.NewRouter()
+		r.Use(middleware.ClientIP(nil))
+		r = NewAccountHandler(r, mockAccountUseCase, new(auth_usecase_mock.AuthMockUseCase), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/account/login", bytes.NewBufferString(`{"email":"mail@email.com","password":"secret"}`))
+		assert.NoError(t, err)
+		req.RemoteAddr = "10.0.0.1:51234"
+		req.Header.Set("X-Forwarded-For", "198.51.100.1")
+		req.Header.Set("X-Real-IP", "198.51.100.2")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
 		mockAccountUseCase.AssertExpectations(t)
 	})
 
 	t.Run("success", func(t *testing.T) {
 		mockAccountUseCase.On("Login", mock.Anything, mock.AnythingOfType("domain.AccountLoginParam")).Return(domain.LoginResponse{Token: "token"}, nil).Once()
 
-		r := gin.Default()
-		r = NewAccountHandler(r, mockAccountUseCase, logger)
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 		param := domain.AccountLoginParam{
 			Email:    "email@mail.com",
@@ -240,7 +983,284 @@ func TestAccountHandler_HandlerAccountLogin(t *testing.T) {
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, "{\"token\":\"token\"}", string(resp))
+		assert.Equal(t, `{"data":{"token":"token"}}`, string(resp))
+		mockAccountUseCase.AssertExpectations(t)
+	})
+}
+
+func TestAccountHandler_HandlerLoginMFA(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name     string
+		response domain.LoginResponse
+		err      error
+		status   int
+		body     string
+	}{
+		{"success", domain.LoginResponse{Token: "token", RefreshToken: "refresh"}, nil, http.StatusOK, `{"data":{"token":"token","refresh_token":"refresh"}}`},
+		{"wrong-code", domain.LoginResponse{}, domain.ErrInvalidMFACode, http.StatusUnauthorized, `{"code":"invalid_mfa_code","errors":["Invalid two-factor code"]}`},
+		{"expired-challenge", domain.LoginResponse{}, domain.ErrInvalidMFAToken, http.StatusUnauthorized, `{"code":"invalid_mfa_token","errors":["Invalid or expired MFA token"]}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+			mockAccountUseCase.On("LoginMFA", mock.Anything, mock.MatchedBy(func(param domain.LoginMFAParam) bool {
+				return param.MFAToken == "challenge" && param.Code == "123456" && param.IP == "10.0.0.1"
+			})).Return(c.response, c.err).Once()
+
+			r := testutil.NewRouter()
+			r = NewAccountHandler(r, mockAccountUseCase, new(auth_usecase_mock.AuthMockUseCase), cursorSigner, logger)
+
+			req, err := http.NewRequest(http.MethodPost, "/account/login/mfa", bytes.NewBufferString(`{"mfa_token":"challenge","code":"123456"}`))
+			assert.NoError(t, err)
+			req.RemoteAddr = "10.0.0.1:51234"
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+			assert.Equal(t, c.body, rec.Body.String())
+			mockAccountUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountHandler_Authorization(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		role   string
+		method string
+		path   string
+		body   string
+	}{
+		{"Customer-cannot-list", domain.RoleCustomer, http.MethodGet, "/account", ""},
+		{"Customer-cannot-read-other-account", domain.RoleCustomer, http.MethodGet, "/account/555002", ""},
+		{"Customer-cannot-read-other-ledger", domain.RoleCustomer, http.MethodGet, "/account/555002/ledger", ""},
+		{"Customer-cannot-create", domain.RoleCustomer, http.MethodPost, "/account", "{}"},
+		{"Teller-cannot-update", domain.RoleTeller, http.MethodPut, "/account", "{}"},
+		{"Teller-cannot-delete", domain.RoleTeller, http.MethodDelete, "/account", "{}"},
+		{"Teller-cannot-rebuild", domain.RoleTeller, http.MethodPost, "/account/555002/ledger/rebuild", ""},
+		{"Teller-cannot-create-admin", domain.RoleTeller, http.MethodPost, "/account", `{"account_number":555003,"customer_number":1001,"email":"admin@mail.com","password":"Str0ngPassword","role":"admin"}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+
+			r := testutil.NewRouter()
+			r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", c.role), cursorSigner, logger)
+
+			req, err := http.NewRequest(c.method, c.path, bytes.NewBufferString(c.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			mockAccountUseCase.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Customer-reads-own-account", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAccountUseCase.On("GetByAccountNumber", mock.Anything, 555001).Return(domain.DetailByAccountNumberResponse{}, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleCustomer), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/555001", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Missing-token", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, new(auth_usecase_mock.AuthMockUseCase), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/account", nil)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestAccountHandler_HandlerAccountUnlock(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAccountUseCase.On("Unlock", mock.Anything, 555001).Return(nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555009", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/account/555001/unlock", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Account-not-found", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAccountUseCase.On("Unlock", mock.Anything, 555001).Return(sql.ErrNoRows).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555009", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/account/555001/unlock", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Teller-cannot-unlock", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, authAs("555009", domain.RoleTeller), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/account/555001/unlock", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockAccountUseCase.AssertNotCalled(t, "Unlock", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAccountHandler_HandlerAccountChangePassword(t *testing.T) {
+	logger := logrus.New()
+
+	param := domain.PasswordChangeParam{
+		OldPassword: "OldPassw0rd",
+		NewPassword: "NewPassw0rd",
+	}
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"success", nil, http.StatusNoContent},
+		{"wrong-password", domain.ErrWrongPassword, http.StatusUnauthorized},
+		{"weak-password", &domain.Error{Kind: domain.KindValidation, Code: "weak_password", Details: []string{"must contain a digit"}}, http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+			mockAccountUseCase.On("ChangePassword", mock.Anything, 555001, param).Return(c.err).Once()
+
+			r := testutil.NewRouter()
+			r = NewAccountHandler(r, mockAccountUseCase, authAs("555001", domain.RoleCustomer), cursorSigner, logger)
+
+			reqBody, err := json.Marshal(param)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/account/password", bytes.NewBuffer(reqBody))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+			mockAccountUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountHandler_HandlerAccountPasswordReset(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("request", func(t *testing.T) {
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockAccountUseCase.On("RequestPasswordReset", mock.Anything, domain.PasswordResetRequestParam{Email: "mail@email.com"}).Return(nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAccountHandler(r, mockAccountUseCase, new(auth_usecase_mock.AuthMockUseCase), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/account/password/reset", bytes.NewBufferString(`{"email":"mail@email.com"}`))
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
 		mockAccountUseCase.AssertExpectations(t)
 	})
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"confirm", nil, http.StatusNoContent},
+		{"confirm-invalid-token", domain.ErrInvalidResetToken, http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			param := domain.PasswordResetParam{Token: "reset-token", NewPassword: "NewPassw0rd"}
+
+			mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+			mockAccountUseCase.On("ResetPassword", mock.Anything, param).Return(c.err).Once()
+
+			r := testutil.NewRouter()
+			r = NewAccountHandler(r, mockAccountUseCase, new(auth_usecase_mock.AuthMockUseCase), cursorSigner, logger)
+
+			reqBody, err := json.Marshal(param)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/account/password/reset/confirm", bytes.NewBuffer(reqBody))
+			assert.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+			mockAccountUseCase.AssertExpectations(t)
+		})
+	}
 }
+
+var cursorSigner = util.NewCursorSigner("cursor_secret")
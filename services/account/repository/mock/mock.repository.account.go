@@ -19,6 +19,12 @@ func (c *AccountMockRepository) List(ctx context.Context, param domain.AccountLi
 	return result.([]domain.Account), args.Error(1)
 }
 
+func (c *AccountMockRepository) Count(ctx context.Context, param domain.AccountListParam) (int, error) {
+	args := c.Called(ctx, param)
+
+	return args.Int(0), args.Error(1)
+}
+
 func (c *AccountMockRepository) GetByAccountNumber(ctx context.Context, customerNumber int) (domain.Account, error) {
 	args := c.Called(ctx, customerNumber)
 	result := args.Get(0)
@@ -26,6 +32,13 @@ func (c *AccountMockRepository) GetByAccountNumber(ctx context.Context, customer
 	return result.(domain.Account), args.Error(1)
 }
 
+func (c *AccountMockRepository) GetByAccountNumberForUpdate(ctx context.Context, accountNumber int) (domain.Account, error) {
+	args := c.Called(ctx, accountNumber)
+	result := args.Get(0)
+
+	return result.(domain.Account), args.Error(1)
+}
+
 func (c *AccountMockRepository) GetByEmail(ctx context.Context, email string) (domain.Account, error) {
 	args := c.Called(ctx, email)
 	result := args.Get(0)
@@ -45,6 +58,18 @@ func (c *AccountMockRepository) Update(ctx context.Context, a *domain.Account) e
 	return args.Error(0)
 }
 
+func (c *AccountMockRepository) UpdateBalance(ctx context.Context, accountNumber int, balance domain.Money) error {
+	args := c.Called(ctx, accountNumber, balance)
+
+	return args.Error(0)
+}
+
+func (c *AccountMockRepository) UpdatePassword(ctx context.Context, accountNumber int, password string) error {
+	args := c.Called(ctx, accountNumber, password)
+
+	return args.Error(0)
+}
+
 func (c *AccountMockRepository) Delete(ctx context.Context, a *domain.Account) error {
 	args := c.Called(ctx, a)
 
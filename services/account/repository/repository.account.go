@@ -5,43 +5,86 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/oniharnantyo/golang-backend-example/database"
 	"github.com/oniharnantyo/golang-backend-example/domain"
 	"github.com/oniharnantyo/golang-backend-example/util"
+
+	"github.com/lib/pq"
 )
 
+// uniqueViolation is the postgres error code of a unique constraint violation.
+const uniqueViolation = "23505"
+
 type accountRepository struct {
 	dbPool *sql.DB
-	txPool *sql.Tx
 }
 
+// listConditions are the filters of domain.AccountListParam.
+var listConditions = []util.Condition{
+	{Param: "customer_number", SQL: `customer_number = ?`},
+	{Param: "balance_gte", SQL: `balance >= ?`},
+	{Param: "balance_lte", SQL: `balance <= ?`},
+	{Param: "email_domain", SQL: `SPLIT_PART(email, '@', 2) = LOWER(?)`},
+}
+
+// listQuery has the conditions List and Count share.
+func listQuery(param domain.AccountListParam) *util.Query {
+	return util.NewQuery().
+		Search(param.Search, "CAST(account_number AS TEXT)", "CAST(customer_number AS TEXT)").
+		Match(param, listConditions...)
+}
+
+// cursorValues returns the values of the sort columns in after.
+func cursorValues(after *domain.AccountCursor, sort []util.SortField) []interface{} {
+	values := make([]interface{}, len(sort))
+	for i, field := range sort {
+		switch field.Column {
+		case "account_number":
+			values[i] = after.AccountNumber
+		case "customer_number":
+			values[i] = after.CustomerNumber
+		case "balance":
+			values[i] = after.Balance
+		case "email":
+			values[i] = after.Email
+		}
+	}
+
+	return values
+}
+
+// List pages by offset, or by keyset on the sort columns after param.After.
 func (c accountRepository) List(ctx context.Context, param domain.AccountListParam) ([]domain.Account, error) {
-	var filters []string
+	sort, err := domain.AccountSort.Parse(param.Sort, param.Order)
+	if err != nil {
+		return nil, domain.ErrInvalidSort
+	}
 
-	if param.Search != "" {
-		filters = append(filters,
-			fmt.Sprintf(`(LOWER(account_number) LIKE '%%%s%%' OR LOWER(customer_number) LIKE '%%%s%%')`,
-				param.Search, param.Search))
+	query := listQuery(param)
+
+	if param.After != nil {
+		query.After(sort, cursorValues(param.After, sort)...)
 	}
 
-	filterQuery := util.BuildFilterQuery(filters)
+	query.OrderBy(sort).Page(param.Limit, param.Offset)
 
-	stmt, err := c.dbPool.Prepare(fmt.Sprintf(`
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
 		SELECT
 			account_number,
 			customer_number,
 			balance,
+			currency,
 			email,
-			password
+			password,
+			role
 		FROM account
 			%s
-		ORDER BY account_number %s
-		LIMIT $1 OFFSET $2
-	`, filterQuery, param.Order))
+	`, query))
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := stmt.QueryContext(ctx, param.Limit, param.Offset)
+	rows, err := stmt.QueryContext(ctx, query.Args()...)
 	if err != nil {
 		return nil, err
 	}
@@ -54,9 +97,11 @@ func (c accountRepository) List(ctx context.Context, param domain.AccountListPar
 		err := rows.Scan(
 			&account.AccountNumber,
 			&account.CustomerNumber,
-			&account.Balance,
+			&account.Balance.Amount,
+			&account.Balance.Currency,
 			&account.Email,
 			&account.Password,
+			&account.Role,
 		)
 		if err != nil {
 			return nil, err
@@ -68,17 +113,77 @@ func (c accountRepository) List(ctx context.Context, param domain.AccountListPar
 	return accounts, nil
 }
 
+func (c accountRepository) Count(ctx context.Context, param domain.AccountListParam) (int, error) {
+	query := listQuery(param)
+
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			COUNT(*)
+		FROM account
+			%s
+	`, query))
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	err = stmt.QueryRowContext(ctx, query.Args()...).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
 func (c accountRepository) GetByAccountNumber(ctx context.Context, accountNumber int) (domain.Account, error) {
-	stmt, err := c.dbPool.Prepare(fmt.Sprintf(`
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			account_number,
+			customer_number,
+			balance,
+			currency,
+			email,
+			password,
+			role
+		FROM account
+		WHERE
+			account_number = $1
+	`))
+	if err != nil {
+		return domain.Account{}, err
+	}
+
+	var account domain.Account
+	err = stmt.QueryRowContext(ctx, accountNumber).Scan(
+		&account.AccountNumber,
+		&account.CustomerNumber,
+		&account.Balance.Amount,
+		&account.Balance.Currency,
+		&account.Email,
+		&account.Password,
+		&account.Role,
+	)
+	if err != nil {
+		return domain.Account{}, err
+	}
+
+	return account, nil
+}
+
+func (c accountRepository) GetByAccountNumberForUpdate(ctx context.Context, accountNumber int) (domain.Account, error) {
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
 		SELECT
 			account_number,
 			customer_number,
 			balance,
+			currency,
 			email,
-			password
+			password,
+			role
 		FROM account
 		WHERE
 			account_number = $1
+		FOR UPDATE
 	`))
 	if err != nil {
 		return domain.Account{}, err
@@ -88,9 +193,11 @@ func (c accountRepository) GetByAccountNumber(ctx context.Context, accountNumber
 	err = stmt.QueryRowContext(ctx, accountNumber).Scan(
 		&account.AccountNumber,
 		&account.CustomerNumber,
-		&account.Balance,
+		&account.Balance.Amount,
+		&account.Balance.Currency,
 		&account.Email,
 		&account.Password,
+		&account.Role,
 	)
 	if err != nil {
 		return domain.Account{}, err
@@ -100,13 +207,15 @@ func (c accountRepository) GetByAccountNumber(ctx context.Context, accountNumber
 }
 
 func (c accountRepository) GetByEmail(ctx context.Context, email string) (domain.Account, error) {
-	stmt, err := c.dbPool.Prepare(fmt.Sprintf(`
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
 		SELECT
 			account_number,
 			customer_number,
 			balance,
+			currency,
 			email,
-			password
+			password,
+			role
 		FROM account
 		WHERE
 			email = $1
@@ -119,9 +228,11 @@ func (c accountRepository) GetByEmail(ctx context.Context, email string) (domain
 	err = stmt.QueryRowContext(ctx, email).Scan(
 		&account.AccountNumber,
 		&account.CustomerNumber,
-		&account.Balance,
+		&account.Balance.Amount,
+		&account.Balance.Currency,
 		&account.Email,
 		&account.Password,
+		&account.Role,
 	)
 	if err != nil {
 		return domain.Account{}, err
@@ -131,15 +242,17 @@ func (c accountRepository) GetByEmail(ctx context.Context, email string) (domain
 }
 
 func (c accountRepository) Store(ctx context.Context, a *domain.Account) error {
-	stmt, err := c.dbPool.Prepare(fmt.Sprintf(`
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
 		INSERT INTO account (
 			account_number,
 			customer_number,
 			balance,
+			currency,
 			email,
-			password
+			password,
+			role
 		) VALUES (
-			$1, $2, $3, $4, $5
+			$1, $2, $3, $4, $5, $6, $7
 		)`))
 	if err != nil {
 		return err
@@ -148,10 +261,15 @@ func (c accountRepository) Store(ctx context.Context, a *domain.Account) error {
 	_, err = stmt.ExecContext(ctx,
 		&a.AccountNumber,
 		&a.CustomerNumber,
-		&a.Balance,
+		&a.Balance.Amount,
+		&a.Balance.Currency,
 		&a.Email,
 		&a.Password,
+		&a.Role,
 	)
+	if emailTaken(err) {
+		return domain.ErrEmailTaken
+	}
 	if err != nil {
 		return err
 	}
@@ -160,25 +278,80 @@ func (c accountRepository) Store(ctx context.Context, a *domain.Account) error {
 }
 
 func (c accountRepository) Update(ctx context.Context, a *domain.Account) error {
-	stmt, err := c.dbPool.Prepare(fmt.Sprintf(`
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
 		UPDATE account SET
 			customer_number = $1,
-			balance = $2,
-			email = $3,
-			password = $4
+			email = $2
 		WHERE
-			account_number = $5
+			account_number = $3
 	`))
 	if err != nil {
 		return err
 	}
 
-	_, err = stmt.ExecContext(ctx,
+	// Balance and password are left out on purpose, they only change through
+	// UpdateBalance together with the matching ledger entries and through
+	// UpdatePassword.
+	result, err := stmt.ExecContext(ctx,
 		a.CustomerNumber,
-		a.Balance,
-		a.AccountNumber,
 		a.Email,
-		a.Password,
+		a.AccountNumber,
+	)
+	if emailTaken(err) {
+		return domain.ErrEmailTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (c accountRepository) UpdateBalance(ctx context.Context, accountNumber int, balance domain.Money) error {
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE account SET
+			balance = $1
+		WHERE
+			account_number = $2
+	`))
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx,
+		balance.Amount,
+		accountNumber,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c accountRepository) UpdatePassword(ctx context.Context, accountNumber int, password string) error {
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE account SET
+			password = $1
+		WHERE
+			account_number = $2
+	`))
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx,
+		password,
+		accountNumber,
 	)
 	if err != nil {
 		return err
@@ -189,7 +362,7 @@ func (c accountRepository) Update(ctx context.Context, a *domain.Account) error
 
 func (c accountRepository) Delete(ctx context.Context, a *domain.Account) error {
 
-	stmt, err := c.dbPool.Prepare(fmt.Sprintf(`
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
 		DELETE FROM account
 		WHERE
 			account_number = $1
@@ -207,6 +380,12 @@ func (c accountRepository) Delete(ctx context.Context, a *domain.Account) error
 	return nil
 }
 
+// emailTaken reports whether err violates the unique email of the accounts.
+func emailTaken(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == uniqueViolation && pqErr.Constraint == "email_unique"
+}
+
 func NewAccountRepository(db *sql.DB) domain.AccountRepository {
 	return &accountRepository{
 		dbPool: db,
@@ -7,12 +7,14 @@ import (
 	"log"
 	"testing"
 
+	"github.com/oniharnantyo/golang-backend-example/database"
 	"github.com/oniharnantyo/golang-backend-example/domain"
 	"github.com/oniharnantyo/golang-backend-example/util"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 )
 
 func initMock() (*sql.DB, sqlmock.Sqlmock) {
@@ -29,9 +31,9 @@ func TestAccountRepository_List(t *testing.T) {
 
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"account_number", "customer_number", "balance", "email", "password"}).
-		AddRow(555001, 1001, 10000, "email@mail.com", "password").
-		AddRow(555002, 1002, 15000, "email@mail.com", "password")
+	rows := sqlmock.NewRows([]string{"account_number", "customer_number", "balance", "currency", "email", "password", "role"}).
+		AddRow(555001, 1001, 10000, "IDR", "email@mail.com", "password", "customer").
+		AddRow(555002, 1002, 15000, "IDR", "email@mail.com", "password", "customer")
 
 	search := "1"
 	order := "ASC"
@@ -43,22 +45,24 @@ func TestAccountRepository_List(t *testing.T) {
 			account_number, 
 			customer_number,
 			balance,
+			currency,
 			email,
-			password
+			password,
+			role
 		FROM account 
-		WHERE 
-			(LOWER(account_number) LIKE '%%1%%' OR LOWER(customer_number) LIKE '%%1%%') 
-		ORDER BY account_number ASC 
-		LIMIT $1 OFFSET $2`)
+		WHERE
+			(LOWER(CAST(account_number AS TEXT)) LIKE $1 OR LOWER(CAST(customer_number AS TEXT)) LIKE $1)
+		ORDER BY account_number ASC
+		LIMIT $2 OFFSET $3`)
 
 	prep := mock.ExpectPrepare(query)
 
-	prep.ExpectQuery().WithArgs(limit, offset).WillReturnRows(rows)
+	prep.ExpectQuery().WithArgs("%1%", limit, offset).WillReturnRows(rows)
 
 	c := NewAccountRepository(db)
 
 	customers, err := c.List(context.Background(), domain.AccountListParam{
-		util.Filter{
+		Filter: util.Filter{
 			Limit:  limit,
 			Offset: offset,
 			Search: search,
@@ -69,21 +73,181 @@ func TestAccountRepository_List(t *testing.T) {
 	assert.Len(t, customers, 2)
 }
 
+func TestAccountRepository_ListBindsSearchAndOrder(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		SELECT
+			account_number,
+			customer_number,
+			balance,
+			currency,
+			email,
+			password,
+			role
+		FROM account
+		WHERE
+			(LOWER(CAST(account_number AS TEXT)) LIKE $1 OR LOWER(CAST(customer_number AS TEXT)) LIKE $1)
+		ORDER BY account_number ASC
+		LIMIT $2 OFFSET $3`)
+
+	mock.ExpectPrepare(query).ExpectQuery().
+		WithArgs(`%1\%' or '1'='1%`, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"account_number", "customer_number", "balance", "currency", "email", "password", "role"}))
+
+	c := NewAccountRepository(db)
+
+	accounts, err := c.List(context.Background(), domain.AccountListParam{
+		Filter: util.Filter{
+			Limit:  10,
+			Search: "1%' OR '1'='1",
+			Order:  "asc; DROP TABLE account",
+		}})
+	assert.NoError(t, err)
+	assert.Empty(t, accounts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountRepository_ListAfterCursor(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"account_number", "customer_number", "balance", "currency", "email", "password", "role"}).
+		AddRow(555001, 1001, 10000, "IDR", "email@mail.com", "password", "customer")
+
+	query := fmt.Sprintf(`
+		SELECT
+			account_number,
+			customer_number,
+			balance,
+			currency,
+			email,
+			password,
+			role
+		FROM account
+		WHERE
+			account_number < $1
+		ORDER BY account_number DESC
+		LIMIT $2 OFFSET $3`)
+
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs(555002, 10, 0).WillReturnRows(rows)
+
+	c := NewAccountRepository(db)
+
+	accounts, err := c.List(context.Background(), domain.AccountListParam{
+		Filter: util.Filter{Limit: 10, Order: "desc"},
+		After:  &domain.AccountCursor{AccountNumber: 555002},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountRepository_ListFilteredAndSorted(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"account_number", "customer_number", "balance", "currency", "email", "password", "role"}).
+		AddRow(555003, 1001, 4000, "IDR", "other@email.com", "password", "customer")
+
+	query := fmt.Sprintf(`
+		SELECT
+			account_number,
+			customer_number,
+			balance,
+			currency,
+			email,
+			password,
+			role
+		FROM account
+		WHERE
+			customer_number = $1 AND balance >= $2 AND balance <= $3 AND SPLIT_PART(email, '@', 2) = LOWER($4) AND
+			((balance < $5) OR (balance = $5 AND account_number > $6))
+		ORDER BY balance DESC, account_number ASC
+		LIMIT $7 OFFSET $8`)
+
+	mock.ExpectPrepare(query).ExpectQuery().
+		WithArgs(1001, int64(0), int64(5000), "Email.com", int64(4500), 555002, 10, 0).
+		WillReturnRows(rows)
+
+	c := NewAccountRepository(db)
+
+	balanceGte, balanceLte := int64(0), int64(5000)
+	accounts, err := c.List(context.Background(), domain.AccountListParam{
+		Filter:         util.Filter{Limit: 10, Sort: "-balance,account_number"},
+		CustomerNumber: 1001,
+		BalanceGte:     &balanceGte,
+		BalanceLte:     &balanceLte,
+		EmailDomain:    "Email.com",
+		After:          &domain.AccountCursor{AccountNumber: 555002, Balance: 4500},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountRepository_ListInvalidSort(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	c := NewAccountRepository(db)
+
+	_, err := c.List(context.Background(), domain.AccountListParam{
+		Filter: util.Filter{Limit: 10, Sort: "password"},
+	})
+	assert.Equal(t, domain.ErrInvalidSort, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountRepository_Count(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*)
+		FROM account
+		WHERE
+			(LOWER(CAST(account_number AS TEXT)) LIKE $1 OR LOWER(CAST(customer_number AS TEXT)) LIKE $1)`)
+
+	prep := mock.ExpectPrepare(query)
+
+	prep.ExpectQuery().WithArgs("%1%").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	c := NewAccountRepository(db)
+
+	total, err := c.Count(context.Background(), domain.AccountListParam{
+		Filter: util.Filter{
+			Limit:  10,
+			Search: "1",
+		}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+}
+
 func TestAccountRepository_GetByAccountNumber(t *testing.T) {
 	db, mock := initMock()
 
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"account_number", "customer_number", "balance", "email", "password"}).
-		AddRow(555002, 1002, 15000, "email@mail.com", "password")
+	rows := sqlmock.NewRows([]string{"account_number", "customer_number", "balance", "currency", "email", "password", "role"}).
+		AddRow(555002, 1002, 15000, "IDR", "email@mail.com", "password", "customer")
 
 	query := fmt.Sprintf(`
 		SELECT
 			account_number,
 			customer_number,
 			balance,
+			currency,
 			email,
-			password
+			password,
+			role
 		FROM account
 		WHERE
 			account_number = $1
@@ -101,21 +265,66 @@ func TestAccountRepository_GetByAccountNumber(t *testing.T) {
 	assert.NotNil(t, customers)
 }
 
+func TestAccountRepository_GetByAccountNumberForUpdate(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"account_number", "customer_number", "balance", "currency", "email", "password", "role"}).
+		AddRow(555002, 1002, 15000, "IDR", "email@mail.com", "password", "customer")
+
+	query := fmt.Sprintf(`
+		SELECT
+			account_number,
+			customer_number,
+			balance,
+			currency,
+			email,
+			password,
+			role
+		FROM account
+		WHERE
+			account_number = $1
+		FOR UPDATE
+	`)
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(query)
+
+	accountNumber := 555002
+	prep.ExpectQuery().WithArgs(accountNumber).WillReturnRows(rows)
+	mock.ExpectCommit()
+
+	c := NewAccountRepository(db)
+
+	var account domain.Account
+	err := database.NewTransactionManager(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+		var err error
+		account, err = c.GetByAccountNumberForUpdate(ctx, accountNumber)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, accountNumber, account.AccountNumber)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestAccountRepository_GetByEmail(t *testing.T) {
 	db, mock := initMock()
 
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"account_number", "customer_number", "balance", "email", "password"}).
-		AddRow(555001, 1001, 10000, "email@mail.com", "password")
+	rows := sqlmock.NewRows([]string{"account_number", "customer_number", "balance", "currency", "email", "password", "role"}).
+		AddRow(555001, 1001, 10000, "IDR", "email@mail.com", "password", "customer")
 
 	query := fmt.Sprintf(`
 		SELECT
 			account_number,
 			customer_number,
 			balance,
+			currency,
 			email,
-			password
+			password,
+			role
 		FROM account
 		WHERE
 			email = $1
@@ -143,20 +352,23 @@ func TestAccountRepository_Store(t *testing.T) {
 			account_number,
 			customer_number,
 			balance,
+			currency,
 			email,
-			password
+			password,
+			role
 		) VALUES (
-			$1, $2, $3, $4, $5
+			$1, $2, $3, $4, $5, $6, $7
 		)`)
 
 	prep := mock.ExpectPrepare(query)
 
 	accountNumber := 555001
 	customerNumber := 1001
-	balance := 10000
+	balance := domain.NewMoney(10000, "IDR")
 	email := "email@mail.com"
 	password := "password"
-	prep.ExpectExec().WithArgs(accountNumber, customerNumber, balance, email, password).
+	role := domain.RoleCustomer
+	prep.ExpectExec().WithArgs(accountNumber, customerNumber, balance.Amount, balance.Currency, email, password, role).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	c := NewAccountRepository(db)
@@ -167,9 +379,27 @@ func TestAccountRepository_Store(t *testing.T) {
 		Balance:        balance,
 		Email:          email,
 		Password:       password,
+		Role:           role,
 	})
 
 	assert.NoError(t, err)
+
+	t.Run("Email-taken", func(t *testing.T) {
+		prep := mock.ExpectPrepare(query)
+		prep.ExpectExec().WithArgs(accountNumber, customerNumber, balance.Amount, balance.Currency, email, password, role).
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "email_unique"})
+
+		err := c.Store(context.Background(), &domain.Account{
+			AccountNumber:  accountNumber,
+			CustomerNumber: customerNumber,
+			Balance:        balance,
+			Email:          email,
+			Password:       password,
+			Role:           role,
+		})
+
+		assert.Equal(t, domain.ErrEmailTaken, err)
+	})
 }
 
 func TestAccountRepository_Update(t *testing.T) {
@@ -180,20 +410,18 @@ func TestAccountRepository_Update(t *testing.T) {
 	query := fmt.Sprintf(`
 		UPDATE account SET
 			customer_number = $1,
-			balance = $2,
-			email = $3,
-			password = $4
+			email = $2
 		WHERE
-			account_number = $5`)
+			account_number = $3`)
 
 	prep := mock.ExpectPrepare(query)
 
 	accountNumber := 555001
 	customerNumber := 1001
-	balance := 10000
+	balance := domain.NewMoney(10000, "IDR")
 	email := "email@mail.com"
 	password := "password"
-	prep.ExpectExec().WithArgs(customerNumber, balance, accountNumber, email, password).
+	prep.ExpectExec().WithArgs(customerNumber, email, accountNumber).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	c := NewAccountRepository(db)
@@ -207,6 +435,85 @@ func TestAccountRepository_Update(t *testing.T) {
 	})
 
 	assert.NoError(t, err)
+
+	t.Run("Email-taken", func(t *testing.T) {
+		prep := mock.ExpectPrepare(query)
+		prep.ExpectExec().WithArgs(customerNumber, email, accountNumber).
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "email_unique"})
+
+		err := c.Update(context.Background(), &domain.Account{
+			AccountNumber:  accountNumber,
+			CustomerNumber: customerNumber,
+			Email:          email,
+		})
+
+		assert.Equal(t, domain.ErrEmailTaken, err)
+	})
+
+	t.Run("Not-found", func(t *testing.T) {
+		prep := mock.ExpectPrepare(query)
+		prep.ExpectExec().WithArgs(customerNumber, email, accountNumber).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := c.Update(context.Background(), &domain.Account{
+			AccountNumber:  accountNumber,
+			CustomerNumber: customerNumber,
+			Email:          email,
+		})
+
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+}
+
+func TestAccountRepository_UpdateBalance(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		UPDATE account SET
+			balance = $1
+		WHERE
+			account_number = $2`)
+
+	prep := mock.ExpectPrepare(query)
+
+	accountNumber := 555001
+	balance := domain.NewMoney(9000, "IDR")
+	prep.ExpectExec().WithArgs(balance.Amount, accountNumber).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	c := NewAccountRepository(db)
+
+	err := c.UpdateBalance(context.Background(), accountNumber, balance)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountRepository_UpdatePassword(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		UPDATE account SET
+			password = $1
+		WHERE
+			account_number = $2`)
+
+	prep := mock.ExpectPrepare(query)
+
+	accountNumber := 555001
+	password := "$2a$12$hash"
+	prep.ExpectExec().WithArgs(password, accountNumber).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	c := NewAccountRepository(db)
+
+	err := c.UpdatePassword(context.Background(), accountNumber, password)
+
+	assert.NoError(t, err)
 }
 
 func TestAccountRepository_Delete(t *testing.T) {
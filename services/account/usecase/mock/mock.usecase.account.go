@@ -12,11 +12,11 @@ type AccountMockUseCase struct {
 	mock.Mock
 }
 
-func (c *AccountMockUseCase) List(ctx context.Context, param domain.AccountListParam) ([]domain.Account, error) {
+func (c *AccountMockUseCase) List(ctx context.Context, param domain.AccountListParam) ([]domain.Account, int, error) {
 	args := c.Called(ctx, param)
 	result := args.Get(0)
 
-	return result.([]domain.Account), args.Error(1)
+	return result.([]domain.Account), args.Int(1), args.Error(2)
 }
 
 func (c *AccountMockUseCase) GetByAccountNumber(ctx context.Context, customerNumber int) (domain.DetailByAccountNumberResponse, error) {
@@ -26,10 +26,11 @@ func (c *AccountMockUseCase) GetByAccountNumber(ctx context.Context, customerNum
 	return result.(domain.DetailByAccountNumberResponse), args.Error(1)
 }
 
-func (c *AccountMockUseCase) Store(ctx context.Context, a *domain.Account) error {
-	args := c.Called(ctx, a)
+func (c *AccountMockUseCase) Register(ctx context.Context, param domain.AccountRegisterParam) (domain.Account, error) {
+	args := c.Called(ctx, param)
+	result := args.Get(0)
 
-	return args.Error(0)
+	return result.(domain.Account), args.Error(1)
 }
 
 func (c *AccountMockUseCase) Update(ctx context.Context, a *domain.Account) error {
@@ -44,10 +45,46 @@ func (c *AccountMockUseCase) Delete(ctx context.Context, a *domain.Account) erro
 	return args.Error(0)
 }
 
-func (c *AccountMockUseCase) Transfer(ctx context.Context, fromAccountNumber int, a domain.TransferParam) error {
+func (c *AccountMockUseCase) Transfer(ctx context.Context, fromAccountNumber int, a domain.TransferParam) (domain.Transfer, error) {
 	args := c.Called(ctx, fromAccountNumber, a)
+	result := args.Get(0)
 
-	return args.Error(0)
+	return result.(domain.Transfer), args.Error(1)
+}
+
+func (c *AccountMockUseCase) Deposit(ctx context.Context, accountNumber int, a domain.MovementParam) (domain.Transfer, error) {
+	args := c.Called(ctx, accountNumber, a)
+	result := args.Get(0)
+
+	return result.(domain.Transfer), args.Error(1)
+}
+
+func (c *AccountMockUseCase) Withdraw(ctx context.Context, accountNumber int, a domain.MovementParam) (domain.Transfer, error) {
+	args := c.Called(ctx, accountNumber, a)
+	result := args.Get(0)
+
+	return result.(domain.Transfer), args.Error(1)
+}
+
+func (c *AccountMockUseCase) ListTransfers(ctx context.Context, accountNumber int, param domain.TransferListParam) ([]domain.Transfer, int, error) {
+	args := c.Called(ctx, accountNumber, param)
+	result := args.Get(0)
+
+	return result.([]domain.Transfer), args.Int(1), args.Error(2)
+}
+
+func (c *AccountMockUseCase) ListLedgerEntries(ctx context.Context, accountNumber int, param domain.LedgerEntryListParam) ([]domain.LedgerEntry, int, error) {
+	args := c.Called(ctx, accountNumber, param)
+	result := args.Get(0)
+
+	return result.([]domain.LedgerEntry), args.Int(1), args.Error(2)
+}
+
+func (c *AccountMockUseCase) RebuildBalance(ctx context.Context, accountNumber int) (domain.RebuildBalanceResponse, error) {
+	args := c.Called(ctx, accountNumber)
+	result := args.Get(0)
+
+	return result.(domain.RebuildBalanceResponse), args.Error(1)
 }
 
 func (c *AccountMockUseCase) Login(ctx context.Context, param domain.AccountLoginParam) (domain.LoginResponse, error) {
@@ -56,3 +93,34 @@ func (c *AccountMockUseCase) Login(ctx context.Context, param domain.AccountLogi
 
 	return result.(domain.LoginResponse), args.Error(1)
 }
+
+func (c *AccountMockUseCase) LoginMFA(ctx context.Context, param domain.LoginMFAParam) (domain.LoginResponse, error) {
+	args := c.Called(ctx, param)
+	result := args.Get(0)
+
+	return result.(domain.LoginResponse), args.Error(1)
+}
+
+func (c *AccountMockUseCase) Unlock(ctx context.Context, accountNumber int) error {
+	args := c.Called(ctx, accountNumber)
+
+	return args.Error(0)
+}
+
+func (c *AccountMockUseCase) ChangePassword(ctx context.Context, accountNumber int, param domain.PasswordChangeParam) error {
+	args := c.Called(ctx, accountNumber, param)
+
+	return args.Error(0)
+}
+
+func (c *AccountMockUseCase) RequestPasswordReset(ctx context.Context, param domain.PasswordResetRequestParam) error {
+	args := c.Called(ctx, param)
+
+	return args.Error(0)
+}
+
+func (c *AccountMockUseCase) ResetPassword(ctx context.Context, param domain.PasswordResetParam) error {
+	args := c.Called(ctx, param)
+
+	return args.Error(0)
+}
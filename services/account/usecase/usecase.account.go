@@ -2,11 +2,18 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"net/mail"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/oniharnantyo/golang-backend-example/domain"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/pkg/errors"
@@ -14,27 +21,45 @@ import (
 )
 
 type accountUseCase struct {
-	authUseCase        domain.AuthUseCase
-	accountRepository  domain.AccountRepository
-	customerRepository domain.CustomerRepository
-	logger             *logrus.Logger
+	authUseCase             domain.AuthUseCase
+	accountRepository       domain.AccountRepository
+	customerRepository      domain.CustomerRepository
+	ledgerRepository        domain.LedgerRepository
+	transferRepository      domain.TransferRepository
+	transactionManager      domain.TransactionManager
+	loginAttemptRepository  domain.LoginAttemptRepository
+	loginPolicy             domain.LoginPolicy
+	passwordPolicy          domain.PasswordPolicy
+	passwordResetRepository domain.PasswordResetRepository
+	notifier                domain.Notifier
+	mfaUseCase              domain.MFAUseCase
+	fxRateUseCase           domain.FXRateUseCase
+	holdRepository          domain.HoldRepository
+	transferLimitUseCase    domain.TransferLimitUseCase
+	logger                  *logrus.Logger
 }
 
-func (c accountUseCase) List(ctx context.Context, param domain.AccountListParam) ([]domain.Account, error) {
+func (c accountUseCase) List(ctx context.Context, param domain.AccountListParam) ([]domain.Account, int, error) {
 	accounts, err := c.accountRepository.List(ctx, param)
 	if err != nil {
 		c.logger.Errorf("accountUseCase/List/List :%v", err)
-		return nil, errors.Wrap(err, "accountUseCase/List/List")
+		return nil, 0, errors.Wrap(err, "accountUseCase/List/List")
 	}
 
-	return accounts, nil
+	total, err := c.accountRepository.Count(ctx, param)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/List/Count :%v", err)
+		return nil, 0, errors.Wrap(err, "accountUseCase/List/Count")
+	}
+
+	return accounts, total, nil
 }
 
 func (c accountUseCase) GetByAccountNumber(ctx context.Context, accountNumber int) (domain.DetailByAccountNumberResponse, error) {
 	account, err := c.accountRepository.GetByAccountNumber(ctx, accountNumber)
 	if err != nil {
 		c.logger.Errorf("accountUseCase/GetByAccountNumber/GetByAccountNumber :%v", err)
-		return domain.DetailByAccountNumberResponse{}, err
+		return domain.DetailByAccountNumberResponse{}, accountNotFound(err)
 	}
 
 	customer, err := c.customerRepository.GetByCustomerNumber(ctx, account.CustomerNumber)
@@ -50,23 +75,98 @@ func (c accountUseCase) GetByAccountNumber(ctx context.Context, accountNumber in
 	}, nil
 }
 
-func (c accountUseCase) Store(ctx context.Context, a *domain.Account) error {
-	err := c.accountRepository.Store(ctx, a)
+// Register opens an account for a customer. The email is normalised and the
+// password has to meet the password policy before it is hashed.
+func (c accountUseCase) Register(ctx context.Context, param domain.AccountRegisterParam) (domain.Account, error) {
+	if param.Balance.Currency == "" {
+		param.Balance.Currency = domain.DefaultCurrency
+	}
+
+	err := param.Balance.Validate()
 	if err != nil {
-		c.logger.Errorf("accountUseCase/Store/Store :%v", err)
-		return err
+		return domain.Account{}, err
 	}
 
-	return nil
+	if param.Balance.IsNegative() {
+		return domain.Account{}, domain.ErrNegativeBalance
+	}
+
+	switch param.Role {
+	case "":
+		param.Role = domain.RoleCustomer
+	case domain.RoleCustomer, domain.RoleTeller, domain.RoleAdmin:
+	default:
+		return domain.Account{}, domain.ErrInvalidRole
+	}
+
+	email, err := normalizeEmail(param.Email)
+	if err != nil {
+		return domain.Account{}, err
+	}
+
+	err = c.passwordPolicy.Validate(param.Password)
+	if err != nil {
+		return domain.Account{}, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(param.Password), c.passwordPolicy.BcryptCost)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/Register/GenerateFromPassword :%v", err)
+		return domain.Account{}, err
+	}
+
+	account := domain.Account{
+		AccountNumber:  param.AccountNumber,
+		CustomerNumber: param.CustomerNumber,
+		Balance:        param.Balance,
+		Email:          email,
+		Password:       string(hash),
+		Role:           param.Role,
+	}
+
+	err = c.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := c.accountRepository.Store(ctx, &account)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Register/Store :%v", err)
+			return err
+		}
+
+		if account.Balance.IsZero() {
+			return nil
+		}
+
+		err = c.postLedger(ctx, uuid.New().String(), domain.LedgerTransactionOpening,
+			domain.LedgerExternalAccountNumber, account.AccountNumber, account.Balance)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Register/postLedger :%v", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.Account{}, err
+	}
+
+	return account, nil
 }
 
+// Update stores the customer number and the email of the account. The email
+// is normalised like in Store, so Login still finds it.
 func (c accountUseCase) Update(ctx context.Context, a *domain.Account) error {
-	err := c.accountRepository.Update(ctx, a)
+	email, err := normalizeEmail(a.Email)
 	if err != nil {
-		c.logger.Errorf("accountUseCase/Update/Update :%v", err)
 		return err
 	}
 
+	a.Email = email
+
+	err = c.accountRepository.Update(ctx, a)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/Update/Update :%v", err)
+		return accountNotFound(err)
+	}
+
 	return nil
 }
 
@@ -80,86 +180,724 @@ func (c accountUseCase) Delete(ctx context.Context, a *domain.Account) error {
 	return nil
 }
 
-func (c accountUseCase) Transfer(ctx context.Context, fromAccountNumber int, param domain.TransferParam) error {
-	senderAccount, err := c.accountRepository.GetByAccountNumber(ctx, fromAccountNumber)
+func (c accountUseCase) Transfer(ctx context.Context, fromAccountNumber int, param domain.TransferParam) (domain.Transfer, error) {
+	toAccountNumber, err := strconv.Atoi(param.ToAccountNumber)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/Transfer/parserToAccountNumber :%v", err)
+		return domain.Transfer{}, domain.ErrBadRequest
+	}
+
+	// Validate sending to the same account as the sender
+	if fromAccountNumber == toAccountNumber {
+		return domain.Transfer{}, domain.ErrSameAccountTransfer
+	}
+
+	err = param.Amount.Validate()
+	if err != nil {
+		return domain.Transfer{}, err
+	}
+
+	if !param.Amount.IsPositive() {
+		return domain.Transfer{}, domain.ErrInvalidAmount
+	}
+
+	now := time.Now().UTC()
+	transfer := domain.Transfer{
+		ID:                uuid.New().String(),
+		Type:              domain.TransferTypeTransfer,
+		FromAccountNumber: fromAccountNumber,
+		ToAccountNumber:   toAccountNumber,
+		Amount:            param.Amount,
+		ConvertedAmount:   param.Amount,
+		Status:            domain.TransferStatusCompleted,
+		Description:       param.Description,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	err = c.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The code is used up in the transaction, so it is given back when
+		// the transfer fails
+		if !domain.StepUpVerified(ctx) {
+			err := c.mfaUseCase.VerifyStepUp(ctx, fromAccountNumber, param.Amount, param.OTP)
+			if err != nil {
+				c.logger.Errorf("accountUseCase/Transfer/VerifyStepUp :%v", err)
+				return err
+			}
+		}
+
+		senderAccount, receiverAccount, err := c.lockTransferAccounts(ctx, fromAccountNumber, toAccountNumber)
+		if err != nil {
+			return err
+		}
+
+		// The amount is debited in the currency of the sender account
+		if senderAccount.Balance.Currency != param.Amount.Currency {
+			c.logger.Errorf("accountUseCase/Transfer/validateCurrency :%v", domain.ErrCurrencyMismatch)
+			return domain.ErrCurrencyMismatch
+		}
+
+		// The sender row lock keeps concurrent transfers from both passing
+		// the limits
+		err = c.transferLimitUseCase.Check(ctx, senderAccount, param.Amount)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Transfer/Check :%v", err)
+			return err
+		}
+
+		// and credited in the currency of the receiver account
+		if receiverAccount.Balance.Currency != param.Amount.Currency {
+			converted, rate, err := c.fxRateUseCase.Convert(ctx, param.Amount, receiverAccount.Balance.Currency)
+			if err != nil {
+				c.logger.Errorf("accountUseCase/Transfer/Convert :%v", err)
+				return err
+			}
+
+			// Too small to be worth a minor unit of the receiver currency
+			if !converted.IsPositive() {
+				return domain.ErrInvalidAmount
+			}
+
+			transfer.ConvertedAmount = converted
+			transfer.FXRate = rate.Rate
+		}
+
+		senderBalance, err := senderAccount.Balance.Sub(param.Amount)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Transfer/senderAccount/Sub :%v", err)
+			return err
+		}
+
+		// Validate sender account balance
+		err = c.ensureAvailable(ctx, senderAccount.AccountNumber, senderBalance)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Transfer/ensureAvailable :%v", err)
+			return err
+		}
+
+		receiverBalance, err := receiverAccount.Balance.Add(transfer.ConvertedAmount)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Transfer/receiverAccount/Add :%v", err)
+			return err
+		}
+
+		err = c.accountRepository.UpdateBalance(ctx, senderAccount.AccountNumber, senderBalance)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Transfer/senderAccount/UpdateBalance :%v", err)
+			return err
+		}
+
+		err = c.accountRepository.UpdateBalance(ctx, receiverAccount.AccountNumber, receiverBalance)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Transfer/receiverAccount/UpdateBalance :%v", err)
+			return err
+		}
+
+		err = c.transferRepository.Store(ctx, &transfer)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Transfer/Store :%v", err)
+			return err
+		}
+
+		err = c.postTransfer(ctx, transfer)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Transfer/postTransfer :%v", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.Transfer{}, err
+	}
+
+	return transfer, nil
+}
+
+func (c accountUseCase) Deposit(ctx context.Context, accountNumber int, param domain.MovementParam) (domain.Transfer, error) {
+	return c.move(ctx, domain.TransferTypeDeposit, accountNumber, param)
+}
+
+func (c accountUseCase) Withdraw(ctx context.Context, accountNumber int, param domain.MovementParam) (domain.Transfer, error) {
+	return c.move(ctx, domain.TransferTypeWithdrawal, accountNumber, param)
+}
+
+// move deposits to or withdraws from the account. It is stored and booked
+// like a transfer, with the external account on the other side.
+func (c accountUseCase) move(ctx context.Context, transferType string, accountNumber int, param domain.MovementParam) (domain.Transfer, error) {
+	err := param.Amount.Validate()
 	if err != nil {
-		c.logger.Errorf("accountUseCase/Transfer/GetSenderAccountByAccountNumber :%v", err)
-		if errors.Cause(err) == sql.ErrNoRows {
-			return errors.New("Sender account not found")
+		return domain.Transfer{}, err
+	}
+
+	if !param.Amount.IsPositive() {
+		return domain.Transfer{}, domain.ErrInvalidAmount
+	}
+
+	now := time.Now().UTC()
+	transfer := domain.Transfer{
+		ID:                uuid.New().String(),
+		Type:              transferType,
+		FromAccountNumber: domain.LedgerExternalAccountNumber,
+		ToAccountNumber:   accountNumber,
+		Amount:            param.Amount,
+		ConvertedAmount:   param.Amount,
+		Status:            domain.TransferStatusCompleted,
+		Description:       param.Description,
+		Reference:         param.Reference,
+		Channel:           param.Channel,
+		ReasonCode:        param.ReasonCode,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	ledgerTransactionType := domain.LedgerTransactionDeposit
+	if transferType == domain.TransferTypeWithdrawal {
+		transfer.FromAccountNumber, transfer.ToAccountNumber = accountNumber, domain.LedgerExternalAccountNumber
+		ledgerTransactionType = domain.LedgerTransactionWithdrawal
+	}
+
+	err = c.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		account, err := c.accountRepository.GetByAccountNumberForUpdate(ctx, accountNumber)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/move/GetByAccountNumberForUpdate :%v", err)
+			return accountNotFound(err)
+		}
+
+		if account.Balance.Currency != param.Amount.Currency {
+			c.logger.Errorf("accountUseCase/move/validateCurrency :%v", domain.ErrCurrencyMismatch)
+			return domain.ErrCurrencyMismatch
+		}
+
+		var balance domain.Money
+		if transferType == domain.TransferTypeWithdrawal {
+			balance, err = account.Balance.Sub(param.Amount)
+			if err != nil {
+				c.logger.Errorf("accountUseCase/move/Sub :%v", err)
+				return err
+			}
+
+			err = c.ensureAvailable(ctx, accountNumber, balance)
+			if err != nil {
+				c.logger.Errorf("accountUseCase/move/ensureAvailable :%v", err)
+				return err
+			}
+		} else {
+			balance, err = account.Balance.Add(param.Amount)
+			if err != nil {
+				c.logger.Errorf("accountUseCase/move/Add :%v", err)
+				return err
+			}
 		}
+
+		err = c.accountRepository.UpdateBalance(ctx, accountNumber, balance)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/move/UpdateBalance :%v", err)
+			return err
+		}
+
+		err = c.transferRepository.Store(ctx, &transfer)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/move/Store :%v", err)
+			return err
+		}
+
+		err = c.postLedger(ctx, transfer.ID, ledgerTransactionType,
+			transfer.FromAccountNumber, transfer.ToAccountNumber, transfer.Amount)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/move/postLedger :%v", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.Transfer{}, err
+	}
+
+	return transfer, nil
+}
+
+// ensureAvailable fails with ErrInsufficientBalance when balance, what is left
+// on the account after a debit, no longer covers its active holds.
+func (c accountUseCase) ensureAvailable(ctx context.Context, accountNumber int, balance domain.Money) error {
+	held, err := c.holdRepository.TotalActive(ctx, accountNumber)
+	if err != nil {
 		return err
 	}
 
-	toAccountNumber, err := strconv.Atoi(param.ToAccountNumber)
+	if balance.Amount < held {
+		return domain.ErrInsufficientBalance
+	}
+
+	return nil
+}
+
+func (c accountUseCase) ListTransfers(ctx context.Context, accountNumber int, param domain.TransferListParam) ([]domain.Transfer, int, error) {
+	transfers, err := c.transferRepository.ListByAccountNumber(ctx, accountNumber, param)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/ListTransfers/ListByAccountNumber :%v", err)
+		return nil, 0, err
+	}
+
+	total, err := c.transferRepository.CountByAccountNumber(ctx, accountNumber, param)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/ListTransfers/CountByAccountNumber :%v", err)
+		return nil, 0, err
+	}
+
+	return transfers, total, nil
+}
+
+func (c accountUseCase) ListLedgerEntries(ctx context.Context, accountNumber int, param domain.LedgerEntryListParam) ([]domain.LedgerEntry, int, error) {
+	entries, err := c.ledgerRepository.ListByAccountNumber(ctx, accountNumber, param)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/ListLedgerEntries/ListByAccountNumber :%v", err)
+		return nil, 0, err
+	}
+
+	total, err := c.ledgerRepository.CountByAccountNumber(ctx, accountNumber)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/ListLedgerEntries/CountByAccountNumber :%v", err)
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// RebuildBalance recomputes the account balance from its ledger entries and
+// overwrites the stored balance when the two have drifted apart.
+func (c accountUseCase) RebuildBalance(ctx context.Context, accountNumber int) (domain.RebuildBalanceResponse, error) {
+	var response domain.RebuildBalanceResponse
+
+	err := c.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		account, err := c.accountRepository.GetByAccountNumberForUpdate(ctx, accountNumber)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/RebuildBalance/GetByAccountNumberForUpdate :%v", err)
+			return accountNotFound(err)
+		}
+
+		amount, err := c.ledgerRepository.GetBalance(ctx, accountNumber)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/RebuildBalance/GetBalance :%v", err)
+			return err
+		}
+
+		balance := domain.NewMoney(amount, account.Balance.Currency)
+
+		response = domain.RebuildBalanceResponse{
+			AccountNumber:   account.AccountNumber,
+			PreviousBalance: account.Balance,
+			Balance:         balance,
+		}
+
+		if account.Balance == balance {
+			return nil
+		}
+
+		c.logger.Warnf("accountUseCase/RebuildBalance :account %d balance %s does not match ledger balance %s",
+			account.AccountNumber, account.Balance, balance)
+
+		err = c.accountRepository.UpdateBalance(ctx, account.AccountNumber, balance)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/RebuildBalance/UpdateBalance :%v", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.RebuildBalanceResponse{}, err
+	}
+
+	return response, nil
+}
+
+// postLedger books amount moving out of debitAccountNumber into
+// creditAccountNumber as one balanced pair of entries.
+func (c accountUseCase) postLedger(ctx context.Context, transactionID, transactionType string, debitAccountNumber, creditAccountNumber int, amount domain.Money) error {
+	return c.ledgerRepository.Store(ctx, []domain.LedgerEntry{
+		{
+			TransactionID:   transactionID,
+			TransactionType: transactionType,
+			AccountNumber:   debitAccountNumber,
+			EntryType:       domain.LedgerEntryDebit,
+			Amount:          amount,
+		},
+		{
+			TransactionID:   transactionID,
+			TransactionType: transactionType,
+			AccountNumber:   creditAccountNumber,
+			EntryType:       domain.LedgerEntryCredit,
+			Amount:          amount,
+		},
+	})
+}
+
+// postTransfer books a transfer within a currency as one pair of entries. A
+// conversion leaves the bank in the currency of the sender and enters it in
+// the currency of the receiver, so each currency is booked as its own pair
+// against the external account.
+func (c accountUseCase) postTransfer(ctx context.Context, transfer domain.Transfer) error {
+	if transfer.ConvertedAmount == transfer.Amount {
+		return c.postLedger(ctx, transfer.ID, domain.LedgerTransactionTransfer,
+			transfer.FromAccountNumber, transfer.ToAccountNumber, transfer.Amount)
+	}
+
+	err := c.postLedger(ctx, transfer.ID, domain.LedgerTransactionTransfer,
+		transfer.FromAccountNumber, domain.LedgerExternalAccountNumber, transfer.Amount)
+	if err != nil {
+		return err
+	}
+
+	return c.postLedger(ctx, transfer.ID, domain.LedgerTransactionTransfer,
+		domain.LedgerExternalAccountNumber, transfer.ToAccountNumber, transfer.ConvertedAmount)
+}
+
+// lockTransferAccounts takes the row locks of both accounts in ascending account
+// number order, so two transfers between the same pair can never deadlock.
+func (c accountUseCase) lockTransferAccounts(ctx context.Context, fromAccountNumber, toAccountNumber int) (domain.Account, domain.Account, error) {
+	lockOrder := []int{fromAccountNumber, toAccountNumber}
+	if toAccountNumber < fromAccountNumber {
+		lockOrder = []int{toAccountNumber, fromAccountNumber}
+	}
+
+	locked := make(map[int]domain.Account, len(lockOrder))
+	for _, accountNumber := range lockOrder {
+		account, err := c.accountRepository.GetByAccountNumberForUpdate(ctx, accountNumber)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Transfer/GetByAccountNumberForUpdate :%v", err)
+			if errors.Cause(err) == sql.ErrNoRows {
+				if accountNumber == fromAccountNumber {
+					return domain.Account{}, domain.Account{}, domain.ErrSenderAccountNotFound
+				}
+				return domain.Account{}, domain.Account{}, domain.ErrReceiverAccountNotFound
+			}
+			return domain.Account{}, domain.Account{}, err
+		}
+
+		locked[accountNumber] = account
+	}
+
+	return locked[fromAccountNumber], locked[toAccountNumber], nil
+}
+
+// dummyPasswordHash is compared against when the email is unknown, so both
+// failure cases take as long as a real password check.
+const dummyPasswordHash = "$2y$12$55Pvvir6aXTbi3tE5toEyuUMgPCJ1uytiVREzrSHDgXoNFva7kLOK"
+
+func (c accountUseCase) Login(ctx context.Context, param domain.AccountLoginParam) (domain.LoginResponse, error) {
+	emailSubject := loginEmailSubject(param.Email)
+	ipSubject := "ip:" + param.IP
+
+	for _, subject := range []string{emailSubject, ipSubject} {
+		blockedFor, err := c.loginAttemptRepository.BlockedFor(ctx, subject)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Login/BlockedFor :%v", err)
+			return domain.LoginResponse{}, err
+		}
+
+		if blockedFor > 0 {
+			return domain.LoginResponse{}, domain.LoginThrottledError{RetryAfter: blockedFor}
+		}
+	}
+
+	account, err := c.accountRepository.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(param.Email)))
+	if err != nil && errors.Cause(err) != sql.ErrNoRows {
+		c.logger.Errorf("accountUseCase/Login/GetByEmail :%v", err)
+		return domain.LoginResponse{}, err
+	}
+
+	hash := account.Password
+	if err != nil {
+		hash = dummyPasswordHash
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(param.Password)) != nil || err != nil {
+		err = c.registerLoginFailure(ctx, emailSubject, ipSubject)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Login/registerLoginFailure :%v", err)
+			return domain.LoginResponse{}, err
+		}
+
+		return domain.LoginResponse{}, domain.ErrInvalidCredentials
+	}
+
+	err = c.loginAttemptRepository.Reset(ctx, emailSubject)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/Login/Reset :%v", err)
+		return domain.LoginResponse{}, err
+	}
+
+	mfaEnabled, err := c.mfaUseCase.Enabled(ctx, account.AccountNumber)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/Login/Enabled :%v", err)
+		return domain.LoginResponse{}, err
+	}
+
+	// The password alone only buys a challenge, LoginMFA issues the tokens.
+	if mfaEnabled {
+		mfaToken, err := c.mfaUseCase.CreateChallenge(ctx, account.AccountNumber)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/Login/CreateChallenge :%v", err)
+			return domain.LoginResponse{}, err
+		}
+
+		return domain.LoginResponse{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	token, err := c.authUseCase.CreateAuth(ctx, account, param.UserAgent)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/Login/CreateAuth :%v", err)
+		return domain.LoginResponse{}, err
+	}
+
+	return domain.LoginResponse{Token: token.AccessToken, RefreshToken: token.RefreshToken}, nil
+}
+
+// LoginMFA finishes a login with the challenge from Login and a second factor
+// code. A challenge is used up by the first attempt, a wrong code counts as a
+// failed login and sends the caller back to the password step.
+func (c accountUseCase) LoginMFA(ctx context.Context, param domain.LoginMFAParam) (domain.LoginResponse, error) {
+	accountNumber, err := c.mfaUseCase.ConsumeChallenge(ctx, param.MFAToken)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/LoginMFA/ConsumeChallenge :%v", err)
+		return domain.LoginResponse{}, err
+	}
+
+	account, err := c.accountRepository.GetByAccountNumber(ctx, accountNumber)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/LoginMFA/GetByAccountNumber :%v", err)
+		return domain.LoginResponse{}, err
+	}
+
+	err = c.mfaUseCase.Verify(ctx, accountNumber, param.Code)
+	if errors.Cause(err) == domain.ErrInvalidMFACode {
+		err = c.registerLoginFailure(ctx, loginEmailSubject(account.Email), "ip:"+param.IP)
+		if err != nil {
+			c.logger.Errorf("accountUseCase/LoginMFA/registerLoginFailure :%v", err)
+			return domain.LoginResponse{}, err
+		}
+
+		return domain.LoginResponse{}, domain.ErrInvalidMFACode
+	}
+	if err != nil {
+		c.logger.Errorf("accountUseCase/LoginMFA/Verify :%v", err)
+		return domain.LoginResponse{}, err
+	}
+
+	token, err := c.authUseCase.CreateAuth(ctx, account, param.UserAgent)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/LoginMFA/CreateAuth :%v", err)
+		return domain.LoginResponse{}, err
+	}
+
+	return domain.LoginResponse{Token: token.AccessToken, RefreshToken: token.RefreshToken}, nil
+}
+
+// registerLoginFailure counts a failed login for the email and the IP. The
+// email has to wait longer after every failure and is locked once it reaches
+// the limit, the IP is only locked at its own, higher limit.
+func (c accountUseCase) registerLoginFailure(ctx context.Context, emailSubject, ipSubject string) error {
+	failures, err := c.loginAttemptRepository.RegisterFailure(ctx, emailSubject, c.loginPolicy.FailureWindow)
 	if err != nil {
-		c.logger.Errorf("accountUseCase/Transfer/parserToAccountNumber :%v", err)
 		return err
 	}
 
-	receiverAccount, err := c.accountRepository.GetByAccountNumber(ctx, toAccountNumber)
+	block := c.loginPolicy.LockDuration
+	if failures < int64(c.loginPolicy.MaxFailures) {
+		block = c.loginPolicy.BaseDelay
+		for i := int64(1); i < failures && block < c.loginPolicy.MaxDelay; i++ {
+			block *= 2
+		}
+		if block > c.loginPolicy.MaxDelay {
+			block = c.loginPolicy.MaxDelay
+		}
+	}
+
+	if block > 0 {
+		err = c.loginAttemptRepository.Block(ctx, emailSubject, block)
+		if err != nil {
+			return err
+		}
+	}
+
+	ipFailures, err := c.loginAttemptRepository.RegisterFailure(ctx, ipSubject, c.loginPolicy.FailureWindow)
 	if err != nil {
-		c.logger.Errorf("accountUseCase/Transfer/GetByReceiverAccountAccountNumber :%v", err)
-		if errors.Cause(err) == sql.ErrNoRows {
-			return errors.New("Receiver account not found")
+		return err
+	}
+
+	if ipFailures >= int64(c.loginPolicy.MaxIPFailures) {
+		err = c.loginAttemptRepository.Block(ctx, ipSubject, c.loginPolicy.LockDuration)
+		if err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// Unlock lifts the login lock of the account before it runs out. The lock of
+// an IP that reached its own limit stays, it is not tied to one account.
+func (c accountUseCase) Unlock(ctx context.Context, accountNumber int) error {
+	account, err := c.accountRepository.GetByAccountNumber(ctx, accountNumber)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/Unlock/GetByAccountNumber :%v", err)
+		return accountNotFound(err)
+	}
+
+	err = c.loginAttemptRepository.Reset(ctx, loginEmailSubject(account.Email))
+	if err != nil {
+		c.logger.Errorf("accountUseCase/Unlock/Reset :%v", err)
 		return err
 	}
 
-	// Validate sending to the same account as the sender
-	if senderAccount.AccountNumber == receiverAccount.AccountNumber {
-		return errors.New("Sender and receiver is same account")
+	return nil
+}
+
+// ChangePassword replaces the password of the account after checking the
+// current one. Every session of the account is revoked afterwards.
+func (c accountUseCase) ChangePassword(ctx context.Context, accountNumber int, param domain.PasswordChangeParam) error {
+	account, err := c.accountRepository.GetByAccountNumber(ctx, accountNumber)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/ChangePassword/GetByAccountNumber :%v", err)
+		return accountNotFound(err)
 	}
 
-	// Validate sender account balance
-	if senderAccount.Balance < param.Amount {
-		c.logger.Errorf("accountUseCase/Transfer/validateBalance :%v", errors.New("Insufficient balance"))
-		return errors.New("Insufficient balance")
+	if bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(param.OldPassword)) != nil {
+		return domain.ErrWrongPassword
 	}
 
-	senderAccount.Balance = senderAccount.Balance - param.Amount
-	err = c.accountRepository.Update(ctx, &senderAccount)
+	return c.setPassword(ctx, accountNumber, param.NewPassword)
+}
+
+// RequestPasswordReset sends a single use reset token to the email. Unknown
+// emails are ignored silently, so the call does not reveal which emails are
+// registered.
+func (c accountUseCase) RequestPasswordReset(ctx context.Context, param domain.PasswordResetRequestParam) error {
+	account, err := c.accountRepository.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(param.Email)))
+	if errors.Cause(err) == sql.ErrNoRows {
+		return nil
+	}
 	if err != nil {
-		c.logger.Errorf("accountUseCase/Transfer/senderAccount/BeginTx :%v", err)
+		c.logger.Errorf("accountUseCase/RequestPasswordReset/GetByEmail :%v", err)
 		return err
 	}
 
-	receiverAccount.Balance = receiverAccount.Balance + param.Amount
-	err = c.accountRepository.Update(ctx, &receiverAccount)
+	b := make([]byte, 32)
+	_, err = rand.Read(b)
 	if err != nil {
-		c.logger.Errorf("accountUseCase/Transfer/receiverAccount/UpdateTx :%v", err)
+		c.logger.Errorf("accountUseCase/RequestPasswordReset/Read :%v", err)
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	err = c.passwordResetRepository.Store(ctx, token, account.AccountNumber, c.passwordPolicy.ResetTokenExpire)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/RequestPasswordReset/Store :%v", err)
+		return err
+	}
+
+	err = c.notifier.Notify(ctx, account.Email, "Password reset",
+		fmt.Sprintf("Use this token to reset your password within %s: %s", c.passwordPolicy.ResetTokenExpire, token))
+	if err != nil {
+		c.logger.Errorf("accountUseCase/RequestPasswordReset/Notify :%v", err)
 		return err
 	}
 
 	return nil
 }
 
-func (c accountUseCase) Login(ctx context.Context, param domain.AccountLoginParam) (domain.LoginResponse, error) {
-	account, err := c.accountRepository.GetByEmail(ctx, param.Email)
+// ResetPassword sets a new password with a token from RequestPasswordReset.
+// Every session of the account is revoked afterwards.
+func (c accountUseCase) ResetPassword(ctx context.Context, param domain.PasswordResetParam) error {
+	// Checked before the token is consumed, so a weak password does not waste
+	// the token.
+	err := c.passwordPolicy.Validate(param.NewPassword)
 	if err != nil {
-		c.logger.Errorf("accountUseCase/Transfer/receiverAccount/GetByEmail :%v", err)
-		return domain.LoginResponse{}, err
+		return err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(param.Password))
+	accountNumber, err := c.passwordResetRepository.Consume(ctx, param.Token)
 	if err != nil {
-		c.logger.Errorf("accountUseCase/Transfer/receiverAccount/CompareHashAndPassword :%v", err)
-		return domain.LoginResponse{}, err
+		c.logger.Errorf("accountUseCase/ResetPassword/Consume :%v", err)
+		return err
 	}
 
-	token, err := c.authUseCase.CreateAuth(ctx, account)
+	return c.setPassword(ctx, accountNumber, param.NewPassword)
+}
+
+// setPassword hashes and stores a new password, then revokes the sessions of
+// the account so tokens issued with the old password stop working.
+func (c accountUseCase) setPassword(ctx context.Context, accountNumber int, password string) error {
+	err := c.passwordPolicy.Validate(password)
 	if err != nil {
-		c.logger.Errorf("accountUseCase/Transfer/receiverAccount/CreateToken :%v", err)
-		return domain.LoginResponse{}, err
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), c.passwordPolicy.BcryptCost)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/setPassword/GenerateFromPassword :%v", err)
+		return err
+	}
+
+	err = c.accountRepository.UpdatePassword(ctx, accountNumber, string(hash))
+	if err != nil {
+		c.logger.Errorf("accountUseCase/setPassword/UpdatePassword :%v", err)
+		return err
+	}
+
+	err = c.authUseCase.LogoutAll(ctx, accountNumber)
+	if err != nil {
+		c.logger.Errorf("accountUseCase/setPassword/LogoutAll :%v", err)
+		return err
+	}
+
+	return nil
+}
+
+// accountNotFound turns a missing row into ErrAccountNotFound.
+func accountNotFound(err error) error {
+	if errors.Cause(err) == sql.ErrNoRows {
+		return domain.ErrAccountNotFound
+	}
+	return err
+}
+
+func loginEmailSubject(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// normalizeEmail trims and lower cases the email, emails are compared case
+// insensitively by the email_unique constraint and by Login.
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", domain.ErrInvalidEmail
 	}
 
-	return domain.LoginResponse{token.AccessToken}, nil
+	return email, nil
 }
 
-func NewAccountUseCase(au domain.AuthUseCase, a domain.AccountRepository, c domain.CustomerRepository, log *logrus.Logger) domain.AccountUseCase {
+func NewAccountUseCase(au domain.AuthUseCase, a domain.AccountRepository, c domain.CustomerRepository, l domain.LedgerRepository, t domain.TransferRepository, tm domain.TransactionManager, la domain.LoginAttemptRepository, lp domain.LoginPolicy, pp domain.PasswordPolicy, pr domain.PasswordResetRepository, n domain.Notifier, mfa domain.MFAUseCase, fx domain.FXRateUseCase, h domain.HoldRepository, tl domain.TransferLimitUseCase, log *logrus.Logger) domain.AccountUseCase {
 	return &accountUseCase{
-		authUseCase:        au,
-		accountRepository:  a,
-		customerRepository: c,
-		logger:             log,
+		authUseCase:             au,
+		accountRepository:       a,
+		customerRepository:      c,
+		ledgerRepository:        l,
+		transferRepository:      t,
+		transactionManager:      tm,
+		loginAttemptRepository:  la,
+		loginPolicy:             lp,
+		passwordPolicy:          pp,
+		passwordResetRepository: pr,
+		notifier:                n,
+		mfaUseCase:              mfa,
+		fxRateUseCase:           fx,
+		holdRepository:          h,
+		transferLimitUseCase:    tl,
+		logger:                  log,
 	}
 }
@@ -4,18 +4,30 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
+	database_mock "github.com/oniharnantyo/golang-backend-example/database/mock"
+	notifier_mock "github.com/oniharnantyo/golang-backend-example/notifier/mock"
 	auth_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/auth/usecase/mock"
 
 	"github.com/oniharnantyo/golang-backend-example/domain"
 	repository_account_mock "github.com/oniharnantyo/golang-backend-example/services/account/repository/mock"
 	repository_customer_mock "github.com/oniharnantyo/golang-backend-example/services/customer/repository/mock"
+	fxrate_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/fxrate/usecase/mock"
+	repository_hold_mock "github.com/oniharnantyo/golang-backend-example/services/hold/repository/mock"
+	repository_ledger_mock "github.com/oniharnantyo/golang-backend-example/services/ledger/repository/mock"
+	loginattempt_repository_mock "github.com/oniharnantyo/golang-backend-example/services/loginattempt/repository/mock"
+	mfa_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/mfa/usecase/mock"
+	passwordreset_repository_mock "github.com/oniharnantyo/golang-backend-example/services/passwordreset/repository/mock"
+	repository_transfer_mock "github.com/oniharnantyo/golang-backend-example/services/transfer/repository/mock"
+	transferlimit_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/transferlimit/usecase/mock"
 
 	"github.com/pkg/errors"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -25,37 +37,84 @@ var (
 	AccessSecretExpireAfterMinute int    = 15
 	RefreshSecret                 string = "refresh"
 	RefreshSecretExpireAfterDay   int    = 30
+
+	loginPolicy = domain.LoginPolicy{
+		MaxFailures:   5,
+		MaxIPFailures: 20,
+		FailureWindow: 15 * time.Minute,
+		LockDuration:  15 * time.Minute,
+		BaseDelay:     time.Second,
+		MaxDelay:      8 * time.Second,
+	}
+
+	passwordPolicy = domain.PasswordPolicy{
+		MinLength:    10,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+		BcryptCost:   bcrypt.MinCost,
+	}
 )
 
+// noMFA stands in for accounts without a second factor and transfers below
+// the step-up amount.
+func noMFA() *mfa_usecase_mock.MFAMockUseCase {
+	mockMFAUseCase := new(mfa_usecase_mock.MFAMockUseCase)
+	mockMFAUseCase.On("Enabled", mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	mockMFAUseCase.On("VerifyStepUp", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	return mockMFAUseCase
+}
+
+func noHolds() *repository_hold_mock.HoldMockRepository {
+	mockHoldRepo := new(repository_hold_mock.HoldMockRepository)
+	mockHoldRepo.On("TotalActive", mock.Anything, mock.Anything).Return(int64(0), nil).Maybe()
+
+	return mockHoldRepo
+}
+
+func noLimits() *transferlimit_usecase_mock.TransferLimitMockUseCase {
+	mockTransferLimitUseCase := new(transferlimit_usecase_mock.TransferLimitMockUseCase)
+	mockTransferLimitUseCase.On("Check", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	return mockTransferLimitUseCase
+}
+
 func TestAccountUseCase_List(t *testing.T) {
 	logger := logrus.New()
 
 	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
 	mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+	mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+	mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
 
 	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockTransaction := new(database_mock.TransactionMockManager)
+	mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
 
 	customersData := []domain.Account{
 		{
 			AccountNumber:  555001,
 			CustomerNumber: 1001,
-			Balance:        10000,
+			Balance:        domain.NewMoney(10000, "IDR"),
 		},
 		{
 			AccountNumber:  555002,
 			CustomerNumber: 1002,
-			Balance:        15000,
+			Balance:        domain.NewMoney(15000, "IDR"),
 		},
 	}
 
 	t.Run("Success", func(t *testing.T) {
 		mockAccountRepo.On("List", mock.Anything, mock.AnythingOfType("domain.AccountListParam")).Return(customersData, nil).Once()
+		mockAccountRepo.On("Count", mock.Anything, mock.AnythingOfType("domain.AccountListParam")).Return(2, nil).Once()
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
 
-		cDatas, err := customerUseCase.List(context.Background(), domain.AccountListParam{})
+		cDatas, total, err := customerUseCase.List(context.Background(), domain.AccountListParam{})
 		assert.NoError(t, err)
 		assert.NotNil(t, cDatas)
+		assert.Equal(t, 2, total)
 
 		mockAccountRepo.AssertExpectations(t)
 	})
@@ -63,9 +122,9 @@ func TestAccountUseCase_List(t *testing.T) {
 	t.Run("Failed", func(t *testing.T) {
 		mockAccountRepo.On("List", mock.Anything, mock.AnythingOfType("domain.AccountListParam")).Return([]domain.Account{}, errors.New("Unexpected")).Once()
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
 
-		cDatas, err := customerUseCase.List(context.Background(), domain.AccountListParam{})
+		cDatas, _, err := customerUseCase.List(context.Background(), domain.AccountListParam{})
 		assert.Error(t, err)
 		assert.Nil(t, cDatas)
 
@@ -79,13 +138,17 @@ func TestAccountUseCase_GetByAccountNumber(t *testing.T) {
 
 	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
 	mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+	mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+	mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
 
 	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockTransaction := new(database_mock.TransactionMockManager)
+	mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
 
 	accountData := domain.Account{
 		AccountNumber:  555001,
 		CustomerNumber: 1001,
-		Balance:        10000,
+		Balance:        domain.NewMoney(10000, "IDR"),
 	}
 
 	customerData := domain.Customer{
@@ -97,7 +160,7 @@ func TestAccountUseCase_GetByAccountNumber(t *testing.T) {
 		mockAccountRepo.On("GetByAccountNumber", mock.Anything, mock.AnythingOfType("int")).Return(accountData, nil).Once()
 		mockCustomerRepo.On("GetByCustomerNumber", mock.Anything, mock.AnythingOfType("int")).Return(customerData, nil).Once()
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
 
 		cData, err := customerUseCase.GetByAccountNumber(context.Background(), 1001)
 		assert.NoError(t, err)
@@ -110,7 +173,7 @@ func TestAccountUseCase_GetByAccountNumber(t *testing.T) {
 		mockAccountRepo.On("GetByAccountNumber", mock.Anything, mock.AnythingOfType("int")).Return(domain.Account{}, sql.ErrNoRows).Once()
 		mockCustomerRepo.On("GetByCustomerNumber", mock.Anything, mock.AnythingOfType("int")).Return(domain.Customer{}, nil).Once()
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
 
 		cData, err := customerUseCase.GetByAccountNumber(context.Background(), 0)
 		assert.Error(t, err)
@@ -120,38 +183,91 @@ func TestAccountUseCase_GetByAccountNumber(t *testing.T) {
 	})
 }
 
-func TestAccountUseCase_Store(t *testing.T) {
+func TestAccountUseCase_Register(t *testing.T) {
 	logger := logrus.New()
 
 	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
 	mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+	mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+	mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
 
 	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockTransaction := new(database_mock.TransactionMockManager)
+	mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
 
-	accountData := domain.Account{
+	param := domain.AccountRegisterParam{
 		AccountNumber:  555001,
 		CustomerNumber: 1001,
-		Balance:        10000,
+		Balance:        domain.NewMoney(10000, "IDR"),
+		Email:          " Mail@Email.com ",
+		Password:       "Str0ngPassword",
 	}
 
 	t.Run("Success", func(t *testing.T) {
-		mockAccountRepo.On("Store", mock.Anything, mock.AnythingOfType("*domain.Account")).Return(nil).Once()
-
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
-
-		err := customerUseCase.Store(context.Background(), &accountData)
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("Store", mock.Anything, mock.MatchedBy(func(a *domain.Account) bool {
+			return a.Email == "mail@email.com" &&
+				bcrypt.CompareHashAndPassword([]byte(a.Password), []byte(param.Password)) == nil
+		})).Return(nil).Once()
+		mockLedgerRepo.On("Store", mock.Anything, mock.MatchedBy(func(entries []domain.LedgerEntry) bool {
+			return len(entries) == 2 &&
+				entries[0].AccountNumber == domain.LedgerExternalAccountNumber &&
+				entries[0].EntryType == domain.LedgerEntryDebit &&
+				entries[1].AccountNumber == param.AccountNumber &&
+				entries[1].EntryType == domain.LedgerEntryCredit &&
+				entries[1].Amount == param.Balance
+		})).Return(nil).Once()
+
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		account, err := customerUseCase.Register(context.Background(), param)
 		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleCustomer, account.Role)
+		assert.Equal(t, "mail@email.com", account.Email)
 
 		mockAccountRepo.AssertExpectations(t)
+		mockLedgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid-role", func(t *testing.T) {
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		invalid := param
+		invalid.Role = "root"
+		_, err := customerUseCase.Register(context.Background(), invalid)
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid-email", func(t *testing.T) {
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		invalid := param
+		invalid.Email = "Mail <mail@email.com>"
+		_, err := customerUseCase.Register(context.Background(), invalid)
+		assert.Equal(t, domain.ErrInvalidEmail, err)
+	})
+
+	t.Run("Weak-password", func(t *testing.T) {
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		weak := param
+		weak.Password = "password"
+		_, err := customerUseCase.Register(context.Background(), weak)
+
+		policyErr, ok := err.(*domain.Error)
+		assert.True(t, ok)
+		assert.Equal(t, "weak_password", policyErr.Code)
+		assert.Len(t, policyErr.Details, 3)
 	})
 
 	t.Run("Failed", func(t *testing.T) {
-		mockAccountRepo.On("Store", mock.Anything, mock.AnythingOfType("*domain.Account")).Return(errors.New("Unexpected")).Once()
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("Store", mock.Anything, mock.AnythingOfType("*domain.Account")).Return(domain.ErrEmailTaken).Once()
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
 
-		err := customerUseCase.Store(context.Background(), &accountData)
-		assert.Error(t, err)
+		_, err := customerUseCase.Register(context.Background(), param)
+		assert.Equal(t, domain.ErrEmailTaken, errors.Cause(err))
 
 		mockAccountRepo.AssertExpectations(t)
 	})
@@ -162,19 +278,26 @@ func TestAccountUseCase_Update(t *testing.T) {
 
 	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
 	mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+	mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+	mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
 
 	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockTransaction := new(database_mock.TransactionMockManager)
+	mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
 
 	customerData := domain.Account{
 		AccountNumber:  555001,
 		CustomerNumber: 1001,
-		Balance:        10000,
+		Balance:        domain.NewMoney(10000, "IDR"),
+		Email:          " Bob@Mail.com ",
 	}
 
 	t.Run("Success", func(t *testing.T) {
-		mockAccountRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Account")).Return(nil).Once()
+		mockAccountRepo.On("Update", mock.Anything, mock.MatchedBy(func(a *domain.Account) bool {
+			return a.Email == "bob@mail.com"
+		})).Return(nil).Once()
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
 
 		err := customerUseCase.Update(context.Background(), &customerData)
 		assert.NoError(t, err)
@@ -185,13 +308,35 @@ func TestAccountUseCase_Update(t *testing.T) {
 	t.Run("Failed", func(t *testing.T) {
 		mockAccountRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Account")).Return(errors.New("Unexpected")).Once()
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
 
 		err := customerUseCase.Update(context.Background(), &customerData)
 		assert.Error(t, err)
 
 		mockAccountRepo.AssertExpectations(t)
 	})
+
+	t.Run("Not-found", func(t *testing.T) {
+		mockAccountRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Account")).Return(sql.ErrNoRows).Once()
+
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		err := customerUseCase.Update(context.Background(), &customerData)
+		assert.Equal(t, domain.ErrAccountNotFound, err)
+
+		mockAccountRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid-email", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		err := customerUseCase.Update(context.Background(), &domain.Account{AccountNumber: 555001, CustomerNumber: 1001, Email: "not-an-email"})
+		assert.Equal(t, domain.ErrInvalidEmail, err)
+
+		mockAccountRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
 }
 
 func TestAccountUseCase_Delete(t *testing.T) {
@@ -199,19 +344,23 @@ func TestAccountUseCase_Delete(t *testing.T) {
 
 	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
 	mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+	mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+	mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
 
 	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockTransaction := new(database_mock.TransactionMockManager)
+	mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
 
 	customerData := domain.Account{
 		AccountNumber:  555001,
 		CustomerNumber: 1001,
-		Balance:        10000,
+		Balance:        domain.NewMoney(10000, "IDR"),
 	}
 
 	t.Run("Success", func(t *testing.T) {
 		mockAccountRepo.On("Delete", mock.Anything, mock.AnythingOfType("*domain.Account")).Return(nil).Once()
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
 
 		err := customerUseCase.Delete(context.Background(), &customerData)
 		assert.NoError(t, err)
@@ -222,7 +371,7 @@ func TestAccountUseCase_Delete(t *testing.T) {
 	t.Run("Failed", func(t *testing.T) {
 		mockAccountRepo.On("Delete", mock.Anything, mock.AnythingOfType("*domain.Account")).Return(errors.New("Unexpected")).Once()
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
 
 		err := customerUseCase.Delete(context.Background(), &customerData)
 		assert.Error(t, err)
@@ -234,121 +383,1178 @@ func TestAccountUseCase_Delete(t *testing.T) {
 func TestAccountUseCase_Transfer(t *testing.T) {
 	logger := logrus.New()
 
-	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
-	mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
-
-	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
-
 	accountSenderData := domain.Account{
 		AccountNumber:  555001,
 		CustomerNumber: 1001,
-		Balance:        10000,
+		Balance:        domain.NewMoney(10000, "IDR"),
 	}
 
 	accountReceiverData := domain.Account{
 		AccountNumber:  555002,
 		CustomerNumber: 1002,
-		Balance:        15000,
+		Balance:        domain.NewMoney(15000, "IDR"),
 	}
 
 	transferParam := domain.TransferParam{
 		ToAccountNumber: "555002",
-		Amount:          1000,
+		Amount:          domain.NewMoney(1000, "IDR"),
 	}
 
 	t.Run("Success", func(t *testing.T) {
-		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(accountSenderData, nil).Once()
-		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555002).Return(accountReceiverData, nil).Once()
-		mockAccountRepo.On("Update", mock.Anything, &accountSenderData).Return(nil).Once()
-		mockAccountRepo.On("Update", mock.Anything, &accountReceiverData).Return(nil).Once()
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountSenderData, nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555002).Return(accountReceiverData, nil).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555001, domain.NewMoney(9000, "IDR")).Return(nil).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555002, domain.NewMoney(16000, "IDR")).Return(nil).Once()
+		mockTransferRepo.On("Store", mock.Anything, mock.AnythingOfType("*domain.Transfer")).Return(nil).Once()
+		mockLedgerRepo.On("Store", mock.Anything, mock.MatchedBy(func(entries []domain.LedgerEntry) bool {
+			return len(entries) == 2 &&
+				entries[0].TransactionID == entries[1].TransactionID &&
+				entries[0].AccountNumber == 555001 && entries[0].EntryType == domain.LedgerEntryDebit &&
+				entries[1].AccountNumber == 555002 && entries[1].EntryType == domain.LedgerEntryCredit &&
+				entries[0].Amount == domain.NewMoney(1000, "IDR") && entries[1].Amount == domain.NewMoney(1000, "IDR")
+		})).Return(nil).Once()
+
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		transfer, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, transfer.ID)
+		assert.Equal(t, 555001, transfer.FromAccountNumber)
+		assert.Equal(t, 555002, transfer.ToAccountNumber)
+		assert.Equal(t, domain.NewMoney(1000, "IDR"), transfer.Amount)
+		assert.Equal(t, domain.TransferStatusCompleted, transfer.Status)
+
+		mockTransaction.AssertExpectations(t)
+		mockTransferRepo.AssertExpectations(t)
+		mockAccountRepo.AssertExpectations(t)
+		mockLedgerRepo.AssertExpectations(t)
+	})
 
-		accountSenderData.Balance = accountSenderData.Balance - transferParam.Amount
-		accountReceiverData.Balance = accountReceiverData.Balance + transferParam.Amount
+	t.Run("Ledger-failed", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountSenderData, nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555002).Return(accountReceiverData, nil).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555001, domain.NewMoney(9000, "IDR")).Return(nil).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555002, domain.NewMoney(16000, "IDR")).Return(nil).Once()
+		mockTransferRepo.On("Store", mock.Anything, mock.AnythingOfType("*domain.Transfer")).Return(nil).Once()
+		mockLedgerRepo.On("Store", mock.Anything, mock.AnythingOfType("[]domain.LedgerEntry")).Return(errors.New("Unexpected")).Once()
+
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		_, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
+		assert.Error(t, err)
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		mockLedgerRepo.AssertExpectations(t)
+	})
 
-		err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
+	t.Run("Lock-in-account-number-order", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+
+		var lockOrder []int
+		recordLock := func(args mock.Arguments) {
+			lockOrder = append(lockOrder, args.Int(1))
+		}
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountSenderData, nil).Run(recordLock).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555002).Return(accountReceiverData, nil).Run(recordLock).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555002, domain.NewMoney(14000, "IDR")).Return(nil).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555001, domain.NewMoney(11000, "IDR")).Return(nil).Once()
+		mockTransferRepo.On("Store", mock.Anything, mock.AnythingOfType("*domain.Transfer")).Return(nil).Once()
+		mockLedgerRepo.On("Store", mock.Anything, mock.AnythingOfType("[]domain.LedgerEntry")).Return(nil).Once()
+
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		_, err := customerUseCase.Transfer(context.Background(), accountReceiverData.AccountNumber, domain.TransferParam{
+			ToAccountNumber: "555001",
+			Amount:          domain.NewMoney(1000, "IDR"),
+		})
 		assert.NoError(t, err)
-		assert.Equal(t, 9000, accountSenderData.Balance)
-		assert.Equal(t, 16000, accountReceiverData.Balance)
+		assert.Equal(t, []int{555001, 555002}, lockOrder)
 
+		mockAccountRepo.AssertExpectations(t)
 	})
 
 	t.Run("Account-sender-not-exists", func(t *testing.T) {
-		mockAccountRepo.On("GetByAccountNumber", mock.Anything, mock.AnythingOfType("int")).Return(domain.Account{}, sql.ErrNoRows).Once()
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(domain.Account{}, sql.ErrNoRows).Once()
 
-		err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
-		assert.Error(t, err)
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		_, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
+		assert.Equal(t, domain.ErrSenderAccountNotFound, errors.Cause(err))
 
 		mockAccountRepo.AssertExpectations(t)
 	})
 
 	t.Run("Account-receiver-not-exists", func(t *testing.T) {
-		mockAccountRepo.On("GetByAccountNumber", mock.Anything, mock.AnythingOfType("int")).Return(accountSenderData, nil).Once()
-		mockAccountRepo.On("GetByAccountNumber", mock.Anything, mock.AnythingOfType("int")).Return(domain.Account{}, sql.ErrNoRows).Once()
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountSenderData, nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555002).Return(domain.Account{}, sql.ErrNoRows).Once()
 
-		err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
-		assert.Error(t, err)
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		_, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
+		assert.Equal(t, domain.ErrReceiverAccountNotFound, errors.Cause(err))
 
 		mockAccountRepo.AssertExpectations(t)
 	})
 
 	t.Run("Insufficient-balance", func(t *testing.T) {
-		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(accountSenderData, nil).Once()
-		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555002).Return(accountReceiverData, nil).Once()
-		mockAccountRepo.On("Update", mock.Anything, &accountSenderData).Return(nil).Once()
-		mockAccountRepo.On("Update", mock.Anything, &accountReceiverData).Return(nil).Once()
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountSenderData, nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555002).Return(accountReceiverData, nil).Once()
+
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		_, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, domain.TransferParam{
+			ToAccountNumber: "555002",
+			Amount:          domain.NewMoney(100000, "IDR"),
+		})
+		assert.Equal(t, domain.ErrInsufficientBalance, errors.Cause(err))
+
+		mockAccountRepo.AssertExpectations(t)
+		mockAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Held-balance", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockHoldRepo := new(repository_hold_mock.HoldMockRepository)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountSenderData, nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555002).Return(accountReceiverData, nil).Once()
+		mockHoldRepo.On("TotalActive", mock.Anything, 555001).Return(int64(9500), nil).Once()
+
+		customerUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository), new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository), mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), mockHoldRepo, noLimits(), logger)
+
+		// 10,000 on the account with 9,500 held leaves 500 to transfer
+		_, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
+		assert.Equal(t, domain.ErrInsufficientBalance, errors.Cause(err))
+
+		mockHoldRepo.AssertExpectations(t)
+		mockAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Over-limit", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockTransferLimitUseCase := new(transferlimit_usecase_mock.TransferLimitMockUseCase)
+
+		remaining := domain.NewMoney(200, "IDR")
+		exceeded := domain.TransferLimitExceededError{Limit: domain.TransferLimitDaily, Remaining: &remaining}
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountSenderData, nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555002).Return(accountReceiverData, nil).Once()
+		mockTransferLimitUseCase.On("Check", mock.Anything, accountSenderData, transferParam.Amount).Return(exceeded).Once()
+
+		customerUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository), new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository), mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), mockTransferLimitUseCase, logger)
+
+		_, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
+		assert.Equal(t, exceeded, errors.Cause(err))
+
+		mockTransferLimitUseCase.AssertExpectations(t)
+		mockAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Currency-mismatch", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
 
-		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountSenderData, nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555002).Return(accountReceiverData, nil).Once()
+
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		_, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, domain.TransferParam{
+			ToAccountNumber: "555002",
+			Amount:          domain.NewMoney(1000, "USD"),
+		})
+		assert.Equal(t, domain.ErrCurrencyMismatch, errors.Cause(err))
+
+		mockAccountRepo.AssertExpectations(t)
+		mockAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Converted", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+
+		receiver := domain.Account{AccountNumber: 555002, CustomerNumber: 1002, Balance: domain.NewMoney(500, "USD")}
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountSenderData, nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555002).Return(receiver, nil).Once()
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(1000, "IDR"), "USD").
+			Return(domain.NewMoney(7, "USD"), domain.FXRate{BaseCurrency: "IDR", QuoteCurrency: "USD", Rate: "0.000065"}, nil).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555001, domain.NewMoney(9000, "IDR")).Return(nil).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555002, domain.NewMoney(507, "USD")).Return(nil).Once()
+		mockTransferRepo.On("Store", mock.Anything, mock.AnythingOfType("*domain.Transfer")).Return(nil).Once()
+		// Each currency is booked as its own pair against the external account.
+		mockLedgerRepo.On("Store", mock.Anything, mock.MatchedBy(func(entries []domain.LedgerEntry) bool {
+			return len(entries) == 2 &&
+				entries[0].AccountNumber == 555001 && entries[0].EntryType == domain.LedgerEntryDebit &&
+				entries[1].AccountNumber == domain.LedgerExternalAccountNumber && entries[1].EntryType == domain.LedgerEntryCredit &&
+				entries[0].Amount == domain.NewMoney(1000, "IDR") && entries[1].Amount == domain.NewMoney(1000, "IDR")
+		})).Return(nil).Once()
+		mockLedgerRepo.On("Store", mock.Anything, mock.MatchedBy(func(entries []domain.LedgerEntry) bool {
+			return len(entries) == 2 &&
+				entries[0].AccountNumber == domain.LedgerExternalAccountNumber && entries[0].EntryType == domain.LedgerEntryDebit &&
+				entries[1].AccountNumber == 555002 && entries[1].EntryType == domain.LedgerEntryCredit &&
+				entries[0].Amount == domain.NewMoney(7, "USD") && entries[1].Amount == domain.NewMoney(7, "USD")
+		})).Return(nil).Once()
+
+		customerUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository), mockLedgerRepo, mockTransferRepo, mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), mockFXRateUseCase, noHolds(), noLimits(), logger)
+
+		transfer, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.NewMoney(1000, "IDR"), transfer.Amount)
+		assert.Equal(t, domain.NewMoney(7, "USD"), transfer.ConvertedAmount)
+		assert.Equal(t, "0.000065", transfer.FXRate)
+
+		mockAccountRepo.AssertExpectations(t)
+		mockLedgerRepo.AssertExpectations(t)
+		mockFXRateUseCase.AssertExpectations(t)
+	})
+
+	t.Run("No-fx-rate", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+
+		receiver := domain.Account{AccountNumber: 555002, CustomerNumber: 1002, Balance: domain.NewMoney(500, "USD")}
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountSenderData, nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555002).Return(receiver, nil).Once()
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(1000, "IDR"), "USD").
+			Return(domain.Money{}, domain.FXRate{}, domain.ErrFXRateNotFound).Once()
+
+		customerUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository), new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository), mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), mockFXRateUseCase, noHolds(), noLimits(), logger)
+
+		_, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
+		assert.Equal(t, domain.ErrFXRateNotFound, errors.Cause(err))
+
+		mockAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Non-positive-amount", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockTransaction := new(database_mock.TransactionMockManager)
+
+		customerUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository), new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository), mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		for _, amount := range []int64{0, -1000} {
+			_, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, domain.TransferParam{
+				ToAccountNumber: "555002",
+				Amount:          domain.NewMoney(amount, "IDR"),
+			})
+			assert.Equal(t, domain.ErrInvalidAmount, errors.Cause(err))
+		}
+
+		_, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, domain.TransferParam{
+			ToAccountNumber: "555002",
+			Amount:          domain.NewMoney(1000, "XYZ"),
+		})
+		assert.Equal(t, domain.ErrInvalidCurrency, errors.Cause(err))
+
+		mockTransaction.AssertNotCalled(t, "WithinTransaction", mock.Anything, mock.Anything)
+	})
 
-		transferParam.Amount = 100000
-		err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, transferParam)
+	t.Run("Same-account", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+
+		customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		_, err := customerUseCase.Transfer(context.Background(), accountReceiverData.AccountNumber, transferParam)
 		assert.Error(t, err)
+
+		mockTransaction.AssertNotCalled(t, "WithinTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid-receiver-account-number", func(t *testing.T) {
+		mockTransaction := new(database_mock.TransactionMockManager)
+
+		customerUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), new(repository_account_mock.AccountMockRepository), new(repository_customer_mock.CustomerMockRepository), new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository), mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		_, err := customerUseCase.Transfer(context.Background(), accountSenderData.AccountNumber, domain.TransferParam{
+			ToAccountNumber: "55500x",
+			Amount:          domain.NewMoney(1000, "IDR"),
+		})
+		assert.Equal(t, domain.ErrBadRequest, err)
+
+		mockTransaction.AssertNotCalled(t, "WithinTransaction", mock.Anything, mock.Anything)
 	})
 }
 
-func TestAccountUseCase_Login(t *testing.T) {
+func TestAccountUseCase_Deposit(t *testing.T) {
+	logger := logrus.New()
+
+	account := domain.Account{
+		AccountNumber:  555001,
+		CustomerNumber: 1001,
+		Balance:        domain.NewMoney(10000, "IDR"),
+	}
+
+	param := domain.MovementParam{
+		Amount:     domain.NewMoney(2500, "IDR"),
+		Reference:  "BR-0001",
+		Channel:    domain.ChannelBranch,
+		ReasonCode: "CASH",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockTransaction := new(database_mock.TransactionMockManager)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(account, nil).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555001, domain.NewMoney(12500, "IDR")).Return(nil).Once()
+		mockTransferRepo.On("Store", mock.Anything, mock.MatchedBy(func(t *domain.Transfer) bool {
+			return t.Type == domain.TransferTypeDeposit && t.FromAccountNumber == domain.LedgerExternalAccountNumber &&
+				t.ToAccountNumber == 555001 && t.Reference == "BR-0001" && t.Channel == domain.ChannelBranch && t.ReasonCode == "CASH"
+		})).Return(nil).Once()
+		mockLedgerRepo.On("Store", mock.Anything, mock.MatchedBy(func(entries []domain.LedgerEntry) bool {
+			return len(entries) == 2 &&
+				entries[0].TransactionType == domain.LedgerTransactionDeposit &&
+				entries[0].AccountNumber == domain.LedgerExternalAccountNumber && entries[0].EntryType == domain.LedgerEntryDebit &&
+				entries[1].AccountNumber == 555001 && entries[1].EntryType == domain.LedgerEntryCredit &&
+				entries[1].Amount == domain.NewMoney(2500, "IDR")
+		})).Return(nil).Once()
+
+		accountUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository), mockLedgerRepo, mockTransferRepo, mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		deposit, err := accountUseCase.Deposit(context.Background(), 555001, param)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.TransferTypeDeposit, deposit.Type)
+		assert.Equal(t, domain.NewMoney(2500, "IDR"), deposit.ConvertedAmount)
+
+		mockAccountRepo.AssertExpectations(t)
+		mockTransferRepo.AssertExpectations(t)
+		mockLedgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("Duplicate-reference", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockTransaction := new(database_mock.TransactionMockManager)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(account, nil).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555001, domain.NewMoney(12500, "IDR")).Return(nil).Once()
+		mockTransferRepo.On("Store", mock.Anything, mock.AnythingOfType("*domain.Transfer")).Return(domain.ErrDuplicateReference).Once()
+
+		accountUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository), mockLedgerRepo, mockTransferRepo, mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		_, err := accountUseCase.Deposit(context.Background(), 555001, param)
+		assert.Equal(t, domain.ErrDuplicateReference, err)
+
+		mockLedgerRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+	})
+
+	cases := []struct {
+		name    string
+		amount  domain.Money
+		account domain.Account
+		lookup  error
+		err     error
+	}{
+		{"Account-not-exists", domain.NewMoney(2500, "IDR"), domain.Account{}, sql.ErrNoRows, domain.ErrAccountNotFound},
+		{"Currency-mismatch", domain.NewMoney(2500, "USD"), account, nil, domain.ErrCurrencyMismatch},
+		{"Non-positive-amount", domain.NewMoney(0, "IDR"), account, nil, domain.ErrInvalidAmount},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+			mockTransaction := new(database_mock.TransactionMockManager)
+
+			mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Maybe()
+			mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(c.account, c.lookup).Maybe()
+
+			accountUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository), new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository), mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+			p := param
+			p.Amount = c.amount
+
+			_, err := accountUseCase.Deposit(context.Background(), 555001, p)
+			assert.Equal(t, c.err, err)
+
+			mockAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAccountUseCase_Withdraw(t *testing.T) {
+	logger := logrus.New()
+
+	account := domain.Account{
+		AccountNumber:  555001,
+		CustomerNumber: 1001,
+		Balance:        domain.NewMoney(10000, "IDR"),
+	}
+
+	param := domain.MovementParam{
+		Amount:     domain.NewMoney(4000, "IDR"),
+		Reference:  "ATM-0001",
+		Channel:    domain.ChannelATM,
+		ReasonCode: "CASH",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockHoldRepo := new(repository_hold_mock.HoldMockRepository)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(account, nil).Once()
+		mockHoldRepo.On("TotalActive", mock.Anything, 555001).Return(int64(6000), nil).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555001, domain.NewMoney(6000, "IDR")).Return(nil).Once()
+		mockTransferRepo.On("Store", mock.Anything, mock.MatchedBy(func(t *domain.Transfer) bool {
+			return t.Type == domain.TransferTypeWithdrawal && t.FromAccountNumber == 555001 &&
+				t.ToAccountNumber == domain.LedgerExternalAccountNumber && t.Reference == "ATM-0001"
+		})).Return(nil).Once()
+		mockLedgerRepo.On("Store", mock.Anything, mock.MatchedBy(func(entries []domain.LedgerEntry) bool {
+			return len(entries) == 2 &&
+				entries[0].TransactionType == domain.LedgerTransactionWithdrawal &&
+				entries[0].AccountNumber == 555001 && entries[0].EntryType == domain.LedgerEntryDebit &&
+				entries[1].AccountNumber == domain.LedgerExternalAccountNumber && entries[1].EntryType == domain.LedgerEntryCredit
+		})).Return(nil).Once()
+
+		accountUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository), mockLedgerRepo, mockTransferRepo, mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), mockHoldRepo, noLimits(), logger)
+
+		// The 6,000 held stay on the account
+		withdrawal, err := accountUseCase.Withdraw(context.Background(), 555001, param)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.TransferTypeWithdrawal, withdrawal.Type)
+
+		mockAccountRepo.AssertExpectations(t)
+		mockHoldRepo.AssertExpectations(t)
+		mockTransferRepo.AssertExpectations(t)
+		mockLedgerRepo.AssertExpectations(t)
+	})
+
+	cases := []struct {
+		name   string
+		amount domain.Money
+		held   int64
+		err    error
+	}{
+		{"Insufficient-balance", domain.NewMoney(10001, "IDR"), 0, domain.ErrInsufficientBalance},
+		{"Held-balance", domain.NewMoney(4000, "IDR"), 6001, domain.ErrInsufficientBalance},
+		{"Currency-mismatch", domain.NewMoney(4000, "USD"), 0, domain.ErrCurrencyMismatch},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+			mockTransaction := new(database_mock.TransactionMockManager)
+			mockHoldRepo := new(repository_hold_mock.HoldMockRepository)
+
+			mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+			mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(account, nil).Once()
+			mockHoldRepo.On("TotalActive", mock.Anything, 555001).Return(c.held, nil).Maybe()
+
+			accountUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository), new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository), mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), mockHoldRepo, noLimits(), logger)
+
+			p := param
+			p.Amount = c.amount
+
+			_, err := accountUseCase.Withdraw(context.Background(), 555001, p)
+			assert.Equal(t, c.err, err)
+
+			mockAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAccountUseCase_ListTransfers(t *testing.T) {
+	logger := logrus.New()
+
+	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+	mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+	mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+	mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockTransaction := new(database_mock.TransactionMockManager)
+	mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+
+	transfers := []domain.Transfer{
+		{
+			ID:                "6f1c1a4e-3f0a-4d4b-8f59-1f1d2b7c8a90",
+			FromAccountNumber: 555001,
+			ToAccountNumber:   555002,
+			Amount:            domain.NewMoney(1000, "IDR"),
+			Status:            domain.TransferStatusCompleted,
+		},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockTransferRepo.On("ListByAccountNumber", mock.Anything, 555001, mock.AnythingOfType("domain.TransferListParam")).Return(transfers, nil).Once()
+		mockTransferRepo.On("CountByAccountNumber", mock.Anything, 555001, mock.AnythingOfType("domain.TransferListParam")).Return(1, nil).Once()
+
+		accountUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		result, total, err := accountUseCase.ListTransfers(context.Background(), 555001, domain.TransferListParam{})
+		assert.NoError(t, err)
+		assert.Equal(t, transfers, result)
+		assert.Equal(t, 1, total)
+
+		mockTransferRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockTransferRepo.On("ListByAccountNumber", mock.Anything, 555001, mock.AnythingOfType("domain.TransferListParam")).Return([]domain.Transfer{}, errors.New("Unexpected")).Once()
+
+		accountUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		result, _, err := accountUseCase.ListTransfers(context.Background(), 555001, domain.TransferListParam{})
+		assert.Error(t, err)
+		assert.Nil(t, result)
+
+		mockTransferRepo.AssertExpectations(t)
+	})
+}
+
+func TestAccountUseCase_ListLedgerEntries(t *testing.T) {
 	logger := logrus.New()
 
 	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
 	mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+	mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+	mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockTransaction := new(database_mock.TransactionMockManager)
+	mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+
+	entries := []domain.LedgerEntry{
+		{
+			ID:              1,
+			TransactionType: domain.LedgerTransactionOpening,
+			AccountNumber:   555001,
+			EntryType:       domain.LedgerEntryCredit,
+			Amount:          domain.NewMoney(10000, "IDR"),
+		},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockLedgerRepo.On("ListByAccountNumber", mock.Anything, 555001, mock.AnythingOfType("domain.LedgerEntryListParam")).Return(entries, nil).Once()
+		mockLedgerRepo.On("CountByAccountNumber", mock.Anything, 555001).Return(1, nil).Once()
+
+		accountUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		result, total, err := accountUseCase.ListLedgerEntries(context.Background(), 555001, domain.LedgerEntryListParam{})
+		assert.NoError(t, err)
+		assert.Equal(t, entries, result)
+		assert.Equal(t, 1, total)
+
+		mockLedgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockLedgerRepo.On("ListByAccountNumber", mock.Anything, 555001, mock.AnythingOfType("domain.LedgerEntryListParam")).Return([]domain.LedgerEntry{}, errors.New("Unexpected")).Once()
+
+		accountUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		result, _, err := accountUseCase.ListLedgerEntries(context.Background(), 555001, domain.LedgerEntryListParam{})
+		assert.Error(t, err)
+		assert.Nil(t, result)
+
+		mockLedgerRepo.AssertExpectations(t)
+	})
+}
+
+func TestAccountUseCase_RebuildBalance(t *testing.T) {
+	logger := logrus.New()
+
+	accountData := domain.Account{
+		AccountNumber:  555001,
+		CustomerNumber: 1001,
+		Balance:        domain.NewMoney(10000, "IDR"),
+	}
+
+	t.Run("Balance-matches-ledger", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountData, nil).Once()
+		mockLedgerRepo.On("GetBalance", mock.Anything, 555001).Return(int64(10000), nil).Once()
+
+		accountUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		response, err := accountUseCase.RebuildBalance(context.Background(), 555001)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RebuildBalanceResponse{AccountNumber: 555001, PreviousBalance: domain.NewMoney(10000, "IDR"), Balance: domain.NewMoney(10000, "IDR")}, response)
+
+		mockAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Balance-drifted", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(accountData, nil).Once()
+		mockLedgerRepo.On("GetBalance", mock.Anything, 555001).Return(int64(9000), nil).Once()
+		mockAccountRepo.On("UpdateBalance", mock.Anything, 555001, domain.NewMoney(9000, "IDR")).Return(nil).Once()
+
+		accountUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		response, err := accountUseCase.RebuildBalance(context.Background(), 555001)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RebuildBalanceResponse{AccountNumber: 555001, PreviousBalance: domain.NewMoney(10000, "IDR"), Balance: domain.NewMoney(9000, "IDR")}, response)
+
+		mockAccountRepo.AssertExpectations(t)
+	})
+
+	t.Run("Account-not-exists", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+		mockLedgerRepo := new(repository_ledger_mock.LedgerMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockAccountRepo.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(domain.Account{}, sql.ErrNoRows).Once()
+
+		accountUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, mockLedgerRepo, mockTransferRepo, mockTransaction, mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		response, err := accountUseCase.RebuildBalance(context.Background(), 555001)
+		assert.Equal(t, domain.ErrAccountNotFound, errors.Cause(err))
+		assert.Equal(t, domain.RebuildBalanceResponse{}, response)
+	})
+}
+
+func TestAccountUseCase_Login(t *testing.T) {
+	logger := logrus.New()
+
+	accountData := domain.Account{
+		AccountNumber:  555001,
+		CustomerNumber: 1001,
+		Balance:        domain.NewMoney(10000, "IDR"),
+		Email:          "email@mail.com",
+		Password:       "$2y$12$55Pvvir6aXTbi3tE5toEyuUMgPCJ1uytiVREzrSHDgXoNFva7kLOK", //Secret
+	}
+
+	type mocks struct {
+		account      *repository_account_mock.AccountMockRepository
+		loginAttempt *loginattempt_repository_mock.LoginAttemptMockRepository
+		auth         *auth_usecase_mock.AuthMockUseCase
+	}
+
+	newUseCase := func() (domain.AccountUseCase, mocks) {
+		m := mocks{
+			account:      new(repository_account_mock.AccountMockRepository),
+			loginAttempt: new(loginattempt_repository_mock.LoginAttemptMockRepository),
+			auth:         new(auth_usecase_mock.AuthMockUseCase),
+		}
+
+		accountUseCase := NewAccountUseCase(m.auth, m.account, new(repository_customer_mock.CustomerMockRepository),
+			new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository),
+			new(database_mock.TransactionMockManager), m.loginAttempt, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		return accountUseCase, m
+	}
+
+	notBlocked := func(m mocks) {
+		m.loginAttempt.On("BlockedFor", mock.Anything, "email:email@mail.com").Return(time.Duration(0), nil).Once()
+		m.loginAttempt.On("BlockedFor", mock.Anything, "ip:10.0.0.1").Return(time.Duration(0), nil).Once()
+	}
+
+	param := func(password string) domain.AccountLoginParam {
+		return domain.AccountLoginParam{
+			Email:     "Email@Mail.com",
+			Password:  password,
+			UserAgent: "curl/7.68.0",
+			IP:        "10.0.0.1",
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		notBlocked(m)
+		m.account.On("GetByEmail", mock.Anything, "email@mail.com").Return(accountData, nil).Once()
+		m.loginAttempt.On("Reset", mock.Anything, "email:email@mail.com").Return(nil).Once()
+		m.auth.On("CreateAuth", mock.Anything, accountData, "curl/7.68.0").Return(domain.Auth{AccessToken: "token", RefreshToken: "refresh"}, nil).Once()
+
+		response, err := accountUseCase.Login(context.Background(), param("secret"))
+		assert.NoError(t, err)
+		assert.Equal(t, domain.LoginResponse{Token: "token", RefreshToken: "refresh"}, response)
+
+		m.account.AssertExpectations(t)
+		m.loginAttempt.AssertExpectations(t)
+		m.auth.AssertExpectations(t)
+	})
+
+	t.Run("Email-not-found", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		notBlocked(m)
+		m.account.On("GetByEmail", mock.Anything, "email@mail.com").Return(domain.Account{}, sql.ErrNoRows).Once()
+		m.loginAttempt.On("RegisterFailure", mock.Anything, "email:email@mail.com", loginPolicy.FailureWindow).Return(int64(1), nil).Once()
+		m.loginAttempt.On("Block", mock.Anything, "email:email@mail.com", time.Second).Return(nil).Once()
+		m.loginAttempt.On("RegisterFailure", mock.Anything, "ip:10.0.0.1", loginPolicy.FailureWindow).Return(int64(1), nil).Once()
+
+		response, err := accountUseCase.Login(context.Background(), param("secret"))
+		assert.Equal(t, domain.ErrInvalidCredentials, err)
+		assert.Empty(t, response.Token)
+
+		m.loginAttempt.AssertExpectations(t)
+		m.auth.AssertNotCalled(t, "CreateAuth", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid-password-delays-progressively", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		notBlocked(m)
+		m.account.On("GetByEmail", mock.Anything, "email@mail.com").Return(accountData, nil).Once()
+		m.loginAttempt.On("RegisterFailure", mock.Anything, "email:email@mail.com", loginPolicy.FailureWindow).Return(int64(3), nil).Once()
+		m.loginAttempt.On("Block", mock.Anything, "email:email@mail.com", 4*time.Second).Return(nil).Once()
+		m.loginAttempt.On("RegisterFailure", mock.Anything, "ip:10.0.0.1", loginPolicy.FailureWindow).Return(int64(3), nil).Once()
+
+		_, err := accountUseCase.Login(context.Background(), param("secret1"))
+		assert.Equal(t, domain.ErrInvalidCredentials, err)
+
+		m.loginAttempt.AssertExpectations(t)
+	})
+
+	t.Run("Delay-is-capped", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		notBlocked(m)
+		m.account.On("GetByEmail", mock.Anything, "email@mail.com").Return(accountData, nil).Once()
+		m.loginAttempt.On("RegisterFailure", mock.Anything, "email:email@mail.com", loginPolicy.FailureWindow).Return(int64(loginPolicy.MaxFailures-1), nil).Once()
+		m.loginAttempt.On("Block", mock.Anything, "email:email@mail.com", loginPolicy.MaxDelay).Return(nil).Once()
+		m.loginAttempt.On("RegisterFailure", mock.Anything, "ip:10.0.0.1", loginPolicy.FailureWindow).Return(int64(4), nil).Once()
+
+		_, err := accountUseCase.Login(context.Background(), param("secret1"))
+		assert.Equal(t, domain.ErrInvalidCredentials, err)
+
+		m.loginAttempt.AssertExpectations(t)
+	})
+
+	t.Run("Lockout", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		notBlocked(m)
+		m.account.On("GetByEmail", mock.Anything, "email@mail.com").Return(accountData, nil).Once()
+		m.loginAttempt.On("RegisterFailure", mock.Anything, "email:email@mail.com", loginPolicy.FailureWindow).Return(int64(loginPolicy.MaxFailures), nil).Once()
+		m.loginAttempt.On("Block", mock.Anything, "email:email@mail.com", loginPolicy.LockDuration).Return(nil).Once()
+		m.loginAttempt.On("RegisterFailure", mock.Anything, "ip:10.0.0.1", loginPolicy.FailureWindow).Return(int64(loginPolicy.MaxIPFailures), nil).Once()
+		m.loginAttempt.On("Block", mock.Anything, "ip:10.0.0.1", loginPolicy.LockDuration).Return(nil).Once()
+
+		_, err := accountUseCase.Login(context.Background(), param("secret1"))
+		assert.Equal(t, domain.ErrInvalidCredentials, err)
+
+		m.loginAttempt.AssertExpectations(t)
+	})
+
+	t.Run("Locked-email", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		m.loginAttempt.On("BlockedFor", mock.Anything, "email:email@mail.com").Return(10*time.Minute, nil).Once()
+
+		_, err := accountUseCase.Login(context.Background(), param("secret"))
+		assert.Equal(t, domain.LoginThrottledError{RetryAfter: 10 * time.Minute}, err)
+
+		m.account.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Locked-ip", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		m.loginAttempt.On("BlockedFor", mock.Anything, "email:email@mail.com").Return(time.Duration(0), nil).Once()
+		m.loginAttempt.On("BlockedFor", mock.Anything, "ip:10.0.0.1").Return(time.Minute, nil).Once()
+
+		_, err := accountUseCase.Login(context.Background(), param("secret"))
+		assert.Equal(t, domain.LoginThrottledError{RetryAfter: time.Minute}, err)
+
+		m.account.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAccountUseCase_Unlock(t *testing.T) {
+	logger := logrus.New()
+
+	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+	mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+
+	accountUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository),
+		new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository),
+		new(database_mock.TransactionMockManager), mockLoginAttemptRepo, loginPolicy, passwordPolicy, new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(domain.Account{AccountNumber: 555001, Email: "email@mail.com"}, nil).Once()
+		mockLoginAttemptRepo.On("Reset", mock.Anything, "email:email@mail.com").Return(nil).Once()
+
+		err := accountUseCase.Unlock(context.Background(), 555001)
+		assert.NoError(t, err)
+
+		mockLoginAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Account-not-found", func(t *testing.T) {
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555009).Return(domain.Account{}, sql.ErrNoRows).Once()
+
+		err := accountUseCase.Unlock(context.Background(), 555009)
+		assert.Equal(t, domain.ErrAccountNotFound, errors.Cause(err))
+	})
+}
+
+func TestAccountUseCase_ChangePassword(t *testing.T) {
+	logger := logrus.New()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("OldPassw0rd"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+
+	accountUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, new(repository_customer_mock.CustomerMockRepository),
+		new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository),
+		new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy,
+		new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(domain.Account{AccountNumber: 555001, Password: string(hash)}, nil).Once()
+		mockAccountRepo.On("UpdatePassword", mock.Anything, 555001, mock.MatchedBy(func(password string) bool {
+			return bcrypt.CompareHashAndPassword([]byte(password), []byte("NewPassw0rd")) == nil
+		})).Return(nil).Once()
+		mockAuthUseCase.On("LogoutAll", mock.Anything, 555001).Return(nil).Once()
+
+		err := accountUseCase.ChangePassword(context.Background(), 555001, domain.PasswordChangeParam{
+			OldPassword: "OldPassw0rd",
+			NewPassword: "NewPassw0rd",
+		})
+		assert.NoError(t, err)
+
+		mockAccountRepo.AssertExpectations(t)
+		mockAuthUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Wrong-password", func(t *testing.T) {
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(domain.Account{AccountNumber: 555001, Password: string(hash)}, nil).Once()
+
+		err := accountUseCase.ChangePassword(context.Background(), 555001, domain.PasswordChangeParam{
+			OldPassword: "WrongPassw0rd",
+			NewPassword: "NewPassw0rd",
+		})
+		assert.Equal(t, domain.ErrWrongPassword, err)
+	})
+
+	t.Run("Weak-password", func(t *testing.T) {
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(domain.Account{AccountNumber: 555001, Password: string(hash)}, nil).Once()
+
+		err := accountUseCase.ChangePassword(context.Background(), 555001, domain.PasswordChangeParam{
+			OldPassword: "OldPassw0rd",
+			NewPassword: "weak",
+		})
+		policyErr, ok := err.(*domain.Error)
+		assert.True(t, ok)
+		assert.Equal(t, "weak_password", policyErr.Code)
+
+		mockAccountRepo.AssertNumberOfCalls(t, "UpdatePassword", 1)
+	})
+}
+
+func TestAccountUseCase_RequestPasswordReset(t *testing.T) {
+	logger := logrus.New()
+
+	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+	mockPasswordResetRepo := new(passwordreset_repository_mock.PasswordResetMockRepository)
+	mockNotifier := new(notifier_mock.NotifierMock)
+
+	policy := passwordPolicy
+	policy.ResetTokenExpire = 30 * time.Minute
+
+	accountUseCase := NewAccountUseCase(new(auth_usecase_mock.AuthMockUseCase), mockAccountRepo, new(repository_customer_mock.CustomerMockRepository),
+		new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository),
+		new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, policy,
+		mockPasswordResetRepo, mockNotifier, noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+	t.Run("Success", func(t *testing.T) {
+		var token string
+
+		mockAccountRepo.On("GetByEmail", mock.Anything, "email@mail.com").Return(domain.Account{AccountNumber: 555001, Email: "email@mail.com"}, nil).Once()
+		mockPasswordResetRepo.On("Store", mock.Anything, mock.AnythingOfType("string"), 555001, 30*time.Minute).Run(func(args mock.Arguments) {
+			token = args.String(1)
+		}).Return(nil).Once()
+		mockNotifier.On("Notify", mock.Anything, "email@mail.com", "Password reset", mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+			assert.Contains(t, args.String(3), token)
+		}).Return(nil).Once()
+
+		err := accountUseCase.RequestPasswordReset(context.Background(), domain.PasswordResetRequestParam{Email: "Email@Mail.com"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+
+		mockPasswordResetRepo.AssertExpectations(t)
+		mockNotifier.AssertExpectations(t)
+	})
+
+	t.Run("Unknown-email", func(t *testing.T) {
+		mockAccountRepo.On("GetByEmail", mock.Anything, "unknown@mail.com").Return(domain.Account{}, sql.ErrNoRows).Once()
+
+		err := accountUseCase.RequestPasswordReset(context.Background(), domain.PasswordResetRequestParam{Email: "unknown@mail.com"})
+		assert.NoError(t, err)
+
+		mockNotifier.AssertNumberOfCalls(t, "Notify", 1)
+	})
+}
+
+func TestAccountUseCase_ResetPassword(t *testing.T) {
+	logger := logrus.New()
+
+	mockAccountRepo := new(repository_account_mock.AccountMockRepository)
 	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockPasswordResetRepo := new(passwordreset_repository_mock.PasswordResetMockRepository)
+
+	accountUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, new(repository_customer_mock.CustomerMockRepository),
+		new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository),
+		new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), loginPolicy, passwordPolicy,
+		mockPasswordResetRepo, new(notifier_mock.NotifierMock), noMFA(), new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+	t.Run("Success", func(t *testing.T) {
+		mockPasswordResetRepo.On("Consume", mock.Anything, "token").Return(555001, nil).Once()
+		mockAccountRepo.On("UpdatePassword", mock.Anything, 555001, mock.AnythingOfType("string")).Return(nil).Once()
+		mockAuthUseCase.On("LogoutAll", mock.Anything, 555001).Return(nil).Once()
+
+		err := accountUseCase.ResetPassword(context.Background(), domain.PasswordResetParam{Token: "token", NewPassword: "NewPassw0rd"})
+		assert.NoError(t, err)
+
+		mockAccountRepo.AssertExpectations(t)
+		mockAuthUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid-token", func(t *testing.T) {
+		mockPasswordResetRepo.On("Consume", mock.Anything, "used").Return(0, domain.ErrInvalidResetToken).Once()
+
+		err := accountUseCase.ResetPassword(context.Background(), domain.PasswordResetParam{Token: "used", NewPassword: "NewPassw0rd"})
+		assert.Equal(t, domain.ErrInvalidResetToken, err)
+	})
 
-	customerUseCase := NewAccountUseCase(mockAuthUseCase, mockAccountRepo, mockCustomerRepo, logger)
+	t.Run("Weak-password-keeps-token", func(t *testing.T) {
+		err := accountUseCase.ResetPassword(context.Background(), domain.PasswordResetParam{Token: "token", NewPassword: "weak"})
+		policyErr, ok := err.(*domain.Error)
+		assert.True(t, ok)
+		assert.Equal(t, "weak_password", policyErr.Code)
+
+		mockPasswordResetRepo.AssertNumberOfCalls(t, "Consume", 2)
+	})
+}
+
+func TestAccountUseCase_LoginMFA(t *testing.T) {
+	logger := logrus.New()
 
 	accountData := domain.Account{
 		AccountNumber:  555001,
 		CustomerNumber: 1001,
-		Balance:        10000,
+		Balance:        domain.NewMoney(10000, "IDR"),
 		Email:          "email@mail.com",
 		Password:       "$2y$12$55Pvvir6aXTbi3tE5toEyuUMgPCJ1uytiVREzrSHDgXoNFva7kLOK", //Secret
 	}
 
-	t.Run("email-not-found", func(t *testing.T) {
-		mockAccountRepo.On("GetByEmail", mock.Anything, mock.AnythingOfType("string")).Return(domain.Account{}, sql.ErrNoRows).Once()
-		mockAuthUseCase.On("CreateAuth", mock.Anything, accountData).Return(domain.Auth{}, nil).Once()
+	type mocks struct {
+		account      *repository_account_mock.AccountMockRepository
+		loginAttempt *loginattempt_repository_mock.LoginAttemptMockRepository
+		auth         *auth_usecase_mock.AuthMockUseCase
+		mfa          *mfa_usecase_mock.MFAMockUseCase
+		transaction  *database_mock.TransactionMockManager
+	}
+
+	newUseCase := func() (domain.AccountUseCase, mocks) {
+		m := mocks{
+			account:      new(repository_account_mock.AccountMockRepository),
+			loginAttempt: new(loginattempt_repository_mock.LoginAttemptMockRepository),
+			auth:         new(auth_usecase_mock.AuthMockUseCase),
+			mfa:          new(mfa_usecase_mock.MFAMockUseCase),
+			transaction:  new(database_mock.TransactionMockManager),
+		}
+
+		accountUseCase := NewAccountUseCase(m.auth, m.account, new(repository_customer_mock.CustomerMockRepository),
+			new(repository_ledger_mock.LedgerMockRepository), new(repository_transfer_mock.TransferMockRepository),
+			m.transaction, m.loginAttempt, loginPolicy, passwordPolicy,
+			new(passwordreset_repository_mock.PasswordResetMockRepository), new(notifier_mock.NotifierMock), m.mfa, new(fxrate_usecase_mock.FXRateMockUseCase), noHolds(), noLimits(), logger)
+
+		return accountUseCase, m
+	}
+
+	param := domain.LoginMFAParam{
+		MFAToken:  "challenge",
+		Code:      "123456",
+		UserAgent: "curl/7.68.0",
+		IP:        "10.0.0.1",
+	}
+
+	t.Run("Login-answers-challenge", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		m.loginAttempt.On("BlockedFor", mock.Anything, mock.Anything).Return(time.Duration(0), nil).Twice()
+		m.account.On("GetByEmail", mock.Anything, "email@mail.com").Return(accountData, nil).Once()
+		m.loginAttempt.On("Reset", mock.Anything, "email:email@mail.com").Return(nil).Once()
+		m.mfa.On("Enabled", mock.Anything, 555001).Return(true, nil).Once()
+		m.mfa.On("CreateChallenge", mock.Anything, 555001).Return("challenge", nil).Once()
 
-		response, err := customerUseCase.Login(context.Background(), domain.AccountLoginParam{
-			Email:    "email1@mail.com",
+		response, err := accountUseCase.Login(context.Background(), domain.AccountLoginParam{
+			Email:    "email@mail.com",
 			Password: "secret",
+			IP:       "10.0.0.1",
 		})
-		assert.Error(t, err)
-		assert.Empty(t, response.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.LoginResponse{MFARequired: true, MFAToken: "challenge"}, response)
+
+		m.auth.AssertNotCalled(t, "CreateAuth", mock.Anything, mock.Anything, mock.Anything)
 	})
 
-	t.Run("invalid-password", func(t *testing.T) {
-		mockAccountRepo.On("GetByEmail", mock.Anything, mock.AnythingOfType("string")).Return(accountData, nil).Once()
-		mockAuthUseCase.On("CreateAuth", mock.Anything, accountData).Return(domain.Auth{}, nil).Once()
+	t.Run("Success", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		m.mfa.On("ConsumeChallenge", mock.Anything, "challenge").Return(555001, nil).Once()
+		m.account.On("GetByAccountNumber", mock.Anything, 555001).Return(accountData, nil).Once()
+		m.mfa.On("Verify", mock.Anything, 555001, "123456").Return(nil).Once()
+		m.auth.On("CreateAuth", mock.Anything, accountData, "curl/7.68.0").Return(domain.Auth{AccessToken: "token", RefreshToken: "refresh"}, nil).Once()
 
-		response, err := customerUseCase.Login(context.Background(), domain.AccountLoginParam{
-			Email:    "email@mail.com",
-			Password: "secret1",
+		response, err := accountUseCase.LoginMFA(context.Background(), param)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.LoginResponse{Token: "token", RefreshToken: "refresh"}, response)
+
+		m.mfa.AssertExpectations(t)
+		m.auth.AssertExpectations(t)
+	})
+
+	t.Run("Wrong-code-counts-as-failure", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		m.mfa.On("ConsumeChallenge", mock.Anything, "challenge").Return(555001, nil).Once()
+		m.account.On("GetByAccountNumber", mock.Anything, 555001).Return(accountData, nil).Once()
+		m.mfa.On("Verify", mock.Anything, 555001, "123456").Return(domain.ErrInvalidMFACode).Once()
+		m.loginAttempt.On("RegisterFailure", mock.Anything, "email:email@mail.com", loginPolicy.FailureWindow).Return(int64(1), nil).Once()
+		m.loginAttempt.On("Block", mock.Anything, "email:email@mail.com", time.Second).Return(nil).Once()
+		m.loginAttempt.On("RegisterFailure", mock.Anything, "ip:10.0.0.1", loginPolicy.FailureWindow).Return(int64(1), nil).Once()
+
+		_, err := accountUseCase.LoginMFA(context.Background(), param)
+		assert.Equal(t, domain.ErrInvalidMFACode, err)
+
+		m.loginAttempt.AssertExpectations(t)
+		m.auth.AssertNotCalled(t, "CreateAuth", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid-challenge", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		m.mfa.On("ConsumeChallenge", mock.Anything, "challenge").Return(0, domain.ErrInvalidMFAToken).Once()
+
+		_, err := accountUseCase.LoginMFA(context.Background(), param)
+		assert.Equal(t, domain.ErrInvalidMFAToken, err)
+	})
+
+	t.Run("Transfer-needs-step-up", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+		m.transaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		m.mfa.On("VerifyStepUp", mock.Anything, 555001, domain.NewMoney(5000000, "IDR"), "").Return(domain.ErrMFARequired).Once()
+
+		_, err := accountUseCase.Transfer(context.Background(), 555001, domain.TransferParam{
+			ToAccountNumber: "555002",
+			Amount:          domain.NewMoney(5000000, "IDR"),
 		})
-		assert.Error(t, err)
-		assert.Empty(t, response.Token)
+		assert.Equal(t, domain.ErrMFARequired, err)
+
+		m.account.AssertNotCalled(t, "GetByAccountNumberForUpdate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Transfer-step-up-in-transaction", func(t *testing.T) {
+		accountUseCase, m := newUseCase()
+
+		// The code is used up in the transaction of the transfer, a failed
+		// transfer rolls it back
+		inTransaction := false
+		m.transaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			inTransaction = true
+		}).Once()
+		m.mfa.On("VerifyStepUp", mock.Anything, 555001, domain.NewMoney(5000000, "IDR"), "123456").Return(nil).Run(func(args mock.Arguments) {
+			assert.True(t, inTransaction)
+		}).Once()
+		m.account.On("GetByAccountNumberForUpdate", mock.Anything, 555001).Return(domain.Account{}, sql.ErrNoRows).Once()
+
+		_, err := accountUseCase.Transfer(context.Background(), 555001, domain.TransferParam{
+			ToAccountNumber: "555002",
+			Amount:          domain.NewMoney(5000000, "IDR"),
+			OTP:             "123456",
+		})
+		assert.Equal(t, domain.ErrSenderAccountNotFound, err)
+
+		m.mfa.AssertExpectations(t)
+		m.transaction.AssertExpectations(t)
 	})
 }
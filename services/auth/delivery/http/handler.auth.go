@@ -0,0 +1,125 @@
+package delivery_http_auth
+
+import (
+	"net/http"
+
+	"github.com/oniharnantyo/golang-backend-example/middleware"
+	"github.com/oniharnantyo/golang-backend-example/util"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sirupsen/logrus"
+)
+
+type AuthHandler struct {
+	authUseCase domain.AuthUseCase
+	logger      *logrus.Logger
+}
+
+func NewAuthHandler(r *gin.Engine, au domain.AuthUseCase, l *logrus.Logger) *gin.Engine {
+	handler := &AuthHandler{authUseCase: au, logger: l}
+
+	r.POST("/auth/refresh", handler.HandlerRefresh)
+	r.POST("/auth/logout", middleware.JWT(au), handler.HandlerLogout)
+	r.POST("/auth/logout-all", middleware.JWT(au), handler.HandlerLogoutAll)
+	r.GET("/auth/sessions", middleware.JWT(au), handler.HandlerGetSessions)
+	r.GET("/.well-known/jwks.json", handler.HandlerGetJWKS)
+
+	return r
+}
+
+func (a *AuthHandler) HandlerRefresh(ctx *gin.Context) {
+	var param domain.RefreshParam
+	err := ctx.ShouldBind(&param)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AuthHandler/HandlerRefresh/ShouldBind", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	auth, err := a.authUseCase.Refresh(ctx, param.RefreshToken)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AuthHandler/HandlerRefresh/Refresh", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{
+		Data: domain.LoginResponse{
+			Token:        auth.AccessToken,
+			RefreshToken: auth.RefreshToken,
+		},
+	})
+}
+
+func (a *AuthHandler) HandlerLogout(ctx *gin.Context) {
+	claims, ok := middleware.GetAccessClaims(ctx)
+	if !ok {
+		ctx.Error(domain.ErrInvalidToken)
+		return
+	}
+
+	err := a.authUseCase.Logout(ctx, claims)
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AuthHandler/HandlerLogout/Logout", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (a *AuthHandler) HandlerLogoutAll(ctx *gin.Context) {
+	claims, ok := middleware.GetAccessClaims(ctx)
+	if !ok {
+		ctx.Error(domain.ErrInvalidToken)
+		return
+	}
+
+	err := a.authUseCase.LogoutAll(ctx, claims.AccountNumber())
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AuthHandler/HandlerLogoutAll/LogoutAll", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (a *AuthHandler) HandlerGetSessions(ctx *gin.Context) {
+	claims, ok := middleware.GetAccessClaims(ctx)
+	if !ok {
+		ctx.Error(domain.ErrInvalidToken)
+		return
+	}
+
+	sessions, err := a.authUseCase.ListSessions(ctx, claims.AccountNumber())
+	if err != nil {
+		a.logger.Errorf("%s : %v", "AuthHandler/HandlerGetSessions/ListSessions", err)
+		ctx.Error(err)
+		return
+	}
+
+	response := make([]domain.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, domain.SessionResponse{
+			ID:          session.ID,
+			UserAgent:   session.UserAgent,
+			CreatedAt:   session.CreatedAt,
+			RefreshedAt: session.RefreshedAt,
+			Current:     session.ID == claims.SessionID,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: response})
+}
+
+func (a *AuthHandler) HandlerGetJWKS(ctx *gin.Context) {
+	// Verifiers may cache the key set, rotations keep the retired key
+	// published for longer than this.
+	ctx.Header("Cache-Control", "public, max-age=300")
+	// The key set is served bare, JWT libraries expect the RFC 7517 format.
+	ctx.JSON(http.StatusOK, a.authUseCase.JWKS())
+}
@@ -0,0 +1,282 @@
+package delivery_http_auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware/testutil"
+	auth_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/auth/usecase/mock"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pkg/errors"
+)
+
+func TestAuthHandler_HandlerRefresh(t *testing.T) {
+	logger := logrus.New()
+
+	param, err := json.Marshal(domain.RefreshParam{RefreshToken: "refresh-token"})
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockAuthUseCase.On("Refresh", mock.Anything, "refresh-token").Return(domain.Auth{
+			AccessToken:  "new-access",
+			RefreshToken: "new-refresh",
+		}, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAuthHandler(r, mockAuthUseCase, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(param))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		resp, err := ioutil.ReadAll(rec.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"data":{"token":"new-access","refresh_token":"new-refresh"}}`, string(resp))
+		mockAuthUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Missing-token", func(t *testing.T) {
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+
+		r := testutil.NewRouter()
+		r = NewAuthHandler(r, mockAuthUseCase, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBufferString("{}"))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockAuthUseCase.AssertNotCalled(t, "Refresh", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Reused", func(t *testing.T) {
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockAuthUseCase.On("Refresh", mock.Anything, "refresh-token").Return(domain.Auth{}, domain.ErrRefreshTokenReused).Once()
+
+		r := testutil.NewRouter()
+		r = NewAuthHandler(r, mockAuthUseCase, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(param))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockAuthUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Internal-error", func(t *testing.T) {
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockAuthUseCase.On("Refresh", mock.Anything, "refresh-token").Return(domain.Auth{}, errors.New("redis down")).Once()
+
+		r := testutil.NewRouter()
+		r = NewAuthHandler(r, mockAuthUseCase, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(param))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mockAuthUseCase.AssertExpectations(t)
+	})
+}
+
+func TestAuthHandler_HandlerLogout(t *testing.T) {
+	logger := logrus.New()
+
+	claims := domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Id: "access-uuid", Subject: "555001"},
+		SessionID:      "session-id",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(claims, nil).Once()
+		mockAuthUseCase.On("Logout", mock.Anything, claims).Return(nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAuthHandler(r, mockAuthUseCase, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/logout", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockAuthUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Missing-token", func(t *testing.T) {
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+
+		r := testutil.NewRouter()
+		r = NewAuthHandler(r, mockAuthUseCase, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/logout", nil)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockAuthUseCase.AssertNotCalled(t, "Logout", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthHandler_HandlerLogoutAll(t *testing.T) {
+	logger := logrus.New()
+
+	claims := domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Id: "access-uuid", Subject: "555001"},
+		SessionID:      "session-id",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(claims, nil).Once()
+		mockAuthUseCase.On("LogoutAll", mock.Anything, 555001).Return(nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewAuthHandler(r, mockAuthUseCase, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/logout-all", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockAuthUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+		mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(claims, nil).Once()
+		mockAuthUseCase.On("LogoutAll", mock.Anything, 555001).Return(errors.New("redis down")).Once()
+
+		r := testutil.NewRouter()
+		r = NewAuthHandler(r, mockAuthUseCase, logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/auth/logout-all", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mockAuthUseCase.AssertExpectations(t)
+	})
+}
+
+func TestAuthHandler_HandlerGetSessions(t *testing.T) {
+	logger := logrus.New()
+
+	claims := domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Id: "access-uuid", Subject: "555001"},
+		SessionID:      "current",
+	}
+
+	createdAt := time.Date(2021, 4, 20, 10, 0, 0, 0, time.UTC)
+
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(claims, nil).Once()
+	mockAuthUseCase.On("ListSessions", mock.Anything, 555001).Return([]domain.Session{
+		{ID: "other", AccountNumber: 555001, AccessUuid: "secret", UserAgent: "curl/7.68.0", CreatedAt: createdAt, RefreshedAt: createdAt},
+		{ID: "current", AccountNumber: 555001, UserAgent: "Mozilla/5.0", CreatedAt: createdAt, RefreshedAt: createdAt},
+	}, nil).Once()
+
+	r := testutil.NewRouter()
+	r = NewAuthHandler(r, mockAuthUseCase, logger)
+
+	req, err := http.NewRequest(http.MethodGet, "/auth/sessions", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
+
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	var response struct {
+		Data []domain.SessionResponse `json:"data"`
+	}
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []domain.SessionResponse{
+		{ID: "other", UserAgent: "curl/7.68.0", CreatedAt: createdAt, RefreshedAt: createdAt},
+		{ID: "current", UserAgent: "Mozilla/5.0", CreatedAt: createdAt, RefreshedAt: createdAt, Current: true},
+	}, response.Data)
+	assert.NotContains(t, rec.Body.String(), "secret")
+	mockAuthUseCase.AssertExpectations(t)
+}
+
+func TestAuthHandler_HandlerGetJWKS(t *testing.T) {
+	logger := logrus.New()
+
+	jwks := domain.JWKS{Keys: []domain.JWK{
+		{Kty: "OKP", Kid: "2021-04", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+	}}
+
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockAuthUseCase.On("JWKS").Return(jwks).Once()
+
+	r := testutil.NewRouter()
+	r = NewAuthHandler(r, mockAuthUseCase, logger)
+
+	req, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	var response domain.JWKS
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, jwks, response)
+	assert.NotEmpty(t, rec.Header().Get("Cache-Control"))
+	mockAuthUseCase.AssertExpectations(t)
+}
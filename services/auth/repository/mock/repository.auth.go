@@ -24,3 +24,27 @@ func (a *AuthMockRepository) Set(ctx context.Context, key, value string, expire
 
 	return args.Error(0)
 }
+
+func (a *AuthMockRepository) Delete(ctx context.Context, keys ...string) (int64, error) {
+	args := a.Called(ctx, keys)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (a *AuthMockRepository) AddSession(ctx context.Context, accountNumber int, sessionID string, expire time.Duration) error {
+	args := a.Called(ctx, accountNumber, sessionID, expire)
+
+	return args.Error(0)
+}
+
+func (a *AuthMockRepository) ListSessions(ctx context.Context, accountNumber int) ([]string, error) {
+	args := a.Called(ctx, accountNumber)
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (a *AuthMockRepository) RemoveSessions(ctx context.Context, accountNumber int, sessionIDs ...string) error {
+	args := a.Called(ctx, accountNumber, sessionIDs)
+
+	return args.Error(0)
+}
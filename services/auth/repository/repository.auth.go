@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/oniharnantyo/golang-backend-example/domain"
@@ -9,6 +10,9 @@ import (
 	"github.com/go-redis/redis/v8"
 )
 
+// sessionsKeyFormat names the set holding the session IDs of one account.
+const sessionsKeyFormat = "account_sessions:%d"
+
 type authRepository struct {
 	redisClient *redis.Client
 }
@@ -31,6 +35,59 @@ func (a authRepository) Set(ctx context.Context, key, value string, expire time.
 	return nil
 }
 
+func (a authRepository) Delete(ctx context.Context, keys ...string) (int64, error) {
+	n, err := a.redisClient.Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func (a authRepository) AddSession(ctx context.Context, accountNumber int, sessionID string, expire time.Duration) error {
+	key := fmt.Sprintf(sessionsKeyFormat, accountNumber)
+
+	err := a.redisClient.SAdd(ctx, key, sessionID).Err()
+	if err != nil {
+		return err
+	}
+
+	// The index lives as long as the newest session in it.
+	err = a.redisClient.Expire(ctx, key, expire).Err()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a authRepository) ListSessions(ctx context.Context, accountNumber int) ([]string, error) {
+	res, err := a.redisClient.SMembers(ctx, fmt.Sprintf(sessionsKeyFormat, accountNumber)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (a authRepository) RemoveSessions(ctx context.Context, accountNumber int, sessionIDs ...string) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		members = append(members, sessionID)
+	}
+
+	err := a.redisClient.SRem(ctx, fmt.Sprintf(sessionsKeyFormat, accountNumber), members...).Err()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func NewAuthRepository(client *redis.Client) domain.AuthRepository {
 	return &authRepository{redisClient: client}
 }
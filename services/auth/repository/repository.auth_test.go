@@ -62,3 +62,100 @@ func TestAuthRepository_Get(t *testing.T) {
 		assert.NotEqual(t, val, res)
 	})
 }
+
+func TestAuthRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRedis.ExpectDel(key, "other").SetVal(1)
+
+		a := NewAuthRepository(client)
+		n, err := a.Delete(ctx, key, "other")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRedis.ExpectDel(key).SetErr(redis.ErrClosed)
+
+		a := NewAuthRepository(client)
+		_, err := a.Delete(ctx, key)
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthRepository_AddSession(t *testing.T) {
+	ctx := context.Background()
+
+	exp := time.Hour
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRedis.ExpectSAdd("account_sessions:555001", "session").SetVal(1)
+		mockRedis.ExpectExpire("account_sessions:555001", exp).SetVal(true)
+
+		a := NewAuthRepository(client)
+		err := a.AddSession(ctx, 555001, "session", exp)
+		assert.NoError(t, err)
+		assert.NoError(t, mockRedis.ExpectationsWereMet())
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRedis.ExpectSAdd("account_sessions:555001", "session").SetErr(redis.ErrClosed)
+
+		a := NewAuthRepository(client)
+		err := a.AddSession(ctx, 555001, "session", exp)
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthRepository_ListSessions(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRedis.ExpectSMembers("account_sessions:555001").SetVal([]string{"first", "second"})
+
+		a := NewAuthRepository(client)
+		res, err := a.ListSessions(ctx, 555001)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, res)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRedis.ExpectSMembers("account_sessions:555001").SetErr(redis.ErrClosed)
+
+		a := NewAuthRepository(client)
+		_, err := a.ListSessions(ctx, 555001)
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthRepository_RemoveSessions(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRedis.ExpectSRem("account_sessions:555001", "first", "second").SetVal(2)
+
+		a := NewAuthRepository(client)
+		err := a.RemoveSessions(ctx, 555001, "first", "second")
+		assert.NoError(t, err)
+		assert.NoError(t, mockRedis.ExpectationsWereMet())
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRedis.ExpectSRem("account_sessions:555001", "first").SetErr(redis.ErrClosed)
+
+		a := NewAuthRepository(client)
+		err := a.RemoveSessions(ctx, 555001, "first")
+		assert.Error(t, err)
+	})
+}
@@ -11,8 +11,44 @@ type AuthMockUseCase struct {
 	mock.Mock
 }
 
-func (a AuthMockUseCase) CreateAuth(ctx context.Context, account domain.Account) (domain.Auth, error) {
-	args := a.Called(ctx, account)
+func (a *AuthMockUseCase) CreateAuth(ctx context.Context, account domain.Account, userAgent string) (domain.Auth, error) {
+	args := a.Called(ctx, account, userAgent)
 
 	return args.Get(0).(domain.Auth), args.Error(1)
 }
+
+func (a *AuthMockUseCase) ValidateAccessToken(ctx context.Context, token string) (domain.AccessClaims, error) {
+	args := a.Called(ctx, token)
+
+	return args.Get(0).(domain.AccessClaims), args.Error(1)
+}
+
+func (a *AuthMockUseCase) Refresh(ctx context.Context, refreshToken string) (domain.Auth, error) {
+	args := a.Called(ctx, refreshToken)
+
+	return args.Get(0).(domain.Auth), args.Error(1)
+}
+
+func (a *AuthMockUseCase) Logout(ctx context.Context, claims domain.AccessClaims) error {
+	args := a.Called(ctx, claims)
+
+	return args.Error(0)
+}
+
+func (a *AuthMockUseCase) LogoutAll(ctx context.Context, accountNumber int) error {
+	args := a.Called(ctx, accountNumber)
+
+	return args.Error(0)
+}
+
+func (a *AuthMockUseCase) ListSessions(ctx context.Context, accountNumber int) ([]domain.Session, error) {
+	args := a.Called(ctx, accountNumber)
+
+	return args.Get(0).([]domain.Session), args.Error(1)
+}
+
+func (a *AuthMockUseCase) JWKS() domain.JWKS {
+	args := a.Called()
+
+	return args.Get(0).(domain.JWKS)
+}
@@ -2,6 +2,9 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
 	"strconv"
 	"time"
 
@@ -10,57 +13,87 @@ import (
 	"github.com/oniharnantyo/golang-backend-example/domain"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 )
 
+const (
+	sessionKeyPrefix        = "session:"
+	rotatedRefreshKeyPrefix = "rotated:"
+)
+
 type authUseCase struct {
 	authRepository                domain.AuthRepository
-	AccessSecret                  string
+	accountRepository             domain.AccountRepository
+	keyStore                      domain.KeyStore
 	AccessSecretExpireAfterMinute int
 	RefreshSecret                 string
 	RefreshSecretExpireAfterDay   int
+	Issuer                        string
+	Audience                      string
 }
 
-func (a authUseCase) CreateAuth(ctx context.Context, account domain.Account) (domain.Auth, error) {
+func (a authUseCase) CreateAuth(ctx context.Context, account domain.Account, userAgent string) (domain.Auth, error) {
+	now := time.Now()
+	session := domain.Session{
+		ID:            uuid.New().String(),
+		AccountNumber: account.AccountNumber,
+		UserAgent:     userAgent,
+		Role:          account.Role,
+		CreatedAt:     now,
+		RefreshedAt:   now,
+	}
+
+	return a.issueTokens(ctx, account.AccountNumber, session)
+}
+
+// issueTokens signs a new access and refresh token pair for the session and
+// stores both UUIDs together with the session.
+func (a authUseCase) issueTokens(ctx context.Context, accountNumber int, session domain.Session) (domain.Auth, error) {
+	now := time.Now()
 	tokenData := domain.Auth{
 		AccessUuid:           uuid.New().String(),
 		RefreshUuid:          uuid.New().String(),
-		AccessTokenExpireAt:  time.Now().Add(time.Minute * time.Duration(a.AccessSecretExpireAfterMinute)).Unix(),
-		RefreshTokenExpireAt: time.Now().Add(time.Hour * 24 * time.Duration(a.RefreshSecretExpireAfterDay)).Unix(),
+		AccessTokenExpireAt:  now.Add(a.accessExpire()).Unix(),
+		RefreshTokenExpireAt: now.Add(a.refreshExpire()).Unix(),
+		SessionID:            session.ID,
 	}
 
 	accessClaims := domain.AccessClaims{
 		StandardClaims: jwt.StandardClaims{
-			Audience:  "",
-			ExpiresAt: time.Now().Add(time.Minute * time.Duration(a.AccessSecretExpireAfterMinute)).Unix(),
-			Id:        "",
-			IssuedAt:  0,
-			Issuer:    "",
-			NotBefore: 0,
-			Subject:   "",
+			Audience:  a.Audience,
+			ExpiresAt: tokenData.AccessTokenExpireAt,
+			Id:        tokenData.AccessUuid,
+			IssuedAt:  now.Unix(),
+			Issuer:    a.Issuer,
+			Subject:   strconv.Itoa(accountNumber),
 		},
-		Account: &account,
+		SessionID: session.ID,
+		Role:      session.Role,
 	}
 
-	at := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err := at.SignedString([]byte(a.AccessSecret))
+	signingKey := a.keyStore.SigningKey()
+
+	at := jwt.NewWithClaims(signingKey.Method, accessClaims)
+	at.Header["kid"] = signingKey.Kid
+	accessToken, err := at.SignedString(signingKey.Private)
 	if err != nil {
 		return domain.Auth{}, errors.Wrap(err, "authUseCase/CreateAuth/SignedString")
 	}
 	tokenData.AccessToken = accessToken
 
-	//Refresh Claims
+	// Refresh tokens are only ever verified by this service, so they keep the
+	// symmetric refresh secret instead of the published keys.
 	refreshClaims := domain.RefreshClaims{
 		StandardClaims: jwt.StandardClaims{
-			Audience:  "",
-			ExpiresAt: time.Now().Add(time.Hour * 24 * time.Duration(a.RefreshSecretExpireAfterDay)).Unix(),
-			Id:        "",
-			IssuedAt:  0,
-			Issuer:    "",
-			NotBefore: 0,
-			Subject:   "",
+			Audience:  a.Audience,
+			ExpiresAt: tokenData.RefreshTokenExpireAt,
+			Id:        tokenData.RefreshUuid,
+			IssuedAt:  now.Unix(),
+			Issuer:    a.Issuer,
+			Subject:   strconv.Itoa(accountNumber),
 		},
-		Account: &account,
+		SessionID: session.ID,
 	}
 
 	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
@@ -72,31 +105,335 @@ func (a authUseCase) CreateAuth(ctx context.Context, account domain.Account) (do
 	tokenData.RefreshToken = refreshToken
 
 	//Save token to Redis
-	err = a.authRepository.Set(ctx, tokenData.AccessUuid, strconv.Itoa(account.AccountNumber), time.Duration(a.AccessSecretExpireAfterMinute)*time.Minute)
+	err = a.authRepository.Set(ctx, tokenData.AccessUuid, strconv.Itoa(accountNumber), a.accessExpire())
 	if err != nil {
 		return domain.Auth{}, errors.Wrap(err, "authUseCase/CreateAuth/SetAccessID")
 	}
 
-	err = a.authRepository.Set(ctx, tokenData.RefreshUuid, strconv.Itoa(account.AccountNumber), time.Duration(a.RefreshSecretExpireAfterDay)*24*time.Hour)
+	err = a.authRepository.Set(ctx, tokenData.RefreshUuid, strconv.Itoa(accountNumber), a.refreshExpire())
 	if err != nil {
 		return domain.Auth{}, errors.Wrap(err, "authUseCase/CreateAuth/SetRefreshID")
 	}
 
+	session.AccessUuid = tokenData.AccessUuid
+	session.RefreshUuid = tokenData.RefreshUuid
+
+	sessionData, err := json.Marshal(session)
+	if err != nil {
+		return domain.Auth{}, errors.Wrap(err, "authUseCase/CreateAuth/MarshalSession")
+	}
+
+	err = a.authRepository.Set(ctx, sessionKeyPrefix+session.ID, string(sessionData), a.refreshExpire())
+	if err != nil {
+		return domain.Auth{}, errors.Wrap(err, "authUseCase/CreateAuth/SetSession")
+	}
+
+	err = a.authRepository.AddSession(ctx, accountNumber, session.ID, a.refreshExpire())
+	if err != nil {
+		return domain.Auth{}, errors.Wrap(err, "authUseCase/CreateAuth/AddSession")
+	}
+
 	return tokenData, nil
 }
 
+// ValidateAccessToken checks the signature and expiry of an access token and
+// that its session is still stored, so revoked tokens are refused.
+func (a authUseCase) ValidateAccessToken(ctx context.Context, token string) (domain.AccessClaims, error) {
+	var claims domain.AccessClaims
+	err := a.parseToken(token, a.accessKey, &claims, &claims.StandardClaims)
+	if err != nil {
+		return domain.AccessClaims{}, err
+	}
+
+	accountNumber, err := a.authRepository.Get(ctx, claims.Id)
+	if err != nil {
+		return domain.AccessClaims{}, errors.Wrap(domain.ErrInvalidToken, "session not found")
+	}
+
+	if accountNumber != claims.Subject {
+		return domain.AccessClaims{}, errors.Wrap(domain.ErrInvalidToken, "session belongs to another account")
+	}
+
+	return claims, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair and revokes the old
+// pair. A refresh token that was already rotated means it leaked, so the whole
+// session it belongs to is revoked.
+func (a authUseCase) Refresh(ctx context.Context, refreshToken string) (domain.Auth, error) {
+	var claims domain.RefreshClaims
+	err := a.parseToken(refreshToken, a.refreshKey, &claims, &claims.StandardClaims)
+	if err != nil {
+		return domain.Auth{}, err
+	}
+
+	if claims.SessionID == "" {
+		return domain.Auth{}, domain.ErrInvalidToken
+	}
+
+	_, err = a.authRepository.Get(ctx, rotatedRefreshKeyPrefix+claims.Id)
+	if err == nil {
+		err = a.revokeSession(ctx, claims.AccountNumber(), claims.SessionID)
+		if err != nil {
+			return domain.Auth{}, errors.Wrap(err, "authUseCase/Refresh/revokeSession")
+		}
+		return domain.Auth{}, domain.ErrRefreshTokenReused
+	}
+	if err != redis.Nil {
+		return domain.Auth{}, errors.Wrap(err, "authUseCase/Refresh/GetRotated")
+	}
+
+	// The session and the account are read before the token is used up, a
+	// retry after a failed read must not look like a reuse.
+	session, err := a.getSession(ctx, claims.SessionID)
+	if errors.Cause(err) == redis.Nil {
+		return domain.Auth{}, errors.Wrap(domain.ErrInvalidToken, "session not found")
+	}
+	if err != nil {
+		return domain.Auth{}, errors.Wrap(err, "authUseCase/Refresh/getSession")
+	}
+
+	// The role may have changed since the login, and a deleted account must
+	// not keep its sessions.
+	account, err := a.accountRepository.GetByAccountNumber(ctx, claims.AccountNumber())
+	if errors.Cause(err) == sql.ErrNoRows {
+		err = a.revokeSession(ctx, claims.AccountNumber(), claims.SessionID)
+		if err != nil {
+			return domain.Auth{}, errors.Wrap(err, "authUseCase/Refresh/revokeSession")
+		}
+		return domain.Auth{}, errors.Wrap(domain.ErrInvalidToken, "account not found")
+	}
+	if err != nil {
+		return domain.Auth{}, errors.Wrap(err, "authUseCase/Refresh/GetByAccountNumber")
+	}
+
+	// Deleting the refresh UUID claims it, only one concurrent refresh wins.
+	n, err := a.authRepository.Delete(ctx, claims.Id)
+	if err != nil {
+		return domain.Auth{}, errors.Wrap(err, "authUseCase/Refresh/DeleteRefreshID")
+	}
+	if n == 0 {
+		return domain.Auth{}, errors.Wrap(domain.ErrInvalidToken, "refresh token revoked")
+	}
+
+	err = a.authRepository.Set(ctx, rotatedRefreshKeyPrefix+claims.Id, claims.SessionID,
+		time.Until(time.Unix(claims.ExpiresAt, 0)))
+	if err != nil {
+		return domain.Auth{}, errors.Wrap(err, "authUseCase/Refresh/SetRotated")
+	}
+
+	_, err = a.authRepository.Delete(ctx, session.AccessUuid)
+	if err != nil {
+		return domain.Auth{}, errors.Wrap(err, "authUseCase/Refresh/DeleteAccessID")
+	}
+
+	session.Role = account.Role
+
+	session.RefreshedAt = time.Now()
+
+	return a.issueTokens(ctx, claims.AccountNumber(), session)
+}
+
+// JWKS returns the public keys access tokens can be verified with.
+func (a authUseCase) JWKS() domain.JWKS {
+	return a.keyStore.JWKS()
+}
+
+// accessKey picks the verification key named by the kid header and refuses
+// tokens whose alg header does not match that key.
+func (a authUseCase) accessKey(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	key, err := a.keyStore.VerificationKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, errors.Errorf("unexpected signing method %v", t.Header["alg"])
+	}
+
+	return key.Public, nil
+}
+
+func (a authUseCase) refreshKey(t *jwt.Token) (interface{}, error) {
+	if t.Method != jwt.SigningMethodHS256 {
+		return nil, errors.Errorf("unexpected signing method %v", t.Header["alg"])
+	}
+
+	return []byte(a.RefreshSecret), nil
+}
+
+// parseToken verifies the signature and the registered claims of token and
+// decodes it into claims. Every failure is reported as domain.ErrInvalidToken.
+func (a authUseCase) parseToken(token string, keyFunc jwt.Keyfunc, claims jwt.Claims, std *jwt.StandardClaims) error {
+	_, err := jwt.ParseWithClaims(token, claims, keyFunc)
+	if err != nil {
+		return errors.Wrap(domain.ErrInvalidToken, err.Error())
+	}
+
+	if !std.VerifyIssuer(a.Issuer, true) {
+		return errors.Wrap(domain.ErrInvalidToken, "unexpected issuer")
+	}
+
+	if !std.VerifyAudience(a.Audience, true) {
+		return errors.Wrap(domain.ErrInvalidToken, "unexpected audience")
+	}
+
+	if std.IssuedAt == 0 {
+		return errors.Wrap(domain.ErrInvalidToken, "missing iat")
+	}
+
+	if std.Id == "" {
+		return errors.Wrap(domain.ErrInvalidToken, "missing jti")
+	}
+
+	accountNumber, err := strconv.Atoi(std.Subject)
+	if err != nil || accountNumber <= 0 {
+		return errors.Wrap(domain.ErrInvalidToken, "invalid sub")
+	}
+
+	return nil
+}
+
+func (a authUseCase) getSession(ctx context.Context, sessionID string) (domain.Session, error) {
+	sessionData, err := a.authRepository.Get(ctx, sessionKeyPrefix+sessionID)
+	if err != nil {
+		return domain.Session{}, err
+	}
+
+	var session domain.Session
+	err = json.Unmarshal([]byte(sessionData), &session)
+	if err != nil {
+		return domain.Session{}, err
+	}
+
+	return session, nil
+}
+
+// Logout revokes the session the access token belongs to.
+func (a authUseCase) Logout(ctx context.Context, claims domain.AccessClaims) error {
+	if claims.SessionID == "" {
+		return domain.ErrInvalidToken
+	}
+
+	err := a.revokeSession(ctx, claims.AccountNumber(), claims.SessionID)
+	if err != nil {
+		return errors.Wrap(err, "authUseCase/Logout/revokeSession")
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every session of the account.
+func (a authUseCase) LogoutAll(ctx context.Context, accountNumber int) error {
+	sessionIDs, err := a.authRepository.ListSessions(ctx, accountNumber)
+	if err != nil {
+		return errors.Wrap(err, "authUseCase/LogoutAll/ListSessions")
+	}
+
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(sessionIDs)*3)
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, sessionKeyPrefix+sessionID)
+
+		session, err := a.getSession(ctx, sessionID)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, session.AccessUuid, session.RefreshUuid)
+	}
+
+	_, err = a.authRepository.Delete(ctx, keys...)
+	if err != nil {
+		return errors.Wrap(err, "authUseCase/LogoutAll/Delete")
+	}
+
+	err = a.authRepository.RemoveSessions(ctx, accountNumber, sessionIDs...)
+	if err != nil {
+		return errors.Wrap(err, "authUseCase/LogoutAll/RemoveSessions")
+	}
+
+	return nil
+}
+
+// ListSessions returns the active sessions of the account, oldest first.
+// Index entries whose session already expired are skipped.
+func (a authUseCase) ListSessions(ctx context.Context, accountNumber int) ([]domain.Session, error) {
+	sessionIDs, err := a.authRepository.ListSessions(ctx, accountNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "authUseCase/ListSessions/ListSessions")
+	}
+
+	sessions := make([]domain.Session, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		session, err := a.getSession(ctx, sessionID)
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	return sessions, nil
+}
+
+// revokeSession deletes the current token pair of the session, the session
+// itself and its entry in the account index. Sessions that are already gone
+// are not an error.
+func (a authUseCase) revokeSession(ctx context.Context, accountNumber int, sessionID string) error {
+	keys := []string{sessionKeyPrefix + sessionID}
+
+	session, err := a.getSession(ctx, sessionID)
+	if err == nil {
+		keys = append(keys, session.AccessUuid, session.RefreshUuid)
+	}
+
+	_, err = a.authRepository.Delete(ctx, keys...)
+	if err != nil {
+		return err
+	}
+
+	err = a.authRepository.RemoveSessions(ctx, accountNumber, sessionID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a authUseCase) accessExpire() time.Duration {
+	return time.Duration(a.AccessSecretExpireAfterMinute) * time.Minute
+}
+
+func (a authUseCase) refreshExpire() time.Duration {
+	return time.Duration(a.RefreshSecretExpireAfterDay) * 24 * time.Hour
+}
+
 func NewAuthUseCase(
 	a domain.AuthRepository,
-	accessSecret string,
+	ac domain.AccountRepository,
+	keyStore domain.KeyStore,
 	accessSecretExpireAfterMinute int,
 	refreshSecret string,
 	refreshSecretExpireAfterDay int,
+	issuer string,
+	audience string,
 ) domain.AuthUseCase {
 	return &authUseCase{
 		authRepository:                a,
-		AccessSecret:                  accessSecret,
+		accountRepository:             ac,
+		keyStore:                      keyStore,
 		AccessSecretExpireAfterMinute: accessSecretExpireAfterMinute,
 		RefreshSecret:                 refreshSecret,
 		RefreshSecretExpireAfterDay:   refreshSecretExpireAfterDay,
+		Issuer:                        issuer,
+		Audience:                      audience,
 	}
 }
@@ -2,16 +2,29 @@ package usecase
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
 
 	"github.com/go-redis/redis/v8"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/keystore"
 
 	"github.com/stretchr/testify/mock"
 
+	account_repository_mock "github.com/oniharnantyo/golang-backend-example/services/account/repository/mock"
 	auth_repository_mock "github.com/oniharnantyo/golang-backend-example/services/auth/repository/mock"
 )
 
@@ -23,7 +36,7 @@ func TestNewAuthUseCase(t *testing.T) {
 	account := domain.Account{
 		AccountNumber:  1,
 		CustomerNumber: 1,
-		Balance:        1000,
+		Balance:        domain.NewMoney(1000, "IDR"),
 		Email:          "mail@gmail.com",
 		Password:       "secret",
 	}
@@ -31,10 +44,12 @@ func TestNewAuthUseCase(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		authRepo.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
 		authRepo.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		authRepo.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		authRepo.On("AddSession", mock.Anything, account.AccountNumber, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
 
-		authUseCase := NewAuthUseCase(authRepo, "secret", 10, "refreshsecret", 15)
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
 
-		token, err := authUseCase.CreateAuth(ctx, account)
+		token, err := authUseCase.CreateAuth(ctx, account, "curl/7.68.0")
 		assert.NoError(t, err)
 		assert.NotNil(t, token)
 
@@ -44,12 +59,569 @@ func TestNewAuthUseCase(t *testing.T) {
 	t.Run("Failed", func(t *testing.T) {
 		authRepo.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(redis.Nil)
 
-		authUseCase := NewAuthUseCase(authRepo, "secret", 10, "refreshsecret", 15)
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
 
-		token, err := authUseCase.CreateAuth(ctx, account)
+		token, err := authUseCase.CreateAuth(ctx, account, "curl/7.68.0")
 		assert.Error(t, err)
 		assert.Equal(t, domain.Auth{}, token)
 
 		authRepo.AssertExpectations(t)
 	})
 }
+
+func newHMACKeyStore(t *testing.T) domain.KeyStore {
+	keyStore, err := keystore.NewKeyStore("default", keystore.NewHMACKey("default", "secret"))
+	assert.NoError(t, err)
+
+	return keyStore
+}
+
+func TestAuthUseCase_ValidateAccessToken(t *testing.T) {
+	ctx := context.Background()
+
+	account := domain.Account{
+		AccountNumber:  555001,
+		CustomerNumber: 1001,
+		Email:          "mail@gmail.com",
+	}
+
+	validClaims := func() jwt.StandardClaims {
+		return jwt.StandardClaims{
+			Audience:  "audience",
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+			Id:        "access-uuid",
+			IssuedAt:  time.Now().Unix(),
+			Issuer:    "issuer",
+			Subject:   "555001",
+		}
+	}
+
+	signClaims := func(secret string, std jwt.StandardClaims) string {
+		claims := domain.AccessClaims{
+			StandardClaims: std,
+			SessionID:      "session-id",
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = "default"
+
+		signed, err := token.SignedString([]byte(secret))
+		assert.NoError(t, err)
+
+		return signed
+	}
+
+	signToken := func(secret string, expiresAt int64) string {
+		std := validClaims()
+		std.ExpiresAt = expiresAt
+
+		return signClaims(secret, std)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authRepo.On("Get", mock.Anything, "access-uuid").Return("555001", nil).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		claims, err := authUseCase.ValidateAccessToken(ctx, signToken("secret", time.Now().Add(time.Minute).Unix()))
+		assert.NoError(t, err)
+		assert.Equal(t, 555001, claims.AccountNumber())
+		assert.Equal(t, "session-id", claims.SessionID)
+
+		authRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid-signature", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		_, err := authUseCase.ValidateAccessToken(ctx, signToken("other-secret", time.Now().Add(time.Minute).Unix()))
+		assert.Equal(t, domain.ErrInvalidToken, errors.Cause(err))
+
+		authRepo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		_, err := authUseCase.ValidateAccessToken(ctx, signToken("secret", time.Now().Add(-time.Minute).Unix()))
+		assert.Equal(t, domain.ErrInvalidToken, errors.Cause(err))
+	})
+
+	t.Run("Registered-claims", func(t *testing.T) {
+		cases := map[string]func(std *jwt.StandardClaims){
+			"wrong-issuer":   func(std *jwt.StandardClaims) { std.Issuer = "someone-else" },
+			"wrong-audience": func(std *jwt.StandardClaims) { std.Audience = "other-api" },
+			"missing-iat":    func(std *jwt.StandardClaims) { std.IssuedAt = 0 },
+			"missing-jti":    func(std *jwt.StandardClaims) { std.Id = "" },
+			"invalid-sub":    func(std *jwt.StandardClaims) { std.Subject = "mail@gmail.com" },
+		}
+
+		for name, tamper := range cases {
+			t.Run(name, func(t *testing.T) {
+				authRepo := new(auth_repository_mock.AuthMockRepository)
+
+				authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+				std := validClaims()
+				tamper(&std)
+
+				_, err := authUseCase.ValidateAccessToken(ctx, signClaims("secret", std))
+				assert.Equal(t, domain.ErrInvalidToken, errors.Cause(err))
+
+				authRepo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authRepo.On("Get", mock.Anything, "access-uuid").Return("", redis.Nil).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		_, err := authUseCase.ValidateAccessToken(ctx, signToken("secret", time.Now().Add(time.Minute).Unix()))
+		assert.Equal(t, domain.ErrInvalidToken, errors.Cause(err))
+
+		authRepo.AssertExpectations(t)
+	})
+
+	t.Run("Created-token", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authRepo.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Times(3)
+		authRepo.On("AddSession", mock.Anything, account.AccountNumber, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		teller := account
+		teller.Role = domain.RoleTeller
+
+		auth, err := authUseCase.CreateAuth(ctx, teller, "curl/7.68.0")
+		assert.NoError(t, err)
+
+		authRepo.On("Get", mock.Anything, auth.AccessUuid).Return("555001", nil).Once()
+
+		claims, err := authUseCase.ValidateAccessToken(ctx, auth.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleTeller, claims.Role)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.AccessUuid, claims.Id)
+		assert.Equal(t, "555001", claims.Subject)
+		assert.Equal(t, "issuer", claims.Issuer)
+		assert.Equal(t, "audience", claims.Audience)
+		assert.NotZero(t, claims.IssuedAt)
+
+		// The token body must not leak account data.
+		payload, err := jwt.DecodeSegment(strings.Split(auth.AccessToken, ".")[1])
+		assert.NoError(t, err)
+		assert.NotContains(t, string(payload), account.Email)
+
+		authRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthUseCase_Refresh(t *testing.T) {
+	ctx := context.Background()
+
+	account := domain.Account{
+		AccountNumber:  555001,
+		CustomerNumber: 1001,
+		Email:          "mail@gmail.com",
+		Role:           domain.RoleCustomer,
+	}
+
+	login := func(authRepo *auth_repository_mock.AuthMockRepository, accountRepo *account_repository_mock.AccountMockRepository) (domain.AuthUseCase, domain.Auth, string) {
+		authRepo.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Times(3)
+		authRepo.On("AddSession", mock.Anything, account.AccountNumber, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, accountRepo, newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		auth, err := authUseCase.CreateAuth(ctx, account, "curl/7.68.0")
+		assert.NoError(t, err)
+
+		session, err := json.Marshal(domain.Session{
+			ID:            auth.SessionID,
+			AccountNumber: account.AccountNumber,
+			AccessUuid:    auth.AccessUuid,
+			RefreshUuid:   auth.RefreshUuid,
+			Role:          account.Role,
+		})
+		assert.NoError(t, err)
+
+		return authUseCase, auth, string(session)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		accountRepo := new(account_repository_mock.AccountMockRepository)
+		authUseCase, auth, session := login(authRepo, accountRepo)
+
+		accountRepo.On("GetByAccountNumber", mock.Anything, account.AccountNumber).Return(account, nil).Once()
+
+		authRepo.On("Get", mock.Anything, rotatedRefreshKeyPrefix+auth.RefreshUuid).Return("", redis.Nil).Once()
+		authRepo.On("Delete", mock.Anything, []string{auth.RefreshUuid}).Return(int64(1), nil).Once()
+		authRepo.On("Set", mock.Anything, rotatedRefreshKeyPrefix+auth.RefreshUuid, auth.SessionID, mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+auth.SessionID).Return(session, nil).Once()
+		authRepo.On("Delete", mock.Anything, []string{auth.AccessUuid}).Return(int64(1), nil).Once()
+		authRepo.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Times(3)
+		authRepo.On("AddSession", mock.Anything, account.AccountNumber, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+
+		refreshed, err := authUseCase.Refresh(ctx, auth.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.SessionID, refreshed.SessionID)
+		assert.NotEqual(t, auth.RefreshUuid, refreshed.RefreshUuid)
+		assert.NotEqual(t, auth.AccessUuid, refreshed.AccessUuid)
+
+		authRepo.AssertExpectations(t)
+	})
+
+	t.Run("Role-changed", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		accountRepo := new(account_repository_mock.AccountMockRepository)
+		authUseCase, auth, session := login(authRepo, accountRepo)
+
+		promoted := account
+		promoted.Role = domain.RoleAdmin
+		accountRepo.On("GetByAccountNumber", mock.Anything, account.AccountNumber).Return(promoted, nil).Once()
+
+		authRepo.On("Get", mock.Anything, rotatedRefreshKeyPrefix+auth.RefreshUuid).Return("", redis.Nil).Once()
+		authRepo.On("Delete", mock.Anything, []string{auth.RefreshUuid}).Return(int64(1), nil).Once()
+		authRepo.On("Set", mock.Anything, rotatedRefreshKeyPrefix+auth.RefreshUuid, auth.SessionID, mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+auth.SessionID).Return(session, nil).Once()
+		authRepo.On("Delete", mock.Anything, []string{auth.AccessUuid}).Return(int64(1), nil).Once()
+		authRepo.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Times(3)
+		authRepo.On("AddSession", mock.Anything, account.AccountNumber, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+
+		refreshed, err := authUseCase.Refresh(ctx, auth.RefreshToken)
+		assert.NoError(t, err)
+
+		authRepo.On("Get", mock.Anything, refreshed.AccessUuid).Return("555001", nil).Once()
+
+		claims, err := authUseCase.ValidateAccessToken(ctx, refreshed.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleAdmin, claims.Role)
+	})
+
+	t.Run("Account-deleted", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		accountRepo := new(account_repository_mock.AccountMockRepository)
+		authUseCase, auth, session := login(authRepo, accountRepo)
+
+		accountRepo.On("GetByAccountNumber", mock.Anything, account.AccountNumber).Return(domain.Account{}, sql.ErrNoRows).Once()
+
+		authRepo.On("Get", mock.Anything, rotatedRefreshKeyPrefix+auth.RefreshUuid).Return("", redis.Nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+auth.SessionID).Return(session, nil).Twice()
+		authRepo.On("Delete", mock.Anything, []string{sessionKeyPrefix + auth.SessionID, auth.AccessUuid, auth.RefreshUuid}).Return(int64(3), nil).Once()
+		authRepo.On("RemoveSessions", mock.Anything, account.AccountNumber, []string{auth.SessionID}).Return(nil).Once()
+
+		_, err := authUseCase.Refresh(ctx, auth.RefreshToken)
+		assert.Equal(t, domain.ErrInvalidToken, errors.Cause(err))
+
+		authRepo.AssertExpectations(t)
+	})
+
+	t.Run("Account-lookup-failed", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		accountRepo := new(account_repository_mock.AccountMockRepository)
+		authUseCase, auth, session := login(authRepo, accountRepo)
+
+		accountRepo.On("GetByAccountNumber", mock.Anything, account.AccountNumber).Return(domain.Account{}, errors.New("connection reset")).Once()
+
+		authRepo.On("Get", mock.Anything, rotatedRefreshKeyPrefix+auth.RefreshUuid).Return("", redis.Nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+auth.SessionID).Return(session, nil).Once()
+
+		_, err := authUseCase.Refresh(ctx, auth.RefreshToken)
+		assert.Error(t, err)
+		assert.NotEqual(t, domain.ErrInvalidToken, errors.Cause(err))
+		authRepo.AssertNotCalled(t, "Delete", mock.Anything, []string{auth.RefreshUuid})
+
+		// The token was not used up, the retry refreshes normally.
+		accountRepo.On("GetByAccountNumber", mock.Anything, account.AccountNumber).Return(account, nil).Once()
+
+		authRepo.On("Get", mock.Anything, rotatedRefreshKeyPrefix+auth.RefreshUuid).Return("", redis.Nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+auth.SessionID).Return(session, nil).Once()
+		authRepo.On("Delete", mock.Anything, []string{auth.RefreshUuid}).Return(int64(1), nil).Once()
+		authRepo.On("Set", mock.Anything, rotatedRefreshKeyPrefix+auth.RefreshUuid, auth.SessionID, mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		authRepo.On("Delete", mock.Anything, []string{auth.AccessUuid}).Return(int64(1), nil).Once()
+		authRepo.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Times(3)
+		authRepo.On("AddSession", mock.Anything, account.AccountNumber, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+
+		refreshed, err := authUseCase.Refresh(ctx, auth.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.SessionID, refreshed.SessionID)
+
+		authRepo.AssertExpectations(t)
+		accountRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reused", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authUseCase, auth, _ := login(authRepo, new(account_repository_mock.AccountMockRepository))
+
+		// The session has since been refreshed to a newer pair.
+		current, err := json.Marshal(domain.Session{
+			ID:            auth.SessionID,
+			AccountNumber: account.AccountNumber,
+			AccessUuid:    "current-access",
+			RefreshUuid:   "current-refresh",
+		})
+		assert.NoError(t, err)
+
+		authRepo.On("Get", mock.Anything, rotatedRefreshKeyPrefix+auth.RefreshUuid).Return(auth.SessionID, nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+auth.SessionID).Return(string(current), nil).Once()
+		authRepo.On("Delete", mock.Anything, []string{sessionKeyPrefix + auth.SessionID, "current-access", "current-refresh"}).Return(int64(3), nil).Once()
+		authRepo.On("RemoveSessions", mock.Anything, account.AccountNumber, []string{auth.SessionID}).Return(nil).Once()
+
+		_, err = authUseCase.Refresh(ctx, auth.RefreshToken)
+		assert.Equal(t, domain.ErrRefreshTokenReused, err)
+
+		authRepo.AssertExpectations(t)
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		accountRepo := new(account_repository_mock.AccountMockRepository)
+		authUseCase, auth, session := login(authRepo, accountRepo)
+
+		accountRepo.On("GetByAccountNumber", mock.Anything, account.AccountNumber).Return(account, nil).Once()
+
+		authRepo.On("Get", mock.Anything, rotatedRefreshKeyPrefix+auth.RefreshUuid).Return("", redis.Nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+auth.SessionID).Return(session, nil).Once()
+		authRepo.On("Delete", mock.Anything, []string{auth.RefreshUuid}).Return(int64(0), nil).Once()
+
+		_, err := authUseCase.Refresh(ctx, auth.RefreshToken)
+		assert.Equal(t, domain.ErrInvalidToken, errors.Cause(err))
+
+		authRepo.AssertExpectations(t)
+	})
+
+	t.Run("Access-token-presented", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authUseCase, auth, _ := login(authRepo, new(account_repository_mock.AccountMockRepository))
+
+		_, err := authUseCase.Refresh(ctx, auth.AccessToken)
+		assert.Equal(t, domain.ErrInvalidToken, errors.Cause(err))
+
+		authRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthUseCase_Logout(t *testing.T) {
+	ctx := context.Background()
+
+	claims := domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Id: "access-uuid", Subject: "555001"},
+		SessionID:      "session-id",
+	}
+
+	session, err := json.Marshal(domain.Session{
+		ID:            "session-id",
+		AccountNumber: 555001,
+		AccessUuid:    "access-uuid",
+		RefreshUuid:   "refresh-uuid",
+	})
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+"session-id").Return(string(session), nil).Once()
+		authRepo.On("Delete", mock.Anything, []string{sessionKeyPrefix + "session-id", "access-uuid", "refresh-uuid"}).Return(int64(3), nil).Once()
+		authRepo.On("RemoveSessions", mock.Anything, 555001, []string{"session-id"}).Return(nil).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		err := authUseCase.Logout(ctx, claims)
+		assert.NoError(t, err)
+
+		authRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+"session-id").Return(string(session), nil).Once()
+		authRepo.On("Delete", mock.Anything, mock.Anything).Return(int64(0), redis.ErrClosed).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		err := authUseCase.Logout(ctx, claims)
+		assert.Error(t, err)
+
+		authRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthUseCase_LogoutAll(t *testing.T) {
+	ctx := context.Background()
+
+	session, err := json.Marshal(domain.Session{
+		ID:            "first",
+		AccountNumber: 555001,
+		AccessUuid:    "access-uuid",
+		RefreshUuid:   "refresh-uuid",
+	})
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authRepo.On("ListSessions", mock.Anything, 555001).Return([]string{"first", "expired"}, nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+"first").Return(string(session), nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+"expired").Return("", redis.Nil).Once()
+		authRepo.On("Delete", mock.Anything, []string{sessionKeyPrefix + "first", "access-uuid", "refresh-uuid", sessionKeyPrefix + "expired"}).Return(int64(3), nil).Once()
+		authRepo.On("RemoveSessions", mock.Anything, 555001, []string{"first", "expired"}).Return(nil).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		err := authUseCase.LogoutAll(ctx, 555001)
+		assert.NoError(t, err)
+
+		authRepo.AssertExpectations(t)
+	})
+
+	t.Run("No-sessions", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authRepo.On("ListSessions", mock.Anything, 555001).Return([]string{}, nil).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		err := authUseCase.LogoutAll(ctx, 555001)
+		assert.NoError(t, err)
+
+		authRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthUseCase_ListSessions(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Now()
+
+	older, err := json.Marshal(domain.Session{ID: "older", AccountNumber: 555001, UserAgent: "curl/7.68.0", CreatedAt: now.Add(-time.Hour)})
+	assert.NoError(t, err)
+	newer, err := json.Marshal(domain.Session{ID: "newer", AccountNumber: 555001, UserAgent: "Mozilla/5.0", CreatedAt: now})
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authRepo.On("ListSessions", mock.Anything, 555001).Return([]string{"newer", "expired", "older"}, nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+"newer").Return(string(newer), nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+"expired").Return("", redis.Nil).Once()
+		authRepo.On("Get", mock.Anything, sessionKeyPrefix+"older").Return(string(older), nil).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		sessions, err := authUseCase.ListSessions(ctx, 555001)
+		assert.NoError(t, err)
+		assert.Len(t, sessions, 2)
+		assert.Equal(t, "older", sessions[0].ID)
+		assert.Equal(t, "newer", sessions[1].ID)
+		assert.Equal(t, "Mozilla/5.0", sessions[1].UserAgent)
+
+		authRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authRepo.On("ListSessions", mock.Anything, 555001).Return([]string(nil), redis.ErrClosed).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), newHMACKeyStore(t), 10, "refreshsecret", 15, "issuer", "audience")
+
+		_, err := authUseCase.ListSessions(ctx, 555001)
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthUseCase_KeyRotation(t *testing.T) {
+	ctx := context.Background()
+
+	account := domain.Account{AccountNumber: 555001}
+
+	_, oldPrivate, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	newPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	oldKey := domain.SigningKey{Kid: "old", Method: keystore.SigningMethodEdDSA, Private: oldPrivate, Public: oldPrivate.Public()}
+	newKey := domain.SigningKey{Kid: "new", Method: jwt.SigningMethodRS256, Private: newPrivate, Public: &newPrivate.PublicKey}
+
+	login := func(keyStore domain.KeyStore) (*auth_repository_mock.AuthMockRepository, domain.AuthUseCase, domain.Auth) {
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authRepo.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Times(3)
+		authRepo.On("AddSession", mock.Anything, account.AccountNumber, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), keyStore, 10, "refreshsecret", 15, "issuer", "audience")
+
+		auth, err := authUseCase.CreateAuth(ctx, account, "curl/7.68.0")
+		assert.NoError(t, err)
+
+		return authRepo, authUseCase, auth
+	}
+
+	beforeRotation, err := keystore.NewKeyStore("old", oldKey)
+	assert.NoError(t, err)
+	afterRotation, err := keystore.NewKeyStore("new", newKey, domain.SigningKey{Kid: "old", Method: keystore.SigningMethodEdDSA, Public: oldKey.Public})
+	assert.NoError(t, err)
+
+	t.Run("Token-signed-before-rotation", func(t *testing.T) {
+		authRepo, _, auth := login(beforeRotation)
+		authRepo.On("Get", mock.Anything, auth.AccessUuid).Return("555001", nil).Once()
+
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), afterRotation, 10, "refreshsecret", 15, "issuer", "audience")
+
+		claims, err := authUseCase.ValidateAccessToken(ctx, auth.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, 555001, claims.AccountNumber())
+	})
+
+	t.Run("Token-signed-after-rotation", func(t *testing.T) {
+		authRepo, authUseCase, auth := login(afterRotation)
+		authRepo.On("Get", mock.Anything, auth.AccessUuid).Return("555001", nil).Once()
+
+		token, _, err := new(jwt.Parser).ParseUnverified(auth.AccessToken, &domain.AccessClaims{})
+		assert.NoError(t, err)
+		assert.Equal(t, "new", token.Header["kid"])
+		assert.Equal(t, "RS256", token.Header["alg"])
+
+		_, err = authUseCase.ValidateAccessToken(ctx, auth.AccessToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unknown-kid", func(t *testing.T) {
+		_, _, auth := login(afterRotation)
+
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), beforeRotation, 10, "refreshsecret", 15, "issuer", "audience")
+
+		_, err := authUseCase.ValidateAccessToken(ctx, auth.AccessToken)
+		assert.Equal(t, domain.ErrInvalidToken, errors.Cause(err))
+	})
+
+	t.Run("Algorithm-mismatch", func(t *testing.T) {
+		// An HS256 token signed with the public key bytes must not be
+		// accepted for an RSA kid.
+		claims := domain.AccessClaims{
+			StandardClaims: jwt.StandardClaims{
+				Audience:  "audience",
+				ExpiresAt: time.Now().Add(time.Minute).Unix(),
+				Id:        "access-uuid",
+				IssuedAt:  time.Now().Unix(),
+				Issuer:    "issuer",
+				Subject:   "555001",
+			},
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = "new"
+
+		signed, err := token.SignedString(x509.MarshalPKCS1PublicKey(&newPrivate.PublicKey))
+		assert.NoError(t, err)
+
+		authRepo := new(auth_repository_mock.AuthMockRepository)
+		authUseCase := NewAuthUseCase(authRepo, new(account_repository_mock.AccountMockRepository), afterRotation, 10, "refreshsecret", 15, "issuer", "audience")
+
+		_, err = authUseCase.ValidateAccessToken(ctx, signed)
+		assert.Equal(t, domain.ErrInvalidToken, errors.Cause(err))
+	})
+}
@@ -1,91 +1,175 @@
 package delivery_http_customer
 
 import (
-	"database/sql"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/oniharnantyo/golang-backend-example/domain"
-
-	"github.com/pkg/errors"
+	"github.com/oniharnantyo/golang-backend-example/middleware"
+	"github.com/oniharnantyo/golang-backend-example/util"
 
 	"github.com/sirupsen/logrus"
 )
 
+// customerCursorKind keeps a cursor of another listing from being used here.
+const customerCursorKind = "customer"
+
 type CustomerHandler struct {
 	customerUseCase domain.CustomerUseCase
+	cursorSigner    util.CursorSigner
 	logger          *logrus.Logger
 }
 
-func NewCustomerHandler(r *gin.Engine, c domain.CustomerUseCase, l *logrus.Logger) *gin.Engine {
-	handler := &CustomerHandler{customerUseCase: c, logger: l}
+func NewCustomerHandler(r *gin.Engine, c domain.CustomerUseCase, au domain.AuthUseCase, cs util.CursorSigner, l *logrus.Logger) *gin.Engine {
+	handler := &CustomerHandler{customerUseCase: c, cursorSigner: cs, logger: l}
+
+	auth := middleware.JWT(au)
+	staff := middleware.RequireRole(domain.RoleTeller, domain.RoleAdmin)
+	admin := middleware.RequireRole(domain.RoleAdmin)
 
-	r.GET("/customer", handler.HandlerGetCustomerList)
-	r.GET("/customer/:customer_number", handler.HandlerGetCustomerByCustomerNumber)
-	r.POST("/customer", handler.HandlerCustomerStore)
-	r.PUT("/customer", handler.HandlerCustomerUpdate)
-	r.DELETE("/customer", handler.HandlerCustomerDelete)
+	r.GET("/customer", auth, staff, handler.HandlerGetCustomerList)
+	r.GET("/customer/search", auth, staff, handler.HandlerSearchCustomer)
+	r.GET("/customer/:customer_number", auth, staff, handler.HandlerGetCustomerByCustomerNumber)
+	r.POST("/customer", auth, staff, handler.HandlerCustomerStore)
+	r.PUT("/customer", auth, staff, handler.HandlerCustomerUpdate)
+	r.DELETE("/customer", auth, admin, handler.HandlerCustomerDelete)
 
 	return r
 }
 
 func (c *CustomerHandler) HandlerGetCustomerList(ctx *gin.Context) {
 	var param domain.CustomerListParam
-	err := ctx.BindQuery(&param)
+	err := ctx.ShouldBindQuery(&param)
 	if err != nil {
 		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerGetCustomerList/ParseQueryParams", err)
-		ctx.AbortWithError(http.StatusBadRequest, err)
+		ctx.Error(domain.ErrBadRequest)
 		return
 	}
 
-	customers, err := c.customerUseCase.List(ctx, param)
+	param.Paginate()
+
+	sort, err := domain.CustomerSort.Parse(param.Sort, param.Order)
+	if err != nil {
+		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerGetCustomerList/ParseSort", err)
+		ctx.Error(domain.ErrInvalidSort)
+		return
+	}
+
+	if param.Cursor != "" {
+		var after domain.CustomerCursor
+		err = c.cursorSigner.Decode(customerCursorKind, param.Cursor, &after)
+		if err == nil && after.Sort != util.FormatSort(sort) {
+			err = util.ErrInvalidCursor
+		}
+		if err != nil {
+			c.logger.Errorf("%s : %v", "CustomerHandler/HandlerGetCustomerList/DecodeCursor", err)
+			ctx.Error(domain.ErrInvalidCursor)
+			return
+		}
+		param.After = &after
+	}
+
+	// One customer more than the page tells whether a next page exists.
+	page := param
+	page.Limit++
+
+	customers, total, err := c.customerUseCase.List(ctx, page)
 	if err != nil {
 		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerGetCustomerList/List", err)
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, customers)
+	var nextCursor string
+	if len(customers) > param.Limit {
+		customers = customers[:param.Limit]
+		last := customers[len(customers)-1]
+		nextCursor, err = c.cursorSigner.Encode(customerCursorKind, domain.CustomerCursor{
+			Sort:           util.FormatSort(sort),
+			Name:           last.Name,
+			CustomerNumber: last.CustomerNumber,
+		})
+		if err != nil {
+			c.logger.Errorf("%s : %v", "CustomerHandler/HandlerGetCustomerList/EncodeCursor", err)
+			ctx.Error(err)
+			return
+		}
+	}
+
+	if customers == nil {
+		customers = []domain.Customer{}
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{
+		Data: customers,
+		Meta: util.NewMeta(ctx.Request.URL, param.Filter, total, nextCursor),
+	})
 	return
 }
 
+func (c *CustomerHandler) HandlerSearchCustomer(ctx *gin.Context) {
+	var param domain.CustomerSearchParam
+	err := ctx.ShouldBindQuery(&param)
+	if err != nil {
+		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerSearchCustomer/ShouldBindQuery", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	if param.Limit <= 0 {
+		param.Limit = util.DefaultLimit
+	}
+	if param.Limit > util.MaxLimit {
+		param.Limit = util.MaxLimit
+	}
+
+	results, err := c.customerUseCase.Search(ctx, param)
+	if err != nil {
+		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerSearchCustomer/Search", err)
+		ctx.Error(err)
+		return
+	}
+
+	if results == nil {
+		results = []domain.CustomerSearchResult{}
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: results})
+}
+
 func (c *CustomerHandler) HandlerGetCustomerByCustomerNumber(ctx *gin.Context) {
 	customerNumber, err := strconv.Atoi(ctx.Param("customer_number"))
 	if err != nil {
 		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerGetCustomerByCustomerNumber/parseCustomerNumber", err)
-		ctx.AbortWithError(http.StatusBadRequest, err)
+		ctx.Error(domain.ErrBadRequest)
 		return
 	}
 
 	customer, err := c.customerUseCase.GetByCustomerNumber(ctx, customerNumber)
 	if err != nil {
 		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerGetCustomerByCustomerNumber/GetByCustomerNumber", err)
-		if errors.Cause(err) == sql.ErrNoRows {
-			ctx.AbortWithError(http.StatusNotFound, errors.New("Customer not exists"))
-			return
-		}
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, customer)
+	ctx.JSON(http.StatusOK, util.Response{Data: customer})
 	return
 }
 
 func (c *CustomerHandler) HandlerCustomerStore(ctx *gin.Context) {
 	var param domain.Customer
-	err := ctx.Bind(&param)
+	err := ctx.ShouldBind(&param)
 	if err != nil {
 		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerCustomerStore/ParseBodyData", err)
-		ctx.AbortWithError(http.StatusBadRequest, err)
+		ctx.Error(domain.ErrBadRequest)
 		return
 	}
 
 	err = c.customerUseCase.Store(ctx, &param)
 	if err != nil {
 		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerCustomerStore/Store", err)
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		ctx.Error(err)
 		return
 	}
 
@@ -96,17 +180,17 @@ func (c *CustomerHandler) HandlerCustomerStore(ctx *gin.Context) {
 func (c *CustomerHandler) HandlerCustomerUpdate(ctx *gin.Context) {
 	var param domain.Customer
 
-	err := ctx.Bind(&param)
+	err := ctx.ShouldBind(&param)
 	if err != nil {
 		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerCustomerUpdate/ParseBodyData", err)
-		ctx.AbortWithError(http.StatusBadRequest, err)
+		ctx.Error(domain.ErrBadRequest)
 		return
 	}
 
 	err = c.customerUseCase.Update(ctx, &param)
 	if err != nil {
 		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerCustomerUpdate/Store", err)
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		ctx.Error(err)
 		return
 	}
 
@@ -117,17 +201,17 @@ func (c *CustomerHandler) HandlerCustomerUpdate(ctx *gin.Context) {
 func (c *CustomerHandler) HandlerCustomerDelete(ctx *gin.Context) {
 	var param domain.Customer
 
-	err := ctx.Bind(&param)
+	err := ctx.ShouldBind(&param)
 	if err != nil {
 		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerCustomerDelete/ParseBodyData", err)
-		ctx.AbortWithError(http.StatusBadRequest, err)
+		ctx.Error(domain.ErrBadRequest)
 		return
 	}
 
 	err = c.customerUseCase.Delete(ctx, &param)
 	if err != nil {
 		c.logger.Errorf("%s : %v", "CustomerHandler/HandlerCustomerDelete/Store", err)
-		ctx.AbortWithError(http.StatusInternalServerError, err)
+		ctx.Error(err)
 		return
 	}
 
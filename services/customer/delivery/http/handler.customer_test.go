@@ -2,15 +2,17 @@ package delivery_http_customer
 
 import (
 	"bytes"
-	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
-	"github.com/gin-gonic/gin"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware/testutil"
+	auth_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/auth/usecase/mock"
 	customer_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/customer/usecase/mock"
+	"github.com/oniharnantyo/golang-backend-example/util"
 
 	"github.com/sirupsen/logrus"
 
@@ -21,6 +23,16 @@ import (
 	"github.com/bxcodec/faker"
 )
 
+func authAs(accountNumber string, role string) *auth_usecase_mock.AuthMockUseCase {
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Subject: accountNumber},
+		Role:           role,
+	}, nil)
+
+	return mockAuthUseCase
+}
+
 func TestCustomerHandler_HandlerGetCustomerList(t *testing.T) {
 	var mockCustomer domain.Customer
 	logger := logrus.New()
@@ -33,21 +45,84 @@ func TestCustomerHandler_HandlerGetCustomerList(t *testing.T) {
 	mockCustomers := make([]domain.Customer, 0)
 	mockCustomers = append(mockCustomers, mockCustomer)
 
-	mockCustomerUseCase.On("List", mock.Anything, mock.AnythingOfType("domain.CustomerListParam")).Return(mockCustomers, nil).Once()
+	mockCustomerUseCase.On("List", mock.Anything, domain.CustomerListParam{
+		Filter: util.Filter{Limit: 11, Order: "asc"},
+	}).Return(mockCustomers, 11, nil).Once()
 
-	r := gin.Default()
-	r = NewCustomerHandler(r, mockCustomerUseCase, logger)
+	r := testutil.NewRouter()
+	r = NewCustomerHandler(r, mockCustomerUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 	req, err := http.NewRequest(http.MethodGet, "/customer?limit=10&offset=0&search=&order=asc", nil)
 	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
 
 	rec := httptest.NewRecorder()
 
 	r.ServeHTTP(rec, req)
+
+	var response struct {
+		Data []domain.Customer `json:"data"`
+		Meta util.Meta         `json:"meta"`
+	}
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
 	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, mockCustomers, response.Data)
+	assert.Equal(t, util.Meta{
+		Total: 11,
+		Limit: 10,
+		Next:  "/customer?limit=10&offset=10&order=asc&search=",
+	}, response.Meta)
 	mockCustomerUseCase.AssertExpectations(t)
 }
 
+func TestCustomerHandler_HandlerSearchCustomer(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockCustomerUseCase := new(customer_usecase_mock.CustomerMockUseCase)
+
+		mockCustomerUseCase.On("Search", mock.Anything, domain.CustomerSearchParam{Q: "bob mar", Limit: util.DefaultLimit}).
+			Return([]domain.CustomerSearchResult{
+				{Customer: domain.Customer{CustomerNumber: 1001, Name: "Bob Martin"}, Rank: 0.75},
+			}, nil).Once()
+
+		r := testutil.NewRouter()
+		r = NewCustomerHandler(r, mockCustomerUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/customer/search?q=bob+mar", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"data":[{"account_number":1001,"name":"Bob Martin","rank":0.75}]}`, rec.Body.String())
+		mockCustomerUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Missing-query", func(t *testing.T) {
+		mockCustomerUseCase := new(customer_usecase_mock.CustomerMockUseCase)
+
+		r := testutil.NewRouter()
+		r = NewCustomerHandler(r, mockCustomerUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/customer/search", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockCustomerUseCase.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+}
+
 func TestCustomerHandler_HandlerGetCustomerByCustomerNumber(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		var mockCustomer domain.Customer
@@ -60,11 +135,12 @@ func TestCustomerHandler_HandlerGetCustomerByCustomerNumber(t *testing.T) {
 
 		mockCustomerUseCase.On("GetByCustomerNumber", mock.Anything, mock.AnythingOfType("int")).Return(mockCustomer, nil).Once()
 
-		r := gin.Default()
-		r = NewCustomerHandler(r, mockCustomerUseCase, logger)
+		r := testutil.NewRouter()
+		r = NewCustomerHandler(r, mockCustomerUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 		req, err := http.NewRequest(http.MethodGet, "/customer/1", nil)
 		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
 
 		rec := httptest.NewRecorder()
 
@@ -82,19 +158,21 @@ func TestCustomerHandler_HandlerGetCustomerByCustomerNumber(t *testing.T) {
 
 		mockCustomerUseCase := new(customer_usecase_mock.CustomerMockUseCase)
 
-		mockCustomerUseCase.On("GetByCustomerNumber", mock.Anything, mock.AnythingOfType("int")).Return(domain.Customer{}, sql.ErrNoRows).Once()
+		mockCustomerUseCase.On("GetByCustomerNumber", mock.Anything, mock.AnythingOfType("int")).Return(domain.Customer{}, domain.ErrCustomerNotFound).Once()
 
-		r := gin.Default()
-		r = NewCustomerHandler(r, mockCustomerUseCase, logger)
+		r := testutil.NewRouter()
+		r = NewCustomerHandler(r, mockCustomerUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 		req, err := http.NewRequest(http.MethodGet, "/customer/1", nil)
 		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
 
 		rec := httptest.NewRecorder()
 
 		r.ServeHTTP(rec, req)
 
 		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, `{"code":"customer_not_found","errors":["Customer not found"]}`, rec.Body.String())
 		mockCustomerUseCase.AssertExpectations(t)
 	})
 }
@@ -110,14 +188,15 @@ func TestCustomerHandler_HandlerCustomerStore(t *testing.T) {
 
 	mockCustomerUseCase.On("Store", mock.Anything, mock.AnythingOfType("*domain.Customer")).Return(mockCustomer, nil).Once()
 
-	r := gin.Default()
-	r = NewCustomerHandler(r, mockCustomerUseCase, logger)
+	r := testutil.NewRouter()
+	r = NewCustomerHandler(r, mockCustomerUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 	reqBody, err := json.Marshal(mockCustomer)
 	assert.NoError(t, err)
 
 	req, err := http.NewRequest(http.MethodPost, "/customer", bytes.NewBuffer(reqBody))
 	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
 
 	rec := httptest.NewRecorder()
 
@@ -137,14 +216,15 @@ func TestCustomerHandler_HandlerCustomerUpdate(t *testing.T) {
 
 	mockCustomerUseCase.On("Update", mock.Anything, mock.AnythingOfType("*domain.Customer")).Return(mockCustomer, nil).Once()
 
-	r := gin.Default()
-	r = NewCustomerHandler(r, mockCustomerUseCase, logger)
+	r := testutil.NewRouter()
+	r = NewCustomerHandler(r, mockCustomerUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 	reqBody, err := json.Marshal(mockCustomer)
 	assert.NoError(t, err)
 
 	req, err := http.NewRequest(http.MethodPut, "/customer", bytes.NewBuffer(reqBody))
 	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
 
 	rec := httptest.NewRecorder()
 
@@ -164,14 +244,15 @@ func TestCustomerHandler_HandlerCustomerDelete(t *testing.T) {
 
 	mockCustomerUseCase.On("Delete", mock.Anything, mock.AnythingOfType("*domain.Customer")).Return(mockCustomer, nil).Once()
 
-	r := gin.Default()
-	r = NewCustomerHandler(r, mockCustomerUseCase, logger)
+	r := testutil.NewRouter()
+	r = NewCustomerHandler(r, mockCustomerUseCase, authAs("555001", domain.RoleAdmin), cursorSigner, logger)
 
 	reqBody, err := json.Marshal(mockCustomer)
 	assert.NoError(t, err)
 
 	req, err := http.NewRequest(http.MethodDelete, "/customer", bytes.NewBuffer(reqBody))
 	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
 
 	rec := httptest.NewRecorder()
 
@@ -179,3 +260,57 @@ func TestCustomerHandler_HandlerCustomerDelete(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, rec.Code)
 	mockCustomerUseCase.AssertExpectations(t)
 }
+
+func TestCustomerHandler_Authorization(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		role   string
+		method string
+		path   string
+	}{
+		{"Customer-cannot-list", domain.RoleCustomer, http.MethodGet, "/customer"},
+		{"Customer-cannot-read", domain.RoleCustomer, http.MethodGet, "/customer/1001"},
+		{"Customer-cannot-create", domain.RoleCustomer, http.MethodPost, "/customer"},
+		{"Teller-cannot-delete", domain.RoleTeller, http.MethodDelete, "/customer"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockCustomerUseCase := new(customer_usecase_mock.CustomerMockUseCase)
+
+			r := testutil.NewRouter()
+			r = NewCustomerHandler(r, mockCustomerUseCase, authAs("555001", c.role), cursorSigner, logger)
+
+			req, err := http.NewRequest(c.method, c.path, bytes.NewBufferString("{}"))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			mockCustomerUseCase.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Missing-token", func(t *testing.T) {
+		mockCustomerUseCase := new(customer_usecase_mock.CustomerMockUseCase)
+
+		r := testutil.NewRouter()
+		r = NewCustomerHandler(r, mockCustomerUseCase, new(auth_usecase_mock.AuthMockUseCase), cursorSigner, logger)
+
+		req, err := http.NewRequest(http.MethodGet, "/customer", nil)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+var cursorSigner = util.NewCursorSigner("cursor_secret")
@@ -19,6 +19,19 @@ func (c *CustomerMockRepository) List(ctx context.Context, param domain.Customer
 	return result.([]domain.Customer), args.Error(1)
 }
 
+func (c *CustomerMockRepository) Count(ctx context.Context, param domain.CustomerListParam) (int, error) {
+	args := c.Called(ctx, param)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (c *CustomerMockRepository) Search(ctx context.Context, param domain.CustomerSearchParam) ([]domain.CustomerSearchResult, error) {
+	args := c.Called(ctx, param)
+	result := args.Get(0)
+
+	return result.([]domain.CustomerSearchResult), args.Error(1)
+}
+
 func (c *CustomerMockRepository) GetByCustomerNumber(ctx context.Context, customerNumber int) (domain.Customer, error) {
 	args := c.Called(ctx, customerNumber)
 	result := args.Get(0)
@@ -43,18 +43,18 @@ func TestCustomerRepository_List(t *testing.T) {
 			customer_number, 
 			name 
 		FROM customer 
-		WHERE 
-			(LOWER(customer_number) LIKE '%%bob%%' OR LOWER(name) LIKE '%%bob%%')
-		ORDER BY name ASC 
-		LIMIT $1 OFFSET $2`)
+		WHERE
+			(LOWER(CAST(customer_number AS TEXT)) LIKE $1 OR LOWER(name) LIKE $1)
+		ORDER BY name ASC, customer_number ASC
+		LIMIT $2 OFFSET $3`)
 
 	prep := mock.ExpectPrepare(query)
 
-	prep.ExpectQuery().WithArgs(limit, offset).WillReturnRows(rows)
+	prep.ExpectQuery().WithArgs("%bob%", limit, offset).WillReturnRows(rows)
 
 	c := NewCustomerRepository(db)
 
-	customers, err := c.List(context.Background(), domain.CustomerListParam{util.Filter{
+	customers, err := c.List(context.Background(), domain.CustomerListParam{Filter: util.Filter{
 		Limit:  limit,
 		Offset: offset,
 		Search: search,
@@ -65,6 +65,136 @@ func TestCustomerRepository_List(t *testing.T) {
 	assert.Len(t, customers, 2)
 }
 
+func TestCustomerRepository_ListAfterCursor(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"customer_number", "name"}).
+		AddRow(1002, "Linus Torvalds")
+
+	query := fmt.Sprintf(`
+		SELECT
+			customer_number,
+			name
+		FROM customer
+		WHERE
+			(name, customer_number) > ($1, $2)
+		ORDER BY name ASC, customer_number ASC
+		LIMIT $3 OFFSET $4`)
+
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs("Bob Martin", 1001, 10, 0).WillReturnRows(rows)
+
+	c := NewCustomerRepository(db)
+
+	customers, err := c.List(context.Background(), domain.CustomerListParam{
+		Filter: util.Filter{Limit: 10},
+		After:  &domain.CustomerCursor{Name: "Bob Martin", CustomerNumber: 1001},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, customers, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCustomerRepository_ListEscapesSearch(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		SELECT
+			customer_number,
+			name
+		FROM customer
+		WHERE
+			(LOWER(CAST(customer_number AS TEXT)) LIKE $1 OR LOWER(name) LIKE $1)
+		ORDER BY name DESC, customer_number DESC
+		LIMIT $2 OFFSET $3`)
+
+	mock.ExpectPrepare(query).ExpectQuery().
+		WithArgs(`%o'\_brien\%%`, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"customer_number", "name"}))
+
+	c := NewCustomerRepository(db)
+
+	customers, err := c.List(context.Background(), domain.CustomerListParam{Filter: util.Filter{
+		Limit:  10,
+		Search: "O'_Brien%",
+		Order:  "DESC",
+	}})
+	assert.NoError(t, err)
+	assert.Empty(t, customers)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCustomerRepository_Search(t *testing.T) {
+	query := fmt.Sprintf(`
+		SELECT
+			customer_number,
+			name,
+			ts_rank(name_tsv, query) + similarity(name, $2) AS rank
+		FROM customer, to_tsquery('simple', $1) query
+		WHERE
+			name_tsv @@ query OR name %% $2
+		ORDER BY rank DESC, customer_number ASC
+		LIMIT $3`)
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		rows := sqlmock.NewRows([]string{"customer_number", "name", "rank"}).
+			AddRow(1001, "Bob Martin", 0.75)
+
+		mock.ExpectPrepare(query).ExpectQuery().
+			WithArgs("bob:* & mar:*", "Bob Mar'!", 20).
+			WillReturnRows(rows)
+
+		c := NewCustomerRepository(db)
+
+		results, err := c.Search(context.Background(), domain.CustomerSearchParam{Q: " Bob Mar'! ", Limit: 20})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.CustomerSearchResult{
+			{Customer: domain.Customer{CustomerNumber: 1001, Name: "Bob Martin"}, Rank: 0.75},
+		}, results)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No-words", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		c := NewCustomerRepository(db)
+
+		_, err := c.Search(context.Background(), domain.CustomerSearchParam{Q: "&|!:*", Limit: 20})
+		assert.Equal(t, domain.ErrInvalidSearch, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCustomerRepository_Count(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*)
+		FROM customer`)
+
+	prep := mock.ExpectPrepare(query)
+
+	prep.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	c := NewCustomerRepository(db)
+
+	total, err := c.Count(context.Background(), domain.CustomerListParam{Filter: util.Filter{Limit: 10}})
+	assert.NoError(t, err)
+	assert.Equal(t, 12, total)
+}
+
 func TestCustomerRepository_GetByCustomerNumber(t *testing.T) {
 	db, mock := initMock()
 
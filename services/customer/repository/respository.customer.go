@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"unicode"
 
+	"github.com/oniharnantyo/golang-backend-example/database"
 	"github.com/oniharnantyo/golang-backend-example/domain"
 	"github.com/oniharnantyo/golang-backend-example/util"
 )
@@ -13,31 +16,53 @@ type customerRepository struct {
 	dbPool *sql.DB
 }
 
+// listQuery has the conditions List and Count share.
+func listQuery(param domain.CustomerListParam) *util.Query {
+	return util.NewQuery().Search(param.Search, "CAST(customer_number AS TEXT)", "name")
+}
+
+// cursorValues returns the values of the sort columns in after.
+func cursorValues(after *domain.CustomerCursor, sort []util.SortField) []interface{} {
+	values := make([]interface{}, len(sort))
+	for i, field := range sort {
+		switch field.Column {
+		case "name":
+			values[i] = after.Name
+		case "customer_number":
+			values[i] = after.CustomerNumber
+		}
+	}
+
+	return values
+}
+
+// List pages by offset, or by keyset on the sort columns after param.After.
 func (c customerRepository) List(ctx context.Context, param domain.CustomerListParam) ([]domain.Customer, error) {
-	var filters []string
+	sort, err := domain.CustomerSort.Parse(param.Sort, param.Order)
+	if err != nil {
+		return nil, domain.ErrInvalidSort
+	}
 
-	if param.Search != "" {
-		filters = append(filters,
-			fmt.Sprintf(`(LOWER(customer_number) LIKE '%%%s%%' OR LOWER(name) LIKE '%%%s%%')`,
-				param.Search, param.Search))
+	query := listQuery(param)
+
+	if param.After != nil {
+		query.After(sort, cursorValues(param.After, sort)...)
 	}
 
-	filterQuery := util.BuildFilterQuery(filters)
+	query.OrderBy(sort).Page(param.Limit, param.Offset)
 
-	stmt, err := c.dbPool.Prepare(fmt.Sprintf(`
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
 		SELECT
 			customer_number,
 			name
 		FROM customer
 			%s
-		ORDER BY name %s
-		LIMIT $1 OFFSET $2
-	`, filterQuery, param.Order))
+	`, query))
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := stmt.QueryContext(ctx, param.Limit, param.Offset)
+	rows, err := stmt.QueryContext(ctx, query.Args()...)
 	if err != nil {
 		return nil, err
 	}
@@ -61,8 +86,93 @@ func (c customerRepository) List(ctx context.Context, param domain.CustomerListP
 	return customers, nil
 }
 
+func (c customerRepository) Count(ctx context.Context, param domain.CustomerListParam) (int, error) {
+	query := listQuery(param)
+
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			COUNT(*)
+		FROM customer
+			%s
+	`, query))
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	err = stmt.QueryRowContext(ctx, query.Args()...).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// prefixQuery turns the words of q into a tsquery that matches names with
+// words starting with each of them. Anything but letters and digits is
+// dropped, so q can't use tsquery operators.
+func prefixQuery(q string) string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+// Search ranks names by the full text match of the words of param.Q and by
+// trigram similarity to it. The similarity alone finds misspelled names.
+func (c customerRepository) Search(ctx context.Context, param domain.CustomerSearchParam) ([]domain.CustomerSearchResult, error) {
+	query := prefixQuery(param.Q)
+	if query == "" {
+		return nil, domain.ErrInvalidSearch
+	}
+
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			customer_number,
+			name,
+			ts_rank(name_tsv, query) + similarity(name, $2) AS rank
+		FROM customer, to_tsquery('simple', $1) query
+		WHERE
+			name_tsv @@ query OR name %% $2
+		ORDER BY rank DESC, customer_number ASC
+		LIMIT $3
+	`))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, query, strings.TrimSpace(param.Q), param.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var results []domain.CustomerSearchResult
+	for rows.Next() {
+		var result domain.CustomerSearchResult
+		err := rows.Scan(
+			&result.CustomerNumber,
+			&result.Name,
+			&result.Rank,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
 func (c customerRepository) GetByCustomerNumber(ctx context.Context, customerNumber int) (domain.Customer, error) {
-	stmt, err := c.dbPool.Prepare(fmt.Sprintf(`
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
 		SELECT
 			customer_number,
 			name
@@ -87,7 +197,7 @@ func (c customerRepository) GetByCustomerNumber(ctx context.Context, customerNum
 }
 
 func (c customerRepository) Store(ctx context.Context, a *domain.Customer) error {
-	stmt, err := c.dbPool.Prepare(fmt.Sprintf(`
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
 		INSERT INTO customer (
 			customer_number,
 			name
@@ -109,7 +219,7 @@ func (c customerRepository) Store(ctx context.Context, a *domain.Customer) error
 }
 
 func (c customerRepository) Update(ctx context.Context, a *domain.Customer) error {
-	stmt, err := c.dbPool.Prepare(fmt.Sprintf(`
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
 		UPDATE customer SET
 			name = $1
 		WHERE
@@ -128,7 +238,7 @@ func (c customerRepository) Update(ctx context.Context, a *domain.Customer) erro
 
 func (c customerRepository) Delete(ctx context.Context, a *domain.Customer) error {
 
-	stmt, err := c.dbPool.Prepare(fmt.Sprintf(`
+	stmt, err := database.Conn(ctx, c.dbPool).PrepareContext(ctx, fmt.Sprintf(`
 		DELETE FROM customer
 		WHERE
 			customer_number = $1
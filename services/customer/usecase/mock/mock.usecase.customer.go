@@ -12,11 +12,18 @@ type CustomerMockUseCase struct {
 	mock.Mock
 }
 
-func (c *CustomerMockUseCase) List(ctx context.Context, param domain.CustomerListParam) ([]domain.Customer, error) {
+func (c *CustomerMockUseCase) List(ctx context.Context, param domain.CustomerListParam) ([]domain.Customer, int, error) {
 	args := c.Called(ctx, param)
 	result := args.Get(0)
 
-	return result.([]domain.Customer), args.Error(1)
+	return result.([]domain.Customer), args.Int(1), args.Error(2)
+}
+
+func (c *CustomerMockUseCase) Search(ctx context.Context, param domain.CustomerSearchParam) ([]domain.CustomerSearchResult, error) {
+	args := c.Called(ctx, param)
+	result := args.Get(0)
+
+	return result.([]domain.CustomerSearchResult), args.Error(1)
 }
 
 func (c *CustomerMockUseCase) GetByCustomerNumber(ctx context.Context, customerNumber int) (domain.Customer, error) {
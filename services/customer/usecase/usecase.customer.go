@@ -15,14 +15,30 @@ type customerUseCase struct {
 	logger             *logrus.Logger
 }
 
-func (c customerUseCase) List(ctx context.Context, param domain.CustomerListParam) ([]domain.Customer, error) {
+func (c customerUseCase) List(ctx context.Context, param domain.CustomerListParam) ([]domain.Customer, int, error) {
 	customers, err := c.customerRepository.List(ctx, param)
 	if err != nil {
 		c.logger.Errorf("customerUseCase/List/List :%v", err)
+		return nil, 0, err
+	}
+
+	total, err := c.customerRepository.Count(ctx, param)
+	if err != nil {
+		c.logger.Errorf("customerUseCase/List/Count :%v", err)
+		return nil, 0, err
+	}
+
+	return customers, total, nil
+}
+
+func (c customerUseCase) Search(ctx context.Context, param domain.CustomerSearchParam) ([]domain.CustomerSearchResult, error) {
+	results, err := c.customerRepository.Search(ctx, param)
+	if err != nil {
+		c.logger.Errorf("customerUseCase/Search/Search :%v", err)
 		return nil, err
 	}
 
-	return customers, nil
+	return results, nil
 }
 
 func (c customerUseCase) GetByCustomerNumber(ctx context.Context, accountNumber int) (domain.Customer, error) {
@@ -30,7 +46,7 @@ func (c customerUseCase) GetByCustomerNumber(ctx context.Context, accountNumber
 	if err != nil {
 		c.logger.Errorf("customerUseCase/GetByCustomerNumber/GetByCustomerNumber :%v", err)
 		if errors.Cause(err) == sql.ErrNoRows {
-			return domain.Customer{}, errors.New("Customer not found")
+			return domain.Customer{}, domain.ErrCustomerNotFound
 		}
 		return domain.Customer{}, err
 	}
@@ -35,12 +35,14 @@ func TestCustomerUseCase_List(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		mockCustomerRepo.On("List", mock.Anything, mock.AnythingOfType("domain.CustomerListParam")).Return(customersData, nil).Once()
+		mockCustomerRepo.On("Count", mock.Anything, mock.AnythingOfType("domain.CustomerListParam")).Return(2, nil).Once()
 
 		customerUseCase := NewCustomerUseCase(mockCustomerRepo, logger)
 
-		cDatas, err := customerUseCase.List(context.Background(), domain.CustomerListParam{})
+		cDatas, total, err := customerUseCase.List(context.Background(), domain.CustomerListParam{})
 		assert.NoError(t, err)
 		assert.NotNil(t, cDatas)
+		assert.Equal(t, 2, total)
 
 		mockCustomerRepo.AssertExpectations(t)
 	})
@@ -50,7 +52,7 @@ func TestCustomerUseCase_List(t *testing.T) {
 
 		customerUseCase := NewCustomerUseCase(mockCustomerRepo, logger)
 
-		cDatas, err := customerUseCase.List(context.Background(), domain.CustomerListParam{})
+		cDatas, _, err := customerUseCase.List(context.Background(), domain.CustomerListParam{})
 		assert.Error(t, err)
 		assert.Nil(t, cDatas)
 
@@ -59,6 +61,41 @@ func TestCustomerUseCase_List(t *testing.T) {
 
 }
 
+func TestCustomerUseCase_Search(t *testing.T) {
+	logger := logrus.New()
+
+	mockCustomerRepo := new(repository_customer_mock.CustomerMockRepository)
+
+	param := domain.CustomerSearchParam{Q: "bob", Limit: 20}
+	results := []domain.CustomerSearchResult{
+		{Customer: domain.Customer{CustomerNumber: 1001, Name: "Bob Martin"}, Rank: 0.75},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockCustomerRepo.On("Search", mock.Anything, param).Return(results, nil).Once()
+
+		customerUseCase := NewCustomerUseCase(mockCustomerRepo, logger)
+
+		found, err := customerUseCase.Search(context.Background(), param)
+		assert.NoError(t, err)
+		assert.Equal(t, results, found)
+
+		mockCustomerRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockCustomerRepo.On("Search", mock.Anything, param).Return([]domain.CustomerSearchResult(nil), domain.ErrInvalidSearch).Once()
+
+		customerUseCase := NewCustomerUseCase(mockCustomerRepo, logger)
+
+		found, err := customerUseCase.Search(context.Background(), param)
+		assert.Equal(t, domain.ErrInvalidSearch, err)
+		assert.Nil(t, found)
+
+		mockCustomerRepo.AssertExpectations(t)
+	})
+}
+
 func TestCustomerUseCase_GetByCustomerNumber(t *testing.T) {
 	logger := logrus.New()
 
@@ -87,7 +124,7 @@ func TestCustomerUseCase_GetByCustomerNumber(t *testing.T) {
 		customerUseCase := NewCustomerUseCase(mockCustomerRepo, logger)
 
 		cData, err := customerUseCase.GetByCustomerNumber(context.Background(), 0)
-		assert.Error(t, err)
+		assert.Equal(t, domain.ErrCustomerNotFound, err)
 		assert.Equal(t, cData, domain.Customer{})
 
 		mockCustomerRepo.AssertExpectations(t)
@@ -0,0 +1,95 @@
+package delivery_http_fxrate
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware"
+	"github.com/oniharnantyo/golang-backend-example/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxImportSize limits the CSV of an import, a rate per currency pair fits
+// many times over.
+const maxImportSize = 1 << 20
+
+type FXRateHandler struct {
+	fxRateUseCase domain.FXRateUseCase
+	logger        *logrus.Logger
+}
+
+func NewFXRateHandler(r *gin.Engine, f domain.FXRateUseCase, au domain.AuthUseCase, l *logrus.Logger) *gin.Engine {
+	handler := &FXRateHandler{fxRateUseCase: f, logger: l}
+
+	auth := middleware.JWT(au)
+	staff := middleware.RequireRole(domain.RoleTeller, domain.RoleAdmin)
+	admin := middleware.RequireRole(domain.RoleAdmin)
+
+	r.GET("/fx-rate", auth, staff, handler.HandlerGetFXRateList)
+	r.PUT("/fx-rate", auth, admin, handler.HandlerFXRateStore)
+	r.POST("/fx-rate/import", auth, admin, handler.HandlerFXRateImport)
+	r.DELETE("/fx-rate/:base_currency/:quote_currency", auth, admin, handler.HandlerFXRateDelete)
+
+	return r
+}
+
+func (f *FXRateHandler) HandlerGetFXRateList(ctx *gin.Context) {
+	rates, err := f.fxRateUseCase.List(ctx)
+	if err != nil {
+		f.logger.Errorf("%s : %v", "FXRateHandler/HandlerGetFXRateList/List", err)
+		ctx.Error(err)
+		return
+	}
+
+	if rates == nil {
+		rates = []domain.FXRate{}
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: rates})
+}
+
+func (f *FXRateHandler) HandlerFXRateStore(ctx *gin.Context) {
+	var param domain.FXRate
+	err := ctx.ShouldBindJSON(&param)
+	if err != nil {
+		f.logger.Errorf("%s : %v", "FXRateHandler/HandlerFXRateStore/ParseBodyData", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	err = f.fxRateUseCase.Store(ctx, &param)
+	if err != nil {
+		f.logger.Errorf("%s : %v", "FXRateHandler/HandlerFXRateStore/Store", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: param})
+}
+
+// HandlerFXRateImport takes the CSV as the request body.
+func (f *FXRateHandler) HandlerFXRateImport(ctx *gin.Context) {
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+
+	imported, err := f.fxRateUseCase.Import(ctx, body)
+	if err != nil {
+		f.logger.Errorf("%s : %v", "FXRateHandler/HandlerFXRateImport/Import", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: domain.FXRateImportResponse{Imported: imported}})
+}
+
+func (f *FXRateHandler) HandlerFXRateDelete(ctx *gin.Context) {
+	err := f.fxRateUseCase.Delete(ctx, ctx.Param("base_currency"), ctx.Param("quote_currency"))
+	if err != nil {
+		f.logger.Errorf("%s : %v", "FXRateHandler/HandlerFXRateDelete/Delete", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
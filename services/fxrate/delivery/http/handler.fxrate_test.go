@@ -0,0 +1,156 @@
+package delivery_http_fxrate
+
+import (
+	"bytes"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware/testutil"
+	auth_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/auth/usecase/mock"
+	fxrate_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/fxrate/usecase/mock"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func authAs(role string) *auth_usecase_mock.AuthMockUseCase {
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Subject: "555001"},
+		Role:           role,
+	}, nil)
+
+	return mockAuthUseCase
+}
+
+func TestFXRateHandler_HandlerGetFXRateList(t *testing.T) {
+	logger := logrus.New()
+
+	updatedAt := time.Date(2021, 4, 20, 10, 0, 0, 0, time.UTC)
+
+	mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+	mockFXRateUseCase.On("List", mock.Anything).Return([]domain.FXRate{
+		{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "15500.25", UpdatedAt: updatedAt},
+	}, nil).Once()
+
+	r := testutil.NewRouter()
+	r = NewFXRateHandler(r, mockFXRateUseCase, authAs(domain.RoleTeller), logger)
+
+	req, err := http.NewRequest(http.MethodGet, "/fx-rate", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
+
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"data":[{"base_currency":"USD","quote_currency":"IDR","rate":"15500.25","updated_at":"2021-04-20T10:00:00Z"}]}`, rec.Body.String())
+	mockFXRateUseCase.AssertExpectations(t)
+}
+
+func TestFXRateHandler_HandlerFXRateStore(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		role   string
+		body   string
+		err    error
+		status int
+	}{
+		{"success", domain.RoleAdmin, `{"base_currency":"USD","quote_currency":"IDR","rate":"15500.25"}`, nil, http.StatusOK},
+		{"invalid-rate", domain.RoleAdmin, `{"base_currency":"USD","quote_currency":"IDR","rate":"-1"}`, domain.ErrInvalidFXRate, http.StatusBadRequest},
+		{"missing-rate", domain.RoleAdmin, `{"base_currency":"USD","quote_currency":"IDR"}`, nil, http.StatusBadRequest},
+		{"teller", domain.RoleTeller, `{"base_currency":"USD","quote_currency":"IDR","rate":"15500.25"}`, nil, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+			mockFXRateUseCase.On("Store", mock.Anything, mock.AnythingOfType("*domain.FXRate")).Return(c.err).Maybe()
+
+			r := testutil.NewRouter()
+			r = NewFXRateHandler(r, mockFXRateUseCase, authAs(c.role), logger)
+
+			req, err := http.NewRequest(http.MethodPut, "/fx-rate", bytes.NewBufferString(c.body))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+		})
+	}
+}
+
+func TestFXRateHandler_HandlerFXRateImport(t *testing.T) {
+	logger := logrus.New()
+
+	csv := "base_currency,quote_currency,rate\nUSD,IDR,15500.25\n"
+
+	mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+	mockFXRateUseCase.On("Import", mock.Anything, mock.Anything).Return(1, nil).Once()
+
+	r := testutil.NewRouter()
+	r = NewFXRateHandler(r, mockFXRateUseCase, authAs(domain.RoleAdmin), logger)
+
+	req, err := http.NewRequest(http.MethodPost, "/fx-rate/import", strings.NewReader(csv))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("Content-Type", "text/csv")
+
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"data":{"imported":1}}`, rec.Body.String())
+	mockFXRateUseCase.AssertExpectations(t)
+}
+
+func TestFXRateHandler_HandlerFXRateDelete(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"success", nil, http.StatusNoContent},
+		{"not-found", sql.ErrNoRows, http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+			mockFXRateUseCase.On("Delete", mock.Anything, "usd", "idr").Return(c.err).Once()
+
+			r := testutil.NewRouter()
+			r = NewFXRateHandler(r, mockFXRateUseCase, authAs(domain.RoleAdmin), logger)
+
+			req, err := http.NewRequest(http.MethodDelete, "/fx-rate/usd/idr", nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+			mockFXRateUseCase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package repository_fxrate_mock
+
+import (
+	"context"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type FXRateMockRepository struct {
+	mock.Mock
+}
+
+func (f *FXRateMockRepository) List(ctx context.Context) ([]domain.FXRate, error) {
+	args := f.Called(ctx)
+
+	return args.Get(0).([]domain.FXRate), args.Error(1)
+}
+
+func (f *FXRateMockRepository) Get(ctx context.Context, baseCurrency, quoteCurrency string) (domain.FXRate, error) {
+	args := f.Called(ctx, baseCurrency, quoteCurrency)
+
+	return args.Get(0).(domain.FXRate), args.Error(1)
+}
+
+func (f *FXRateMockRepository) Store(ctx context.Context, r *domain.FXRate) error {
+	args := f.Called(ctx, r)
+
+	return args.Error(0)
+}
+
+func (f *FXRateMockRepository) Delete(ctx context.Context, baseCurrency, quoteCurrency string) error {
+	args := f.Called(ctx, baseCurrency, quoteCurrency)
+
+	return args.Error(0)
+}
@@ -0,0 +1,148 @@
+package repository_fxrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/oniharnantyo/golang-backend-example/database"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+)
+
+type fxRateRepository struct {
+	dbPool *sql.DB
+}
+
+func (f fxRateRepository) List(ctx context.Context) ([]domain.FXRate, error) {
+	stmt, err := database.Conn(ctx, f.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			base_currency,
+			quote_currency,
+			rate::TEXT,
+			updated_at
+		FROM fx_rate
+		ORDER BY base_currency ASC, quote_currency ASC
+	`))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var rates []domain.FXRate
+	for rows.Next() {
+		var rate domain.FXRate
+		err := rows.Scan(
+			&rate.BaseCurrency,
+			&rate.QuoteCurrency,
+			&rate.Rate,
+			&rate.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+func (f fxRateRepository) Get(ctx context.Context, baseCurrency, quoteCurrency string) (domain.FXRate, error) {
+	stmt, err := database.Conn(ctx, f.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			base_currency,
+			quote_currency,
+			rate::TEXT,
+			updated_at
+		FROM fx_rate
+		WHERE
+			base_currency = $1 AND quote_currency = $2
+	`))
+	if err != nil {
+		return domain.FXRate{}, err
+	}
+
+	var rate domain.FXRate
+	err = stmt.QueryRowContext(ctx, baseCurrency, quoteCurrency).Scan(
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.UpdatedAt,
+	)
+	if err != nil {
+		return domain.FXRate{}, err
+	}
+
+	return rate, nil
+}
+
+// Store adds the rate of the currency pair or replaces the one there is.
+func (f fxRateRepository) Store(ctx context.Context, r *domain.FXRate) error {
+	stmt, err := database.Conn(ctx, f.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		INSERT INTO fx_rate (
+			base_currency,
+			quote_currency,
+			rate,
+			updated_at
+		) VALUES (
+			$1, $2, $3::NUMERIC, $4
+		)
+		ON CONFLICT (base_currency, quote_currency) DO UPDATE SET
+			rate = EXCLUDED.rate,
+			updated_at = EXCLUDED.updated_at`))
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx,
+		r.BaseCurrency,
+		r.QuoteCurrency,
+		r.Rate,
+		r.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete returns sql.ErrNoRows when the currency pair has no rate.
+func (f fxRateRepository) Delete(ctx context.Context, baseCurrency, quoteCurrency string) error {
+	stmt, err := database.Conn(ctx, f.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		DELETE FROM fx_rate
+		WHERE
+			base_currency = $1 AND quote_currency = $2
+	`))
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, baseCurrency, quoteCurrency)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func NewFXRateRepository(db *sql.DB) domain.FXRateRepository {
+	return &fxRateRepository{
+		dbPool: db,
+	}
+}
@@ -0,0 +1,151 @@
+package repository_fxrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func initMock() (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		log.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	return db, mock
+}
+
+func TestFXRateRepository_List(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"base_currency", "quote_currency", "rate", "updated_at"}).
+		AddRow("IDR", "USD", "0.000065", now).
+		AddRow("USD", "IDR", "15500.25", now)
+
+	query := fmt.Sprintf(`
+		SELECT
+			base_currency,
+			quote_currency,
+			rate::TEXT,
+			updated_at
+		FROM fx_rate
+		ORDER BY base_currency ASC, quote_currency ASC
+	`)
+
+	mock.ExpectPrepare(query).ExpectQuery().WillReturnRows(rows)
+
+	f := NewFXRateRepository(db)
+
+	rates, err := f.List(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, "15500.25", rates[1].Rate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFXRateRepository_Get(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"base_currency", "quote_currency", "rate", "updated_at"}).
+		AddRow("USD", "IDR", "15500.25", now)
+
+	query := fmt.Sprintf(`
+		SELECT
+			base_currency,
+			quote_currency,
+			rate::TEXT,
+			updated_at
+		FROM fx_rate
+		WHERE
+			base_currency = $1 AND quote_currency = $2
+	`)
+
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs("USD", "IDR").WillReturnRows(rows)
+
+	f := NewFXRateRepository(db)
+
+	rate, err := f.Get(context.Background(), "USD", "IDR")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.FXRate{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "15500.25", UpdatedAt: now}, rate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFXRateRepository_Store(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		INSERT INTO fx_rate (
+			base_currency,
+			quote_currency,
+			rate,
+			updated_at
+		) VALUES (
+			$1, $2, $3::NUMERIC, $4
+		)
+		ON CONFLICT (base_currency, quote_currency) DO UPDATE SET
+			rate = EXCLUDED.rate,
+			updated_at = EXCLUDED.updated_at`)
+
+	now := time.Now()
+	mock.ExpectPrepare(query).ExpectExec().WithArgs("USD", "IDR", "15500.25", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	f := NewFXRateRepository(db)
+
+	err := f.Store(context.Background(), &domain.FXRate{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "15500.25", UpdatedAt: now})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFXRateRepository_Delete(t *testing.T) {
+	query := fmt.Sprintf(`
+		DELETE FROM fx_rate
+		WHERE
+			base_currency = $1 AND quote_currency = $2
+	`)
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectExec().WithArgs("USD", "IDR").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		f := NewFXRateRepository(db)
+
+		err := f.Delete(context.Background(), "USD", "IDR")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not-found", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectExec().WithArgs("USD", "JPY").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		f := NewFXRateRepository(db)
+
+		err := f.Delete(context.Background(), "USD", "JPY")
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
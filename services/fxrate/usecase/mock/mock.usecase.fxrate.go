@@ -0,0 +1,44 @@
+package fxrate_usecase_mock
+
+import (
+	"context"
+	"io"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type FXRateMockUseCase struct {
+	mock.Mock
+}
+
+func (f *FXRateMockUseCase) List(ctx context.Context) ([]domain.FXRate, error) {
+	args := f.Called(ctx)
+
+	return args.Get(0).([]domain.FXRate), args.Error(1)
+}
+
+func (f *FXRateMockUseCase) Store(ctx context.Context, r *domain.FXRate) error {
+	args := f.Called(ctx, r)
+
+	return args.Error(0)
+}
+
+func (f *FXRateMockUseCase) Import(ctx context.Context, r io.Reader) (int, error) {
+	args := f.Called(ctx, r)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (f *FXRateMockUseCase) Delete(ctx context.Context, baseCurrency, quoteCurrency string) error {
+	args := f.Called(ctx, baseCurrency, quoteCurrency)
+
+	return args.Error(0)
+}
+
+func (f *FXRateMockUseCase) Convert(ctx context.Context, amount domain.Money, currency string) (domain.Money, domain.FXRate, error) {
+	args := f.Called(ctx, amount, currency)
+
+	return args.Get(0).(domain.Money), args.Get(1).(domain.FXRate), args.Error(2)
+}
@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type fxRateUseCase struct {
+	fxRateRepository   domain.FXRateRepository
+	transactionManager domain.TransactionManager
+	fxPolicy           domain.FXPolicy
+	logger             *logrus.Logger
+}
+
+func (f fxRateUseCase) List(ctx context.Context) ([]domain.FXRate, error) {
+	rates, err := f.fxRateRepository.List(ctx)
+	if err != nil {
+		f.logger.Errorf("fxRateUseCase/List/List :%v", err)
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (f fxRateUseCase) Store(ctx context.Context, r *domain.FXRate) error {
+	normalizeRate(r)
+
+	err := r.Validate()
+	if err != nil {
+		return err
+	}
+
+	r.UpdatedAt = time.Now().UTC()
+
+	err = f.fxRateRepository.Store(ctx, r)
+	if err != nil {
+		f.logger.Errorf("fxRateUseCase/Store/Store :%v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Import reads every line before it stores anything, so a file with a
+// mistake changes no rate. The first line may name the columns.
+func (f fxRateUseCase) Import(ctx context.Context, r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		f.logger.Errorf("fxRateUseCase/Import/ReadAll :%v", err)
+		return 0, invalidImport([]string{err.Error()})
+	}
+
+	// Problems are reported by line, which is one more with a header line.
+	firstLine := 1
+	if len(records) != 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "base_currency") {
+		records = records[1:]
+		firstLine = 2
+	}
+
+	if len(records) == 0 {
+		return 0, invalidImport([]string{"no rates"})
+	}
+
+	var problems []string
+	rates := make([]domain.FXRate, len(records))
+	seen := make(map[string]int, len(records))
+	for i, record := range records {
+		line := firstLine + i
+
+		rates[i] = domain.FXRate{
+			BaseCurrency:  record[0],
+			QuoteCurrency: record[1],
+			Rate:          record[2],
+		}
+		normalizeRate(&rates[i])
+
+		err := rates[i].Validate()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %s", line, err))
+			continue
+		}
+
+		pair := rates[i].BaseCurrency + rates[i].QuoteCurrency
+		if first, ok := seen[pair]; ok {
+			problems = append(problems, fmt.Sprintf("line %d: same currencies as line %d", line, first))
+			continue
+		}
+		seen[pair] = line
+	}
+
+	if len(problems) != 0 {
+		return 0, invalidImport(problems)
+	}
+
+	now := time.Now().UTC()
+	err = f.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i := range rates {
+			rates[i].UpdatedAt = now
+
+			err := f.fxRateRepository.Store(ctx, &rates[i])
+			if err != nil {
+				f.logger.Errorf("fxRateUseCase/Import/Store :%v", err)
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(rates), nil
+}
+
+func (f fxRateUseCase) Delete(ctx context.Context, baseCurrency, quoteCurrency string) error {
+	err := f.fxRateRepository.Delete(ctx, strings.ToUpper(baseCurrency), strings.ToUpper(quoteCurrency))
+	if err != nil {
+		f.logger.Errorf("fxRateUseCase/Delete/Delete :%v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (f fxRateUseCase) Convert(ctx context.Context, amount domain.Money, currency string) (domain.Money, domain.FXRate, error) {
+	rate, err := f.fxRateRepository.Get(ctx, amount.Currency, currency)
+	if err != nil {
+		f.logger.Errorf("fxRateUseCase/Convert/Get :%v", err)
+		if errors.Cause(err) == sql.ErrNoRows {
+			return domain.Money{}, domain.FXRate{}, domain.ErrFXRateNotFound
+		}
+		return domain.Money{}, domain.FXRate{}, err
+	}
+
+	if f.fxPolicy.MaxRateAge > 0 && time.Since(rate.UpdatedAt) > f.fxPolicy.MaxRateAge {
+		f.logger.Errorf("fxRateUseCase/Convert/validateAge :%s to %s rate from %s", rate.BaseCurrency, rate.QuoteCurrency, rate.UpdatedAt)
+		return domain.Money{}, domain.FXRate{}, domain.ErrFXRateStale
+	}
+
+	ratio, err := rate.Ratio()
+	if err != nil {
+		f.logger.Errorf("fxRateUseCase/Convert/Ratio :%v", err)
+		return domain.Money{}, domain.FXRate{}, err
+	}
+
+	converted, err := amount.Convert(currency, ratio)
+	if err != nil {
+		f.logger.Errorf("fxRateUseCase/Convert/Convert :%v", err)
+		return domain.Money{}, domain.FXRate{}, err
+	}
+
+	return converted, rate, nil
+}
+
+// normalizeRate upper cases the currencies and drops the blanks around the
+// fields.
+func normalizeRate(r *domain.FXRate) {
+	r.BaseCurrency = strings.ToUpper(strings.TrimSpace(r.BaseCurrency))
+	r.QuoteCurrency = strings.ToUpper(strings.TrimSpace(r.QuoteCurrency))
+	r.Rate = strings.TrimSpace(r.Rate)
+}
+
+// invalidImport is ErrInvalidFXRate listing the problems of every rate of an
+// import.
+func invalidImport(problems []string) error {
+	return &domain.Error{
+		Kind:    domain.ErrInvalidFXRate.Kind,
+		Code:    domain.ErrInvalidFXRate.Code,
+		Message: "Invalid exchange rates: " + strings.Join(problems, ", "),
+		Details: problems,
+	}
+}
+
+func NewFXRateUseCase(r domain.FXRateRepository, tm domain.TransactionManager, p domain.FXPolicy, log *logrus.Logger) domain.FXRateUseCase {
+	return &fxRateUseCase{
+		fxRateRepository:   r,
+		transactionManager: tm,
+		fxPolicy:           p,
+		logger:             log,
+	}
+}
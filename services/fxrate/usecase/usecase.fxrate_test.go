@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	database_mock "github.com/oniharnantyo/golang-backend-example/database/mock"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	repository_fxrate_mock "github.com/oniharnantyo/golang-backend-example/services/fxrate/repository/mock"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var fxPolicy = domain.FXPolicy{MaxRateAge: time.Hour}
+
+func TestFXRateUseCase_Store(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockFXRateRepo := new(repository_fxrate_mock.FXRateMockRepository)
+		mockFXRateRepo.On("Store", mock.Anything, mock.MatchedBy(func(r *domain.FXRate) bool {
+			return r.BaseCurrency == "USD" && r.QuoteCurrency == "IDR" && r.Rate == "15500.25" && !r.UpdatedAt.IsZero()
+		})).Return(nil).Once()
+
+		f := NewFXRateUseCase(mockFXRateRepo, new(database_mock.TransactionMockManager), fxPolicy, logger)
+
+		err := f.Store(context.Background(), &domain.FXRate{BaseCurrency: "usd", QuoteCurrency: " idr", Rate: "15500.25"})
+		assert.NoError(t, err)
+
+		mockFXRateRepo.AssertExpectations(t)
+	})
+
+	cases := []struct {
+		name string
+		rate domain.FXRate
+		err  error
+	}{
+		{"Unknown-currency", domain.FXRate{BaseCurrency: "XYZ", QuoteCurrency: "IDR", Rate: "1"}, domain.ErrInvalidCurrency},
+		{"Same-currency", domain.FXRate{BaseCurrency: "IDR", QuoteCurrency: "IDR", Rate: "1"}, domain.ErrInvalidFXRate},
+		{"Zero", domain.FXRate{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "0.000"}, domain.ErrInvalidFXRate},
+		{"Negative", domain.FXRate{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "-15500"}, domain.ErrInvalidFXRate},
+		{"Fraction", domain.FXRate{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "31/2"}, domain.ErrInvalidFXRate},
+		{"Too-many-decimals", domain.FXRate{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "1.0000000000001"}, domain.ErrInvalidFXRate},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockFXRateRepo := new(repository_fxrate_mock.FXRateMockRepository)
+
+			f := NewFXRateUseCase(mockFXRateRepo, new(database_mock.TransactionMockManager), fxPolicy, logger)
+
+			err := f.Store(context.Background(), &c.rate)
+			assert.Equal(t, c.err, err)
+
+			mockFXRateRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestFXRateUseCase_Import(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockFXRateRepo := new(repository_fxrate_mock.FXRateMockRepository)
+		mockTransaction := new(database_mock.TransactionMockManager)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockFXRateRepo.On("Store", mock.Anything, mock.MatchedBy(func(r *domain.FXRate) bool {
+			return r.BaseCurrency == "USD" && r.QuoteCurrency == "IDR" && r.Rate == "15500"
+		})).Return(nil).Once()
+		mockFXRateRepo.On("Store", mock.Anything, mock.MatchedBy(func(r *domain.FXRate) bool {
+			return r.BaseCurrency == "IDR" && r.QuoteCurrency == "JPY" && r.Rate == "0.0095"
+		})).Return(nil).Once()
+
+		f := NewFXRateUseCase(mockFXRateRepo, mockTransaction, fxPolicy, logger)
+
+		imported, err := f.Import(context.Background(), strings.NewReader("base_currency,quote_currency,rate\nUSD,IDR,15500\nidr, jpy, 0.0095\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, imported)
+
+		mockTransaction.AssertExpectations(t)
+		mockFXRateRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid-lines", func(t *testing.T) {
+		mockFXRateRepo := new(repository_fxrate_mock.FXRateMockRepository)
+		mockTransaction := new(database_mock.TransactionMockManager)
+
+		f := NewFXRateUseCase(mockFXRateRepo, mockTransaction, fxPolicy, logger)
+
+		_, err := f.Import(context.Background(), strings.NewReader("base_currency,quote_currency,rate\nUSD,IDR,15500\nUSD,XYZ,1\nusd,idr,15600\n"))
+		domainErr, ok := err.(*domain.Error)
+		assert.True(t, ok)
+		assert.Equal(t, "invalid_fx_rate", domainErr.Code)
+		assert.Equal(t, []string{"line 3: Unknown currency", "line 4: same currencies as line 2"}, domainErr.Details)
+
+		mockTransaction.AssertNotCalled(t, "WithinTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Wrong-number-of-fields", func(t *testing.T) {
+		f := NewFXRateUseCase(new(repository_fxrate_mock.FXRateMockRepository), new(database_mock.TransactionMockManager), fxPolicy, logger)
+
+		_, err := f.Import(context.Background(), strings.NewReader("USD,IDR\n"))
+		domainErr, ok := err.(*domain.Error)
+		assert.True(t, ok)
+		assert.Equal(t, "invalid_fx_rate", domainErr.Code)
+	})
+}
+
+func TestFXRateUseCase_Convert(t *testing.T) {
+	logger := logrus.New()
+	now := time.Now().UTC()
+
+	cases := []struct {
+		name      string
+		rate      domain.FXRate
+		amount    domain.Money
+		currency  string
+		converted domain.Money
+		err       error
+	}{
+		// 10.00 USD is 155,002.50 rupiah, rounded to whole rupiah.
+		{"Rupiah-round-half-up", domain.FXRate{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "15500.25", UpdatedAt: now}, domain.NewMoney(1000, "USD"), "IDR", domain.NewMoney(15500300, "IDR"), nil},
+		// 1,000 rupiah are 0.065 USD, the half goes to the even cent.
+		{"Dollar-round-half-even", domain.FXRate{BaseCurrency: "IDR", QuoteCurrency: "USD", Rate: "0.000065", UpdatedAt: now}, domain.NewMoney(100000, "IDR"), "USD", domain.NewMoney(6, "USD"), nil},
+		// 0.015 USD rounds up to the even cent.
+		{"Dollar-round-half-even-up", domain.FXRate{BaseCurrency: "IDR", QuoteCurrency: "USD", Rate: "0.000015", UpdatedAt: now}, domain.NewMoney(100000, "IDR"), "USD", domain.NewMoney(2, "USD"), nil},
+		// 1.00 USD is 1,350.9 won, never rounded up.
+		{"Won-round-down", domain.FXRate{BaseCurrency: "USD", QuoteCurrency: "KRW", Rate: "1350.9", UpdatedAt: now}, domain.NewMoney(100, "USD"), "KRW", domain.NewMoney(1350, "KRW"), nil},
+		// 100 yen are 0.615 dinar with three decimals.
+		{"Dinar-three-decimals", domain.FXRate{BaseCurrency: "JPY", QuoteCurrency: "KWD", Rate: "0.00615", UpdatedAt: now}, domain.NewMoney(100, "JPY"), "KWD", domain.NewMoney(615, "KWD"), nil},
+		{"Overflow", domain.FXRate{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "999999999999", UpdatedAt: now}, domain.NewMoney(9000000000000000000, "USD"), "IDR", domain.Money{}, domain.ErrAmountOverflow},
+		{"Stale", domain.FXRate{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "15500", UpdatedAt: now.Add(-2 * time.Hour)}, domain.NewMoney(1000, "USD"), "IDR", domain.Money{}, domain.ErrFXRateStale},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockFXRateRepo := new(repository_fxrate_mock.FXRateMockRepository)
+			mockFXRateRepo.On("Get", mock.Anything, c.amount.Currency, c.currency).Return(c.rate, nil).Once()
+
+			f := NewFXRateUseCase(mockFXRateRepo, new(database_mock.TransactionMockManager), fxPolicy, logger)
+
+			converted, _, err := f.Convert(context.Background(), c.amount, c.currency)
+			assert.Equal(t, c.err, err)
+			assert.Equal(t, c.converted, converted)
+
+			mockFXRateRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Not-found", func(t *testing.T) {
+		mockFXRateRepo := new(repository_fxrate_mock.FXRateMockRepository)
+		mockFXRateRepo.On("Get", mock.Anything, "USD", "IDR").Return(domain.FXRate{}, sql.ErrNoRows).Once()
+
+		f := NewFXRateUseCase(mockFXRateRepo, new(database_mock.TransactionMockManager), fxPolicy, logger)
+
+		_, _, err := f.Convert(context.Background(), domain.NewMoney(1000, "USD"), "IDR")
+		assert.Equal(t, domain.ErrFXRateNotFound, err)
+	})
+
+	t.Run("Any-age", func(t *testing.T) {
+		mockFXRateRepo := new(repository_fxrate_mock.FXRateMockRepository)
+		mockFXRateRepo.On("Get", mock.Anything, "USD", "IDR").Return(domain.FXRate{
+			BaseCurrency:  "USD",
+			QuoteCurrency: "IDR",
+			Rate:          "15500",
+			UpdatedAt:     now.AddDate(-1, 0, 0),
+		}, nil).Once()
+
+		f := NewFXRateUseCase(mockFXRateRepo, new(database_mock.TransactionMockManager), domain.FXPolicy{}, logger)
+
+		converted, rate, err := f.Convert(context.Background(), domain.NewMoney(1000, "USD"), "IDR")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.NewMoney(15500000, "IDR"), converted)
+		assert.Equal(t, "15500", rate.Rate)
+	})
+}
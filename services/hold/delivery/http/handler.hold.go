@@ -0,0 +1,105 @@
+package delivery_http_hold
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware"
+	"github.com/oniharnantyo/golang-backend-example/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+type HoldHandler struct {
+	holdUseCase domain.HoldUseCase
+	logger      *logrus.Logger
+}
+
+func NewHoldHandler(r *gin.Engine, h domain.HoldUseCase, au domain.AuthUseCase, l *logrus.Logger) *gin.Engine {
+	handler := &HoldHandler{holdUseCase: h, logger: l}
+
+	auth := middleware.JWT(au)
+	staff := middleware.RequireRole(domain.RoleTeller, domain.RoleAdmin)
+	ownerOrStaff := middleware.RequireOwnerOrRole("account_number", domain.RoleTeller, domain.RoleAdmin)
+
+	r.GET("/account/:account_number/holds", auth, ownerOrStaff, handler.HandlerGetHoldList)
+	r.POST("/account/:account_number/holds", auth, staff, handler.HandlerHoldPlace)
+	r.DELETE("/account/:account_number/holds/:hold_id", auth, staff, handler.HandlerHoldRelease)
+
+	return r
+}
+
+func (h *HoldHandler) HandlerGetHoldList(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		h.logger.Errorf("%s : %v", "HoldHandler/HandlerGetHoldList/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	holds, err := h.holdUseCase.ListActive(ctx, accountNumber)
+	if err != nil {
+		h.logger.Errorf("%s : %v", "HoldHandler/HandlerGetHoldList/ListActive", err)
+		ctx.Error(err)
+		return
+	}
+
+	if holds == nil {
+		holds = []domain.Hold{}
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: holds})
+}
+
+func (h *HoldHandler) HandlerHoldPlace(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		h.logger.Errorf("%s : %v", "HoldHandler/HandlerHoldPlace/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	var param domain.HoldParam
+	err = ctx.ShouldBindJSON(&param)
+	if err != nil {
+		h.logger.Errorf("%s : %v", "HoldHandler/HandlerHoldPlace/ParseBodyData", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	hold, err := h.holdUseCase.Place(ctx, accountNumber, param)
+	if err != nil {
+		h.logger.Errorf("%s : %v", "HoldHandler/HandlerHoldPlace/Place", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, util.Response{Data: hold})
+}
+
+func (h *HoldHandler) HandlerHoldRelease(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		h.logger.Errorf("%s : %v", "HoldHandler/HandlerHoldRelease/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("hold_id"), 10, 64)
+	if err != nil {
+		h.logger.Errorf("%s : %v", "HoldHandler/HandlerHoldRelease/parseHoldID", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	err = h.holdUseCase.Release(ctx, accountNumber, id)
+	if err != nil {
+		h.logger.Errorf("%s : %v", "HoldHandler/HandlerHoldRelease/Release", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,146 @@
+package delivery_http_hold
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware/testutil"
+	auth_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/auth/usecase/mock"
+	hold_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/hold/usecase/mock"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func authAs(accountNumber string, role string) *auth_usecase_mock.AuthMockUseCase {
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Subject: accountNumber},
+		Role:           role,
+	}, nil)
+
+	return mockAuthUseCase
+}
+
+func TestHoldHandler_HandlerGetHoldList(t *testing.T) {
+	logger := logrus.New()
+
+	createdAt := time.Date(2021, 4, 20, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name          string
+		accountNumber string
+		role          string
+		status        int
+	}{
+		{"Owner", "555001", domain.RoleCustomer, http.StatusOK},
+		{"Teller", "555009", domain.RoleTeller, http.StatusOK},
+		{"Other-customer", "555002", domain.RoleCustomer, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockHoldUseCase := new(hold_usecase_mock.HoldMockUseCase)
+			mockHoldUseCase.On("ListActive", mock.Anything, 555001).Return([]domain.Hold{
+				{ID: 7, AccountNumber: 555001, Amount: domain.NewMoney(5000, "IDR"), Reason: "card authorisation", CreatedAt: createdAt},
+			}, nil).Maybe()
+
+			r := testutil.NewRouter()
+			r = NewHoldHandler(r, mockHoldUseCase, authAs(c.accountNumber, c.role), logger)
+
+			req, err := http.NewRequest(http.MethodGet, "/account/555001/holds", nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+			if c.status == http.StatusOK {
+				assert.JSONEq(t, `{"data":[{"id":7,"account_number":555001,"amount":{"amount":"50.00","currency":"IDR"},"reason":"card authorisation","created_at":"2021-04-20T10:00:00Z"}]}`, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHoldHandler_HandlerHoldPlace(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		role   string
+		body   string
+		err    error
+		status int
+	}{
+		{"Success", domain.RoleTeller, `{"amount":{"amount":"50.00","currency":"IDR"},"reason":"card authorisation"}`, nil, http.StatusCreated},
+		{"Missing-reason", domain.RoleTeller, `{"amount":{"amount":"50.00","currency":"IDR"}}`, nil, http.StatusBadRequest},
+		{"Expired", domain.RoleTeller, `{"amount":{"amount":"50.00","currency":"IDR"},"reason":"r","expires_at":"2021-04-20T10:00:00Z"}`, domain.ErrInvalidHoldExpiry, http.StatusBadRequest},
+		{"Customer", domain.RoleCustomer, `{"amount":{"amount":"50.00","currency":"IDR"},"reason":"card authorisation"}`, nil, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockHoldUseCase := new(hold_usecase_mock.HoldMockUseCase)
+			mockHoldUseCase.On("Place", mock.Anything, 555001, mock.AnythingOfType("domain.HoldParam")).Return(domain.Hold{ID: 7}, c.err).Maybe()
+
+			r := testutil.NewRouter()
+			r = NewHoldHandler(r, mockHoldUseCase, authAs("555001", c.role), logger)
+
+			req, err := http.NewRequest(http.MethodPost, "/account/555001/holds", bytes.NewBufferString(c.body))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+		})
+	}
+}
+
+func TestHoldHandler_HandlerHoldRelease(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		path   string
+		err    error
+		status int
+	}{
+		{"Success", "/account/555001/holds/7", nil, http.StatusNoContent},
+		{"Not-found", "/account/555001/holds/7", domain.ErrHoldNotFound, http.StatusNotFound},
+		{"Invalid-id", "/account/555001/holds/x", nil, http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockHoldUseCase := new(hold_usecase_mock.HoldMockUseCase)
+			mockHoldUseCase.On("Release", mock.Anything, 555001, int64(7)).Return(c.err).Maybe()
+
+			r := testutil.NewRouter()
+			r = NewHoldHandler(r, mockHoldUseCase, authAs("555009", domain.RoleAdmin), logger)
+
+			req, err := http.NewRequest(http.MethodDelete, c.path, nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+		})
+	}
+}
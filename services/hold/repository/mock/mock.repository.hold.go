@@ -0,0 +1,37 @@
+package repository_hold_mock
+
+import (
+	"context"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type HoldMockRepository struct {
+	mock.Mock
+}
+
+func (h *HoldMockRepository) Store(ctx context.Context, a *domain.Hold) error {
+	args := h.Called(ctx, a)
+
+	return args.Error(0)
+}
+
+func (h *HoldMockRepository) ListActive(ctx context.Context, accountNumber int) ([]domain.Hold, error) {
+	args := h.Called(ctx, accountNumber)
+
+	return args.Get(0).([]domain.Hold), args.Error(1)
+}
+
+func (h *HoldMockRepository) Release(ctx context.Context, accountNumber int, id int64) error {
+	args := h.Called(ctx, accountNumber, id)
+
+	return args.Error(0)
+}
+
+func (h *HoldMockRepository) TotalActive(ctx context.Context, accountNumber int) (int64, error) {
+	args := h.Called(ctx, accountNumber)
+
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,154 @@
+package repository_hold
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/database"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+)
+
+type holdRepository struct {
+	dbPool *sql.DB
+}
+
+func (h holdRepository) Store(ctx context.Context, a *domain.Hold) error {
+	stmt, err := database.Conn(ctx, h.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		INSERT INTO account_hold (
+			account_number,
+			amount,
+			currency,
+			reason,
+			expires_at,
+			created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+		RETURNING id`))
+	if err != nil {
+		return err
+	}
+
+	err = stmt.QueryRowContext(ctx,
+		a.AccountNumber,
+		a.Amount.Amount,
+		a.Amount.Currency,
+		a.Reason,
+		a.ExpiresAt,
+		a.CreatedAt,
+	).Scan(&a.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListActive returns the holds that are neither released nor expired, the
+// oldest first. expires_at is stored in UTC without a time zone, so it is
+// compared with the current UTC time rather than NOW() of the session.
+func (h holdRepository) ListActive(ctx context.Context, accountNumber int) ([]domain.Hold, error) {
+	stmt, err := database.Conn(ctx, h.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			id,
+			account_number,
+			amount,
+			currency,
+			reason,
+			expires_at,
+			created_at
+		FROM account_hold
+		WHERE
+			account_number = $1 AND released_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+		ORDER BY id ASC
+	`))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, accountNumber, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var holds []domain.Hold
+	for rows.Next() {
+		var hold domain.Hold
+		err := rows.Scan(
+			&hold.ID,
+			&hold.AccountNumber,
+			&hold.Amount.Amount,
+			&hold.Amount.Currency,
+			&hold.Reason,
+			&hold.ExpiresAt,
+			&hold.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		holds = append(holds, hold)
+	}
+
+	return holds, nil
+}
+
+func (h holdRepository) Release(ctx context.Context, accountNumber int, id int64) error {
+	stmt, err := database.Conn(ctx, h.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE account_hold
+		SET
+			released_at = $3
+		WHERE
+			id = $1 AND account_number = $2 AND released_at IS NULL AND (expires_at IS NULL OR expires_at > $3)
+	`))
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, id, accountNumber, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (h holdRepository) TotalActive(ctx context.Context, accountNumber int) (int64, error) {
+	stmt, err := database.Conn(ctx, h.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			COALESCE(SUM(amount), 0)
+		FROM account_hold
+		WHERE
+			account_number = $1 AND released_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+	`))
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	err = stmt.QueryRowContext(ctx, accountNumber, time.Now().UTC()).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func NewHoldRepository(db *sql.DB) domain.HoldRepository {
+	return &holdRepository{
+		dbPool: db,
+	}
+}
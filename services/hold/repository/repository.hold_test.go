@@ -0,0 +1,170 @@
+package repository_hold
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func initMock() (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		log.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	return db, mock
+}
+
+// utcNow matches the current time in UTC, which expires_at is compared with.
+type utcNow struct{}
+
+func (utcNow) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	return ok && t.Location() == time.UTC && time.Since(t) < time.Minute
+}
+
+func TestHoldRepository_Store(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		INSERT INTO account_hold (
+			account_number,
+			amount,
+			currency,
+			reason,
+			expires_at,
+			created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+		RETURNING id`)
+
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs(555001, int64(5000), "IDR", "card authorisation", &expiresAt, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	h := NewHoldRepository(db)
+
+	hold := domain.Hold{
+		AccountNumber: 555001,
+		Amount:        domain.NewMoney(5000, "IDR"),
+		Reason:        "card authorisation",
+		ExpiresAt:     &expiresAt,
+		CreatedAt:     now,
+	}
+	err := h.Store(context.Background(), &hold)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), hold.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHoldRepository_ListActive(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "account_number", "amount", "currency", "reason", "expires_at", "created_at"}).
+		AddRow(7, 555001, 5000, "IDR", "card authorisation", now.Add(time.Hour), now).
+		AddRow(8, 555001, 1000, "IDR", "court order", nil, now)
+
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			account_number,
+			amount,
+			currency,
+			reason,
+			expires_at,
+			created_at
+		FROM account_hold
+		WHERE
+			account_number = $1 AND released_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+		ORDER BY id ASC
+	`)
+
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs(555001, utcNow{}).WillReturnRows(rows)
+
+	h := NewHoldRepository(db)
+
+	holds, err := h.ListActive(context.Background(), 555001)
+	assert.NoError(t, err)
+	assert.Len(t, holds, 2)
+	assert.NotNil(t, holds[0].ExpiresAt)
+	assert.Nil(t, holds[1].ExpiresAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHoldRepository_Release(t *testing.T) {
+	query := fmt.Sprintf(`
+		UPDATE account_hold
+		SET
+			released_at = $3
+		WHERE
+			id = $1 AND account_number = $2 AND released_at IS NULL AND (expires_at IS NULL OR expires_at > $3)
+	`)
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(int64(7), 555001, utcNow{}).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		h := NewHoldRepository(db)
+
+		err := h.Release(context.Background(), 555001, 7)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not-found", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(int64(7), 555002, utcNow{}).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		h := NewHoldRepository(db)
+
+		err := h.Release(context.Background(), 555002, 7)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHoldRepository_TotalActive(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		SELECT
+			COALESCE(SUM(amount), 0)
+		FROM account_hold
+		WHERE
+			account_number = $1 AND released_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+	`)
+
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs(555001, utcNow{}).WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(6000))
+
+	h := NewHoldRepository(db)
+
+	total, err := h.TotalActive(context.Background(), 555001)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6000), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,31 @@
+package hold_usecase_mock
+
+import (
+	"context"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type HoldMockUseCase struct {
+	mock.Mock
+}
+
+func (h *HoldMockUseCase) Place(ctx context.Context, accountNumber int, param domain.HoldParam) (domain.Hold, error) {
+	args := h.Called(ctx, accountNumber, param)
+
+	return args.Get(0).(domain.Hold), args.Error(1)
+}
+
+func (h *HoldMockUseCase) ListActive(ctx context.Context, accountNumber int) ([]domain.Hold, error) {
+	args := h.Called(ctx, accountNumber)
+
+	return args.Get(0).([]domain.Hold), args.Error(1)
+}
+
+func (h *HoldMockUseCase) Release(ctx context.Context, accountNumber int, id int64) error {
+	args := h.Called(ctx, accountNumber, id)
+
+	return args.Error(0)
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type holdUseCase struct {
+	holdRepository    domain.HoldRepository
+	accountRepository domain.AccountRepository
+	logger            *logrus.Logger
+}
+
+// Place holds an amount in the currency of the account. A hold without
+// ExpiresAt stays until it is released.
+func (h holdUseCase) Place(ctx context.Context, accountNumber int, param domain.HoldParam) (domain.Hold, error) {
+	err := param.Amount.Validate()
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	if !param.Amount.IsPositive() {
+		return domain.Hold{}, domain.ErrInvalidAmount
+	}
+
+	now := time.Now().UTC()
+	if param.ExpiresAt != nil {
+		if !param.ExpiresAt.After(now) {
+			return domain.Hold{}, domain.ErrInvalidHoldExpiry
+		}
+
+		expiresAt := param.ExpiresAt.UTC()
+		param.ExpiresAt = &expiresAt
+	}
+
+	account, err := h.accountRepository.GetByAccountNumber(ctx, accountNumber)
+	if err != nil {
+		h.logger.Errorf("holdUseCase/Place/GetByAccountNumber :%v", err)
+		if errors.Cause(err) == sql.ErrNoRows {
+			return domain.Hold{}, domain.ErrAccountNotFound
+		}
+		return domain.Hold{}, err
+	}
+
+	if account.Balance.Currency != param.Amount.Currency {
+		return domain.Hold{}, domain.ErrCurrencyMismatch
+	}
+
+	hold := domain.Hold{
+		AccountNumber: accountNumber,
+		Amount:        param.Amount,
+		Reason:        param.Reason,
+		ExpiresAt:     param.ExpiresAt,
+		CreatedAt:     now,
+	}
+
+	err = h.holdRepository.Store(ctx, &hold)
+	if err != nil {
+		h.logger.Errorf("holdUseCase/Place/Store :%v", err)
+		return domain.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+func (h holdUseCase) ListActive(ctx context.Context, accountNumber int) ([]domain.Hold, error) {
+	holds, err := h.holdRepository.ListActive(ctx, accountNumber)
+	if err != nil {
+		h.logger.Errorf("holdUseCase/ListActive/ListActive :%v", err)
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+func (h holdUseCase) Release(ctx context.Context, accountNumber int, id int64) error {
+	err := h.holdRepository.Release(ctx, accountNumber, id)
+	if err != nil {
+		h.logger.Errorf("holdUseCase/Release/Release :%v", err)
+		if errors.Cause(err) == sql.ErrNoRows {
+			return domain.ErrHoldNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func NewHoldUseCase(hr domain.HoldRepository, a domain.AccountRepository, log *logrus.Logger) domain.HoldUseCase {
+	return &holdUseCase{
+		holdRepository:    hr,
+		accountRepository: a,
+		logger:            log,
+	}
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	repository_account_mock "github.com/oniharnantyo/golang-backend-example/services/account/repository/mock"
+	repository_hold_mock "github.com/oniharnantyo/golang-backend-example/services/hold/repository/mock"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHoldUseCase_Place(t *testing.T) {
+	logger := logrus.New()
+
+	account := domain.Account{
+		AccountNumber: 555001,
+		Balance:       domain.NewMoney(10000, "IDR"),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockHoldRepo := new(repository_hold_mock.HoldMockRepository)
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+
+		expiresAt := time.Now().Add(time.Hour)
+
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(account, nil).Once()
+		mockHoldRepo.On("Store", mock.Anything, mock.MatchedBy(func(h *domain.Hold) bool {
+			return h.AccountNumber == 555001 && h.Amount == domain.NewMoney(5000, "IDR") &&
+				h.ExpiresAt != nil && h.ExpiresAt.Equal(expiresAt) && !h.CreatedAt.IsZero()
+		})).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Hold).ID = 7
+		}).Once()
+
+		h := NewHoldUseCase(mockHoldRepo, mockAccountRepo, logger)
+
+		hold, err := h.Place(context.Background(), 555001, domain.HoldParam{
+			Amount:    domain.NewMoney(5000, "IDR"),
+			Reason:    "card authorisation",
+			ExpiresAt: &expiresAt,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), hold.ID)
+
+		mockHoldRepo.AssertExpectations(t)
+	})
+
+	past := time.Now().Add(-time.Minute)
+
+	cases := []struct {
+		name   string
+		param  domain.HoldParam
+		lookup error
+		err    error
+	}{
+		{"Non-positive-amount", domain.HoldParam{Amount: domain.NewMoney(0, "IDR"), Reason: "r"}, nil, domain.ErrInvalidAmount},
+		{"Expired", domain.HoldParam{Amount: domain.NewMoney(5000, "IDR"), Reason: "r", ExpiresAt: &past}, nil, domain.ErrInvalidHoldExpiry},
+		{"Currency-mismatch", domain.HoldParam{Amount: domain.NewMoney(5000, "USD"), Reason: "r"}, nil, domain.ErrCurrencyMismatch},
+		{"Account-not-exists", domain.HoldParam{Amount: domain.NewMoney(5000, "IDR"), Reason: "r"}, sql.ErrNoRows, domain.ErrAccountNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockHoldRepo := new(repository_hold_mock.HoldMockRepository)
+			mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+
+			mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(account, c.lookup).Maybe()
+
+			h := NewHoldUseCase(mockHoldRepo, mockAccountRepo, logger)
+
+			_, err := h.Place(context.Background(), 555001, c.param)
+			assert.Equal(t, c.err, err)
+
+			mockHoldRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestHoldUseCase_Release(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"Success", nil, nil},
+		{"Not-found", sql.ErrNoRows, domain.ErrHoldNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockHoldRepo := new(repository_hold_mock.HoldMockRepository)
+			mockHoldRepo.On("Release", mock.Anything, 555001, int64(7)).Return(c.err).Once()
+
+			h := NewHoldUseCase(mockHoldRepo, new(repository_account_mock.AccountMockRepository), logger)
+
+			err := h.Release(context.Background(), 555001, 7)
+			assert.Equal(t, c.want, err)
+
+			mockHoldRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package idempotency_repository_mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type IdempotencyMockRepository struct {
+	mock.Mock
+}
+
+func (i *IdempotencyMockRepository) Get(ctx context.Context, key string) (domain.IdempotencyRecord, bool, error) {
+	args := i.Called(ctx, key)
+
+	return args.Get(0).(domain.IdempotencyRecord), args.Bool(1), args.Error(2)
+}
+
+func (i *IdempotencyMockRepository) Lock(ctx context.Context, key string, record domain.IdempotencyRecord, expire time.Duration) (bool, error) {
+	args := i.Called(ctx, key, record, expire)
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (i *IdempotencyMockRepository) Save(ctx context.Context, key string, record domain.IdempotencyRecord, expire time.Duration) error {
+	args := i.Called(ctx, key, record, expire)
+
+	return args.Error(0)
+}
+
+func (i *IdempotencyMockRepository) Delete(ctx context.Context, key string) error {
+	args := i.Called(ctx, key)
+
+	return args.Error(0)
+}
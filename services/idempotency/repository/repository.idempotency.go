@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const keyPrefix = "idempotency:"
+
+type idempotencyRepository struct {
+	redisClient *redis.Client
+}
+
+func (i idempotencyRepository) Get(ctx context.Context, key string) (domain.IdempotencyRecord, bool, error) {
+	res, err := i.redisClient.Get(ctx, keyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return domain.IdempotencyRecord{}, false, nil
+	}
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, err
+	}
+
+	var record domain.IdempotencyRecord
+	err = json.Unmarshal(res, &record)
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, err
+	}
+
+	return record, true, nil
+}
+
+func (i idempotencyRepository) Lock(ctx context.Context, key string, record domain.IdempotencyRecord, expire time.Duration) (bool, error) {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := i.redisClient.SetNX(ctx, keyPrefix+key, value, expire).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
+
+func (i idempotencyRepository) Save(ctx context.Context, key string, record domain.IdempotencyRecord, expire time.Duration) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	err = i.redisClient.Set(ctx, keyPrefix+key, value, expire).Err()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i idempotencyRepository) Delete(ctx context.Context, key string) error {
+	err := i.redisClient.Del(ctx, keyPrefix+key).Err()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewIdempotencyRepository(client *redis.Client) domain.IdempotencyRepository {
+	return &idempotencyRepository{redisClient: client}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-redis/redis/v8"
+	redismock "github.com/go-redis/redismock/v8"
+)
+
+var (
+	key    = "key"
+	record = domain.IdempotencyRecord{
+		Fingerprint: "fingerprint",
+		Status:      201,
+		ContentType: "application/json",
+		Body:        []byte(`{"id":"1"}`),
+	}
+)
+
+func TestIdempotencyRepository_Get(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("Found", func(t *testing.T) {
+		value, err := json.Marshal(record)
+		assert.NoError(t, err)
+
+		mockRedis.ExpectGet(keyPrefix + key).SetVal(string(value))
+
+		i := NewIdempotencyRepository(client)
+		res, found, err := i.Get(ctx, key)
+
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, record, res)
+	})
+
+	t.Run("Not-found", func(t *testing.T) {
+		mockRedis.ExpectGet(keyPrefix + key).RedisNil()
+
+		i := NewIdempotencyRepository(client)
+		_, found, err := i.Get(ctx, key)
+
+		assert.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRedis.ExpectGet(keyPrefix + key).SetErr(redis.ErrClosed)
+
+		i := NewIdempotencyRepository(client)
+		_, _, err := i.Get(ctx, key)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIdempotencyRepository_Lock(t *testing.T) {
+	ctx := context.Background()
+
+	exp := time.Hour
+
+	client, mockRedis := redismock.NewClientMock()
+
+	lockRecord := domain.IdempotencyRecord{Fingerprint: "fingerprint"}
+	value, err := json.Marshal(lockRecord)
+	assert.NoError(t, err)
+
+	t.Run("Acquired", func(t *testing.T) {
+		mockRedis.ExpectSetNX(keyPrefix+key, value, exp).SetVal(true)
+
+		i := NewIdempotencyRepository(client)
+		ok, err := i.Lock(ctx, key, lockRecord, exp)
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Taken", func(t *testing.T) {
+		mockRedis.ExpectSetNX(keyPrefix+key, value, exp).SetVal(false)
+
+		i := NewIdempotencyRepository(client)
+		ok, err := i.Lock(ctx, key, lockRecord, exp)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestIdempotencyRepository_Save(t *testing.T) {
+	ctx := context.Background()
+
+	exp := time.Hour
+
+	client, mockRedis := redismock.NewClientMock()
+
+	value, err := json.Marshal(record)
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRedis.ExpectSet(keyPrefix+key, value, exp).SetVal("OK")
+
+		i := NewIdempotencyRepository(client)
+		err := i.Save(ctx, key, record, exp)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRedis.ExpectSet(keyPrefix+key, value, exp).SetErr(redis.ErrClosed)
+
+		i := NewIdempotencyRepository(client)
+		err := i.Save(ctx, key, record, exp)
+		assert.Error(t, err)
+	})
+}
+
+func TestIdempotencyRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	mockRedis.ExpectDel(keyPrefix + key).SetVal(1)
+
+	i := NewIdempotencyRepository(client)
+	err := i.Delete(ctx, key)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,38 @@
+package repository_ledger_mock
+
+import (
+	"context"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type LedgerMockRepository struct {
+	mock.Mock
+}
+
+func (l *LedgerMockRepository) Store(ctx context.Context, entries []domain.LedgerEntry) error {
+	args := l.Called(ctx, entries)
+
+	return args.Error(0)
+}
+
+func (l *LedgerMockRepository) ListByAccountNumber(ctx context.Context, accountNumber int, param domain.LedgerEntryListParam) ([]domain.LedgerEntry, error) {
+	args := l.Called(ctx, accountNumber, param)
+	result := args.Get(0)
+
+	return result.([]domain.LedgerEntry), args.Error(1)
+}
+
+func (l *LedgerMockRepository) CountByAccountNumber(ctx context.Context, accountNumber int) (int, error) {
+	args := l.Called(ctx, accountNumber)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (l *LedgerMockRepository) GetBalance(ctx context.Context, accountNumber int) (int64, error) {
+	args := l.Called(ctx, accountNumber)
+
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,148 @@
+package repository_ledger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/oniharnantyo/golang-backend-example/database"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+)
+
+type ledgerRepository struct {
+	dbPool *sql.DB
+}
+
+func (l ledgerRepository) Store(ctx context.Context, entries []domain.LedgerEntry) error {
+	stmt, err := database.Conn(ctx, l.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		INSERT INTO ledger_entry (
+			transaction_id,
+			transaction_type,
+			account_number,
+			entry_type,
+			amount,
+			currency
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)`))
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		_, err = stmt.ExecContext(ctx,
+			entry.TransactionID,
+			entry.TransactionType,
+			entry.AccountNumber,
+			entry.EntryType,
+			entry.Amount.Amount,
+			entry.Amount.Currency,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l ledgerRepository) ListByAccountNumber(ctx context.Context, accountNumber int, param domain.LedgerEntryListParam) ([]domain.LedgerEntry, error) {
+	stmt, err := database.Conn(ctx, l.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			id,
+			transaction_id,
+			transaction_type,
+			account_number,
+			entry_type,
+			amount,
+			currency,
+			created_at
+		FROM ledger_entry
+		WHERE
+			account_number = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3
+	`))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, accountNumber, param.Limit, param.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var entries []domain.LedgerEntry
+	for rows.Next() {
+		var entry domain.LedgerEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.TransactionID,
+			&entry.TransactionType,
+			&entry.AccountNumber,
+			&entry.EntryType,
+			&entry.Amount.Amount,
+			&entry.Amount.Currency,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (l ledgerRepository) CountByAccountNumber(ctx context.Context, accountNumber int) (int, error) {
+	stmt, err := database.Conn(ctx, l.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			COUNT(*)
+		FROM ledger_entry
+		WHERE
+			account_number = $1
+	`))
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	err = stmt.QueryRowContext(ctx, accountNumber).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (l ledgerRepository) GetBalance(ctx context.Context, accountNumber int) (int64, error) {
+	stmt, err := database.Conn(ctx, l.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			COALESCE(SUM(CASE WHEN entry_type = 'credit' THEN amount ELSE -amount END), 0)
+		FROM ledger_entry
+		WHERE
+			account_number = $1
+	`))
+	if err != nil {
+		return 0, err
+	}
+
+	var balance int64
+	err = stmt.QueryRowContext(ctx, accountNumber).Scan(&balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+func NewLedgerRepository(db *sql.DB) domain.LedgerRepository {
+	return &ledgerRepository{
+		dbPool: db,
+	}
+}
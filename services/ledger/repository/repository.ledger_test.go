@@ -0,0 +1,173 @@
+package repository_ledger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/util"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func initMock() (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		log.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	return db, mock
+}
+
+func TestLedgerRepository_Store(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		INSERT INTO ledger_entry (
+			transaction_id,
+			transaction_type,
+			account_number,
+			entry_type,
+			amount,
+			currency
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)`)
+
+	transactionID := "6f1c1a4e-3f0a-4d4b-8f59-1f1d2b7c8a90"
+
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(transactionID, domain.LedgerTransactionTransfer, 555001, domain.LedgerEntryDebit, int64(1000), "IDR").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WithArgs(transactionID, domain.LedgerTransactionTransfer, 555002, domain.LedgerEntryCredit, int64(1000), "IDR").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	l := NewLedgerRepository(db)
+
+	err := l.Store(context.Background(), []domain.LedgerEntry{
+		{
+			TransactionID:   transactionID,
+			TransactionType: domain.LedgerTransactionTransfer,
+			AccountNumber:   555001,
+			EntryType:       domain.LedgerEntryDebit,
+			Amount:          domain.NewMoney(1000, "IDR"),
+		},
+		{
+			TransactionID:   transactionID,
+			TransactionType: domain.LedgerTransactionTransfer,
+			AccountNumber:   555002,
+			EntryType:       domain.LedgerEntryCredit,
+			Amount:          domain.NewMoney(1000, "IDR"),
+		},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLedgerRepository_ListByAccountNumber(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "transaction_id", "transaction_type", "account_number", "entry_type", "amount", "currency", "created_at"}).
+		AddRow(2, "6f1c1a4e-3f0a-4d4b-8f59-1f1d2b7c8a90", "transfer", 555001, "debit", 1000, "IDR", now).
+		AddRow(1, "0b6f5c1e-77d6-4a7a-9b43-5e3a2f1c0d9e", "opening", 555001, "credit", 10000, "IDR", now)
+
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			transaction_id,
+			transaction_type,
+			account_number,
+			entry_type,
+			amount,
+			currency,
+			created_at
+		FROM ledger_entry
+		WHERE
+			account_number = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3
+	`)
+
+	accountNumber := 555001
+	limit := 10
+	offset := 0
+
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectQuery().WithArgs(accountNumber, limit, offset).WillReturnRows(rows)
+
+	l := NewLedgerRepository(db)
+
+	entries, err := l.ListByAccountNumber(context.Background(), accountNumber, domain.LedgerEntryListParam{
+		Filter: util.Filter{
+			Limit:  limit,
+			Offset: offset,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestLedgerRepository_CountByAccountNumber(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"count"}).AddRow(2)
+
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*)
+		FROM ledger_entry
+		WHERE
+			account_number = $1
+	`)
+
+	accountNumber := 555001
+
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectQuery().WithArgs(accountNumber).WillReturnRows(rows)
+
+	l := NewLedgerRepository(db)
+
+	total, err := l.CountByAccountNumber(context.Background(), accountNumber)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+}
+
+func TestLedgerRepository_GetBalance(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"balance"}).AddRow(9000)
+
+	query := fmt.Sprintf(`
+		SELECT
+			COALESCE(SUM(CASE WHEN entry_type = 'credit' THEN amount ELSE -amount END), 0)
+		FROM ledger_entry
+		WHERE
+			account_number = $1
+	`)
+
+	accountNumber := 555001
+
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectQuery().WithArgs(accountNumber).WillReturnRows(rows)
+
+	l := NewLedgerRepository(db)
+
+	balance, err := l.GetBalance(context.Background(), accountNumber)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9000), balance)
+}
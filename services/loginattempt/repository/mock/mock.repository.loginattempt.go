@@ -0,0 +1,36 @@
+package loginattempt_repository_mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type LoginAttemptMockRepository struct {
+	mock.Mock
+}
+
+func (l *LoginAttemptMockRepository) RegisterFailure(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	args := l.Called(ctx, subject, window)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (l *LoginAttemptMockRepository) Block(ctx context.Context, subject string, duration time.Duration) error {
+	args := l.Called(ctx, subject, duration)
+
+	return args.Error(0)
+}
+
+func (l *LoginAttemptMockRepository) BlockedFor(ctx context.Context, subject string) (time.Duration, error) {
+	args := l.Called(ctx, subject)
+
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (l *LoginAttemptMockRepository) Reset(ctx context.Context, subject string) error {
+	args := l.Called(ctx, subject)
+
+	return args.Error(0)
+}
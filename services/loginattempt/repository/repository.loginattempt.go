@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	failuresKeyPrefix = "login_failures:"
+	blockKeyPrefix    = "login_block:"
+)
+
+type loginAttemptRepository struct {
+	redisClient *redis.Client
+}
+
+func (l loginAttemptRepository) RegisterFailure(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+
+	// The window starts with the first failure, later ones do not extend it.
+	// Creating the counter with its expiry in the same transaction as the
+	// increment leaves no counter behind that never expires.
+	_, err := l.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, failuresKeyPrefix+subject, 0, window)
+		incr = pipe.Incr(ctx, failuresKeyPrefix+subject)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+func (l loginAttemptRepository) Block(ctx context.Context, subject string, duration time.Duration) error {
+	err := l.redisClient.Set(ctx, blockKeyPrefix+subject, "1", duration).Err()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (l loginAttemptRepository) BlockedFor(ctx context.Context, subject string) (time.Duration, error) {
+	ttl, err := l.redisClient.PTTL(ctx, blockKeyPrefix+subject).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// Missing keys report a negative TTL.
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (l loginAttemptRepository) Reset(ctx context.Context, subject string) error {
+	err := l.redisClient.Del(ctx, failuresKeyPrefix+subject, blockKeyPrefix+subject).Err()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewLoginAttemptRepository(client *redis.Client) domain.LoginAttemptRepository {
+	return &loginAttemptRepository{redisClient: client}
+}
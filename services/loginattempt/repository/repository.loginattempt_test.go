@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-redis/redis/v8"
+	redismock "github.com/go-redis/redismock/v8"
+)
+
+var subject = "email:mail@email.com"
+
+func TestLoginAttemptRepository_RegisterFailure(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("First-failure-starts-window", func(t *testing.T) {
+		mockRedis.ExpectTxPipeline()
+		mockRedis.ExpectSetNX(failuresKeyPrefix+subject, 0, time.Minute).SetVal(true)
+		mockRedis.ExpectIncr(failuresKeyPrefix + subject).SetVal(1)
+		mockRedis.ExpectTxPipelineExec()
+
+		l := NewLoginAttemptRepository(client)
+		n, err := l.RegisterFailure(ctx, subject, time.Minute)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		assert.NoError(t, mockRedis.ExpectationsWereMet())
+	})
+
+	t.Run("Later-failure", func(t *testing.T) {
+		mockRedis.ExpectTxPipeline()
+		mockRedis.ExpectSetNX(failuresKeyPrefix+subject, 0, time.Minute).SetVal(false)
+		mockRedis.ExpectIncr(failuresKeyPrefix + subject).SetVal(2)
+		mockRedis.ExpectTxPipelineExec()
+
+		l := NewLoginAttemptRepository(client)
+		n, err := l.RegisterFailure(ctx, subject, time.Minute)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		assert.NoError(t, mockRedis.ExpectationsWereMet())
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRedis.ExpectTxPipeline()
+		mockRedis.ExpectSetNX(failuresKeyPrefix+subject, 0, time.Minute).SetVal(true)
+		mockRedis.ExpectIncr(failuresKeyPrefix + subject).SetErr(redis.ErrClosed)
+		mockRedis.ExpectTxPipelineExec()
+
+		l := NewLoginAttemptRepository(client)
+		_, err := l.RegisterFailure(ctx, subject, time.Minute)
+		assert.Error(t, err)
+	})
+}
+
+func TestLoginAttemptRepository_Block(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRedis.ExpectSet(blockKeyPrefix+subject, "1", time.Minute).SetVal("OK")
+
+		l := NewLoginAttemptRepository(client)
+		err := l.Block(ctx, subject, time.Minute)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRedis.ExpectSet(blockKeyPrefix+subject, "1", time.Minute).SetErr(redis.ErrClosed)
+
+		l := NewLoginAttemptRepository(client)
+		err := l.Block(ctx, subject, time.Minute)
+		assert.Error(t, err)
+	})
+}
+
+func TestLoginAttemptRepository_BlockedFor(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("Blocked", func(t *testing.T) {
+		mockRedis.ExpectPTTL(blockKeyPrefix + subject).SetVal(30 * time.Second)
+
+		l := NewLoginAttemptRepository(client)
+		d, err := l.BlockedFor(ctx, subject)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 30*time.Second, d)
+	})
+
+	t.Run("Not-blocked", func(t *testing.T) {
+		mockRedis.ExpectPTTL(blockKeyPrefix + subject).SetVal(-2 * time.Millisecond)
+
+		l := NewLoginAttemptRepository(client)
+		d, err := l.BlockedFor(ctx, subject)
+
+		assert.NoError(t, err)
+		assert.Zero(t, d)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRedis.ExpectPTTL(blockKeyPrefix + subject).SetErr(redis.ErrClosed)
+
+		l := NewLoginAttemptRepository(client)
+		_, err := l.BlockedFor(ctx, subject)
+		assert.Error(t, err)
+	})
+}
+
+func TestLoginAttemptRepository_Reset(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRedis.ExpectDel(failuresKeyPrefix+subject, blockKeyPrefix+subject).SetVal(2)
+
+		l := NewLoginAttemptRepository(client)
+		err := l.Reset(ctx, subject)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRedis.ExpectDel(failuresKeyPrefix+subject, blockKeyPrefix+subject).SetErr(redis.ErrClosed)
+
+		l := NewLoginAttemptRepository(client)
+		err := l.Reset(ctx, subject)
+		assert.Error(t, err)
+	})
+}
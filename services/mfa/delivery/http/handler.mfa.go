@@ -0,0 +1,62 @@
+package delivery_http_mfa
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware"
+	"github.com/oniharnantyo/golang-backend-example/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+type MFAHandler struct {
+	mfaUseCase domain.MFAUseCase
+	logger     *logrus.Logger
+}
+
+func NewMFAHandler(r *gin.Engine, m domain.MFAUseCase, au domain.AuthUseCase, l *logrus.Logger) *gin.Engine {
+	handler := &MFAHandler{mfaUseCase: m, logger: l}
+
+	auth := middleware.JWT(au)
+
+	r.POST("/mfa/totp", auth, handler.HandlerEnrollTOTP)
+	r.POST("/mfa/totp/confirm", auth, handler.HandlerConfirmTOTP)
+
+	return r
+}
+
+func (m *MFAHandler) HandlerEnrollTOTP(ctx *gin.Context) {
+	claims, _ := middleware.GetAccessClaims(ctx)
+
+	enrollment, err := m.mfaUseCase.Enroll(ctx, claims.AccountNumber())
+	if err != nil {
+		m.logger.Errorf("%s : %v", "MFAHandler/HandlerEnrollTOTP/Enroll", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, util.Response{Data: enrollment})
+}
+
+func (m *MFAHandler) HandlerConfirmTOTP(ctx *gin.Context) {
+	var param domain.TOTPConfirmParam
+	err := ctx.ShouldBindJSON(&param)
+	if err != nil {
+		m.logger.Errorf("%s : %v", "MFAHandler/HandlerConfirmTOTP/ParseBodyData", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	claims, _ := middleware.GetAccessClaims(ctx)
+
+	response, err := m.mfaUseCase.Confirm(ctx, claims.AccountNumber(), param.Code)
+	if err != nil {
+		m.logger.Errorf("%s : %v", "MFAHandler/HandlerConfirmTOTP/Confirm", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: response})
+}
@@ -0,0 +1,120 @@
+package delivery_http_mfa
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware/testutil"
+	auth_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/auth/usecase/mock"
+	mfa_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/mfa/usecase/mock"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func authAs(accountNumber string) *auth_usecase_mock.AuthMockUseCase {
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Subject: accountNumber},
+		Role:           domain.RoleCustomer,
+	}, nil)
+
+	return mockAuthUseCase
+}
+
+func TestMFAHandler_HandlerEnrollTOTP(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		body   string
+	}{
+		{"success", nil, http.StatusCreated, `{"data":{"secret":"SECRET","otpauth_uri":"otpauth://totp/Bank:555001?secret=SECRET"}}`},
+		{"already-enabled", domain.ErrMFAAlreadyEnabled, http.StatusConflict, `{"code":"mfa_already_enabled","errors":["Two-factor authentication is already enabled"]}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockMFAUseCase := new(mfa_usecase_mock.MFAMockUseCase)
+			mockMFAUseCase.On("Enroll", mock.Anything, 555001).Return(domain.TOTPEnrollment{
+				Secret: "SECRET",
+				URI:    "otpauth://totp/Bank:555001?secret=SECRET",
+			}, c.err).Once()
+
+			r := testutil.NewRouter()
+			r = NewMFAHandler(r, mockMFAUseCase, authAs("555001"), logger)
+
+			req, err := http.NewRequest(http.MethodPost, "/mfa/totp", nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+			assert.Equal(t, c.body, rec.Body.String())
+			mockMFAUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMFAHandler_HandlerConfirmTOTP(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"success", nil, http.StatusOK},
+		{"wrong-code", domain.ErrInvalidMFACode, http.StatusUnauthorized},
+		{"already-enabled", domain.ErrMFAAlreadyEnabled, http.StatusConflict},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockMFAUseCase := new(mfa_usecase_mock.MFAMockUseCase)
+			mockMFAUseCase.On("Confirm", mock.Anything, 555001, "123456").Return(domain.RecoveryCodesResponse{
+				RecoveryCodes: []string{"abcd-efgh"},
+			}, c.err).Once()
+
+			r := testutil.NewRouter()
+			r = NewMFAHandler(r, mockMFAUseCase, authAs("555001"), logger)
+
+			req, err := http.NewRequest(http.MethodPost, "/mfa/totp/confirm", bytes.NewBufferString(`{"code":"123456"}`))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+			mockMFAUseCase.AssertExpectations(t)
+		})
+	}
+
+	t.Run("missing-token", func(t *testing.T) {
+		r := testutil.NewRouter()
+		r = NewMFAHandler(r, new(mfa_usecase_mock.MFAMockUseCase), new(auth_usecase_mock.AuthMockUseCase), logger)
+
+		req, err := http.NewRequest(http.MethodPost, "/mfa/totp/confirm", bytes.NewBufferString(`{"code":"123456"}`))
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
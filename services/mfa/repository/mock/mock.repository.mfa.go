@@ -0,0 +1,66 @@
+package repository_mfa_mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MFAMockRepository struct {
+	mock.Mock
+}
+
+func (m *MFAMockRepository) GetTOTP(ctx context.Context, accountNumber int) (domain.TOTP, error) {
+	args := m.Called(ctx, accountNumber)
+
+	return args.Get(0).(domain.TOTP), args.Error(1)
+}
+
+func (m *MFAMockRepository) StoreTOTP(ctx context.Context, t *domain.TOTP) error {
+	args := m.Called(ctx, t)
+
+	return args.Error(0)
+}
+
+func (m *MFAMockRepository) EnableTOTP(ctx context.Context, accountNumber int) error {
+	args := m.Called(ctx, accountNumber)
+
+	return args.Error(0)
+}
+
+func (m *MFAMockRepository) UseStep(ctx context.Context, accountNumber int, step int64) (bool, error) {
+	args := m.Called(ctx, accountNumber, step)
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MFAMockRepository) StoreRecoveryCodes(ctx context.Context, accountNumber int, hashes []string) error {
+	args := m.Called(ctx, accountNumber, hashes)
+
+	return args.Error(0)
+}
+
+func (m *MFAMockRepository) UseRecoveryCode(ctx context.Context, accountNumber int, hash string) (bool, error) {
+	args := m.Called(ctx, accountNumber, hash)
+
+	return args.Bool(0), args.Error(1)
+}
+
+type MFAChallengeMockRepository struct {
+	mock.Mock
+}
+
+func (m *MFAChallengeMockRepository) Store(ctx context.Context, token string, accountNumber int, expire time.Duration) error {
+	args := m.Called(ctx, token, accountNumber, expire)
+
+	return args.Error(0)
+}
+
+func (m *MFAChallengeMockRepository) Consume(ctx context.Context, token string) (int, error) {
+	args := m.Called(ctx, token)
+
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,61 @@
+package repository_mfa
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const challengeKeyPrefix = "mfa_challenge:"
+
+type mfaChallengeRepository struct {
+	redisClient *redis.Client
+}
+
+// challengeKey keys the challenge by the hash of its token.
+func challengeKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return challengeKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+func (m mfaChallengeRepository) Store(ctx context.Context, token string, accountNumber int, expire time.Duration) error {
+	err := m.redisClient.Set(ctx, challengeKey(token), accountNumber, expire).Err()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m mfaChallengeRepository) Consume(ctx context.Context, token string) (int, error) {
+	var get *redis.StringCmd
+
+	_, err := m.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, challengeKey(token))
+		pipe.Del(ctx, challengeKey(token))
+		return nil
+	})
+	if err == redis.Nil {
+		return 0, domain.ErrInvalidMFAToken
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	accountNumber, err := strconv.Atoi(get.Val())
+	if err != nil {
+		return 0, err
+	}
+
+	return accountNumber, nil
+}
+
+func NewMFAChallengeRepository(client *redis.Client) domain.MFAChallengeRepository {
+	return &mfaChallengeRepository{redisClient: client}
+}
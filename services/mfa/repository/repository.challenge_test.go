@@ -0,0 +1,58 @@
+package repository_mfa
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/assert"
+
+	redismock "github.com/go-redis/redismock/v8"
+)
+
+func TestMFAChallengeRepository_Store(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+	mockRedis.ExpectSet(challengeKey("challenge"), 555001, 5*time.Minute).SetVal("OK")
+
+	m := NewMFAChallengeRepository(client)
+	err := m.Store(ctx, "challenge", 555001, 5*time.Minute)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mockRedis.ExpectationsWereMet())
+}
+
+func TestMFAChallengeRepository_Consume(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRedis.ExpectTxPipeline()
+		mockRedis.ExpectGet(challengeKey("challenge")).SetVal("555001")
+		mockRedis.ExpectDel(challengeKey("challenge")).SetVal(1)
+		mockRedis.ExpectTxPipelineExec()
+
+		m := NewMFAChallengeRepository(client)
+		accountNumber, err := m.Consume(ctx, "challenge")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 555001, accountNumber)
+		assert.NoError(t, mockRedis.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown-challenge", func(t *testing.T) {
+		mockRedis.ExpectTxPipeline()
+		mockRedis.ExpectGet(challengeKey("challenge")).RedisNil()
+		mockRedis.ExpectDel(challengeKey("challenge")).SetVal(0)
+		mockRedis.ExpectTxPipelineExec()
+
+		m := NewMFAChallengeRepository(client)
+		_, err := m.Consume(ctx, "challenge")
+
+		assert.Equal(t, domain.ErrInvalidMFAToken, err)
+	})
+}
@@ -0,0 +1,190 @@
+package repository_mfa
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/oniharnantyo/golang-backend-example/database"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+)
+
+type mfaRepository struct {
+	dbPool *sql.DB
+}
+
+func (m mfaRepository) GetTOTP(ctx context.Context, accountNumber int) (domain.TOTP, error) {
+	stmt, err := database.Conn(ctx, m.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			account_number,
+			secret,
+			enabled,
+			last_step
+		FROM account_totp
+		WHERE
+			account_number = $1
+	`))
+	if err != nil {
+		return domain.TOTP{}, err
+	}
+
+	var totp domain.TOTP
+	err = stmt.QueryRowContext(ctx, accountNumber).Scan(
+		&totp.AccountNumber,
+		&totp.Secret,
+		&totp.Enabled,
+		&totp.LastStep,
+	)
+	if err != nil {
+		return domain.TOTP{}, err
+	}
+
+	return totp, nil
+}
+
+func (m mfaRepository) StoreTOTP(ctx context.Context, t *domain.TOTP) error {
+	stmt, err := database.Conn(ctx, m.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		INSERT INTO account_totp (
+			account_number,
+			secret,
+			enabled,
+			last_step
+		) VALUES (
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (account_number) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			enabled = EXCLUDED.enabled,
+			last_step = EXCLUDED.last_step`))
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx,
+		t.AccountNumber,
+		t.Secret,
+		t.Enabled,
+		t.LastStep,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m mfaRepository) EnableTOTP(ctx context.Context, accountNumber int) error {
+	stmt, err := database.Conn(ctx, m.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE account_totp SET
+			enabled = TRUE
+		WHERE
+			account_number = $1
+	`))
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, accountNumber)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m mfaRepository) UseStep(ctx context.Context, accountNumber int, step int64) (bool, error) {
+	// The condition makes concurrent requests with the same code race for
+	// one row update, only the first one wins.
+	stmt, err := database.Conn(ctx, m.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE account_totp SET
+			last_step = $1
+		WHERE
+			account_number = $2 AND
+			last_step < $1
+	`))
+	if err != nil {
+		return false, err
+	}
+
+	result, err := stmt.ExecContext(ctx, step, accountNumber)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (m mfaRepository) StoreRecoveryCodes(ctx context.Context, accountNumber int, hashes []string) error {
+	deleteStmt, err := database.Conn(ctx, m.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		DELETE FROM account_recovery_code
+		WHERE
+			account_number = $1
+	`))
+	if err != nil {
+		return err
+	}
+
+	_, err = deleteStmt.ExecContext(ctx, accountNumber)
+	if err != nil {
+		return err
+	}
+
+	insertStmt, err := database.Conn(ctx, m.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		INSERT INTO account_recovery_code (
+			account_number,
+			code_hash
+		) VALUES (
+			$1, $2
+		)`))
+	if err != nil {
+		return err
+	}
+
+	defer insertStmt.Close()
+
+	for _, hash := range hashes {
+		_, err = insertStmt.ExecContext(ctx, accountNumber, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m mfaRepository) UseRecoveryCode(ctx context.Context, accountNumber int, hash string) (bool, error) {
+	stmt, err := database.Conn(ctx, m.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE account_recovery_code SET
+			used_at = NOW()
+		WHERE
+			account_number = $1 AND
+			code_hash = $2 AND
+			used_at IS NULL
+	`))
+	if err != nil {
+		return false, err
+	}
+
+	result, err := stmt.ExecContext(ctx, accountNumber, hash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func NewMFARepository(db *sql.DB) domain.MFARepository {
+	return &mfaRepository{
+		dbPool: db,
+	}
+}
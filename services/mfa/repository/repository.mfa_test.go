@@ -0,0 +1,202 @@
+package repository_mfa
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func initMock() (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		log.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	return db, mock
+}
+
+func TestMFARepository_GetTOTP(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"account_number", "secret", "enabled", "last_step"}).
+		AddRow(555001, "SECRET", true, 100)
+
+	query := fmt.Sprintf(`
+		SELECT
+			account_number,
+			secret,
+			enabled,
+			last_step
+		FROM account_totp
+		WHERE
+			account_number = $1
+	`)
+
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectQuery().WithArgs(555001).WillReturnRows(rows)
+
+	m := NewMFARepository(db)
+
+	totp, err := m.GetTOTP(context.Background(), 555001)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TOTP{AccountNumber: 555001, Secret: "SECRET", Enabled: true, LastStep: 100}, totp)
+}
+
+func TestMFARepository_StoreTOTP(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		INSERT INTO account_totp (
+			account_number,
+			secret,
+			enabled,
+			last_step
+		) VALUES (
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (account_number) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			enabled = EXCLUDED.enabled,
+			last_step = EXCLUDED.last_step`)
+
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(555001, "SECRET", false, 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	m := NewMFARepository(db)
+
+	err := m.StoreTOTP(context.Background(), &domain.TOTP{AccountNumber: 555001, Secret: "SECRET"})
+	assert.NoError(t, err)
+}
+
+func TestMFARepository_EnableTOTP(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		UPDATE account_totp SET
+			enabled = TRUE
+		WHERE
+			account_number = $1
+	`)
+
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(555001).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	m := NewMFARepository(db)
+
+	err := m.EnableTOTP(context.Background(), 555001)
+	assert.NoError(t, err)
+}
+
+func TestMFARepository_UseStep(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		UPDATE account_totp SET
+			last_step = $1
+		WHERE
+			account_number = $2 AND
+			last_step < $1
+	`)
+
+	m := NewMFARepository(db)
+
+	t.Run("New-step", func(t *testing.T) {
+		prep := mock.ExpectPrepare(query)
+		prep.ExpectExec().WithArgs(101, 555001).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		ok, err := m.UseStep(context.Background(), 555001, 101)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Used-step", func(t *testing.T) {
+		prep := mock.ExpectPrepare(query)
+		prep.ExpectExec().WithArgs(101, 555001).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		ok, err := m.UseStep(context.Background(), 555001, 101)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestMFARepository_StoreRecoveryCodes(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	deleteQuery := fmt.Sprintf(`
+		DELETE FROM account_recovery_code
+		WHERE
+			account_number = $1
+	`)
+	insertQuery := fmt.Sprintf(`
+		INSERT INTO account_recovery_code (
+			account_number,
+			code_hash
+		) VALUES (
+			$1, $2
+		)`)
+
+	mock.ExpectPrepare(deleteQuery).ExpectExec().WithArgs(555001).WillReturnResult(sqlmock.NewResult(0, 2))
+	prep := mock.ExpectPrepare(insertQuery)
+	prep.ExpectExec().WithArgs(555001, "hash-1").WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WithArgs(555001, "hash-2").WillReturnResult(sqlmock.NewResult(2, 1))
+
+	m := NewMFARepository(db)
+
+	err := m.StoreRecoveryCodes(context.Background(), 555001, []string{"hash-1", "hash-2"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFARepository_UseRecoveryCode(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		UPDATE account_recovery_code SET
+			used_at = NOW()
+		WHERE
+			account_number = $1 AND
+			code_hash = $2 AND
+			used_at IS NULL
+	`)
+
+	m := NewMFARepository(db)
+
+	t.Run("Unused-code", func(t *testing.T) {
+		prep := mock.ExpectPrepare(query)
+		prep.ExpectExec().WithArgs(555001, "hash-1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		ok, err := m.UseRecoveryCode(context.Background(), 555001, "hash-1")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Used-code", func(t *testing.T) {
+		prep := mock.ExpectPrepare(query)
+		prep.ExpectExec().WithArgs(555001, "hash-1").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		ok, err := m.UseRecoveryCode(context.Background(), 555001, "hash-1")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
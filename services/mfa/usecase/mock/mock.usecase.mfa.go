@@ -0,0 +1,55 @@
+package mfa_usecase_mock
+
+import (
+	"context"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MFAMockUseCase struct {
+	mock.Mock
+}
+
+func (m *MFAMockUseCase) Enroll(ctx context.Context, accountNumber int) (domain.TOTPEnrollment, error) {
+	args := m.Called(ctx, accountNumber)
+
+	return args.Get(0).(domain.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MFAMockUseCase) Confirm(ctx context.Context, accountNumber int, code string) (domain.RecoveryCodesResponse, error) {
+	args := m.Called(ctx, accountNumber, code)
+
+	return args.Get(0).(domain.RecoveryCodesResponse), args.Error(1)
+}
+
+func (m *MFAMockUseCase) Enabled(ctx context.Context, accountNumber int) (bool, error) {
+	args := m.Called(ctx, accountNumber)
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MFAMockUseCase) Verify(ctx context.Context, accountNumber int, code string) error {
+	args := m.Called(ctx, accountNumber, code)
+
+	return args.Error(0)
+}
+
+func (m *MFAMockUseCase) VerifyStepUp(ctx context.Context, accountNumber int, amount domain.Money, code string) error {
+	args := m.Called(ctx, accountNumber, amount, code)
+
+	return args.Error(0)
+}
+
+func (m *MFAMockUseCase) CreateChallenge(ctx context.Context, accountNumber int) (string, error) {
+	args := m.Called(ctx, accountNumber)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MFAMockUseCase) ConsumeChallenge(ctx context.Context, token string) (int, error) {
+	args := m.Called(ctx, token)
+
+	return args.Int(0), args.Error(1)
+}
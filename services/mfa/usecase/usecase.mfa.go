@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/totp"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// skew is how many time steps a code may be off, to allow for clock drift.
+const skew = 1
+
+type mfaUseCase struct {
+	mfaRepository          domain.MFARepository
+	mfaChallengeRepository domain.MFAChallengeRepository
+	transactionManager     domain.TransactionManager
+	loginAttemptRepository domain.LoginAttemptRepository
+	fxRateUseCase          domain.FXRateUseCase
+	mfaPolicy              domain.MFAPolicy
+	logger                 *logrus.Logger
+}
+
+// Enroll creates a new, not yet enabled secret. Enrolling again before the
+// confirmation replaces the secret.
+func (m mfaUseCase) Enroll(ctx context.Context, accountNumber int) (domain.TOTPEnrollment, error) {
+	current, err := m.mfaRepository.GetTOTP(ctx, accountNumber)
+	if err != nil && errors.Cause(err) != sql.ErrNoRows {
+		m.logger.Errorf("mfaUseCase/Enroll/GetTOTP :%v", err)
+		return domain.TOTPEnrollment{}, err
+	}
+
+	if current.Enabled {
+		return domain.TOTPEnrollment{}, domain.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/Enroll/GenerateSecret :%v", err)
+		return domain.TOTPEnrollment{}, err
+	}
+
+	err = m.mfaRepository.StoreTOTP(ctx, &domain.TOTP{
+		AccountNumber: accountNumber,
+		Secret:        secret,
+	})
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/Enroll/StoreTOTP :%v", err)
+		return domain.TOTPEnrollment{}, err
+	}
+
+	return domain.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(m.mfaPolicy.Issuer, strconv.Itoa(accountNumber), secret),
+	}, nil
+}
+
+func (m mfaUseCase) Confirm(ctx context.Context, accountNumber int, code string) (domain.RecoveryCodesResponse, error) {
+	current, err := m.mfaRepository.GetTOTP(ctx, accountNumber)
+	if errors.Cause(err) == sql.ErrNoRows {
+		return domain.RecoveryCodesResponse{}, domain.ErrMFANotEnrolled
+	}
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/Confirm/GetTOTP :%v", err)
+		return domain.RecoveryCodesResponse{}, err
+	}
+
+	if current.Enabled {
+		return domain.RecoveryCodesResponse{}, domain.ErrMFAAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(current.Secret, code, time.Now(), skew)
+	if !ok {
+		return domain.RecoveryCodesResponse{}, domain.ErrInvalidMFACode
+	}
+
+	codes, hashes, err := m.generateRecoveryCodes()
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/Confirm/generateRecoveryCodes :%v", err)
+		return domain.RecoveryCodesResponse{}, err
+	}
+
+	err = m.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		ok, err := m.mfaRepository.UseStep(ctx, accountNumber, step)
+		if err != nil {
+			m.logger.Errorf("mfaUseCase/Confirm/UseStep :%v", err)
+			return err
+		}
+
+		if !ok {
+			return domain.ErrInvalidMFACode
+		}
+
+		err = m.mfaRepository.EnableTOTP(ctx, accountNumber)
+		if err != nil {
+			m.logger.Errorf("mfaUseCase/Confirm/EnableTOTP :%v", err)
+			return err
+		}
+
+		err = m.mfaRepository.StoreRecoveryCodes(ctx, accountNumber, hashes)
+		if err != nil {
+			m.logger.Errorf("mfaUseCase/Confirm/StoreRecoveryCodes :%v", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.RecoveryCodesResponse{}, err
+	}
+
+	return domain.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (m mfaUseCase) Enabled(ctx context.Context, accountNumber int) (bool, error) {
+	current, err := m.mfaRepository.GetTOTP(ctx, accountNumber)
+	if errors.Cause(err) == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/Enabled/GetTOTP :%v", err)
+		return false, err
+	}
+
+	return current.Enabled, nil
+}
+
+func (m mfaUseCase) Verify(ctx context.Context, accountNumber int, code string) error {
+	current, err := m.mfaRepository.GetTOTP(ctx, accountNumber)
+	if errors.Cause(err) == sql.ErrNoRows {
+		return domain.ErrMFANotEnrolled
+	}
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/Verify/GetTOTP :%v", err)
+		return err
+	}
+
+	if !current.Enabled {
+		return domain.ErrMFANotEnrolled
+	}
+
+	var ok bool
+	if len(code) == totp.Digits {
+		step, valid := totp.Validate(current.Secret, code, time.Now(), skew)
+		if !valid {
+			return domain.ErrInvalidMFACode
+		}
+
+		ok, err = m.mfaRepository.UseStep(ctx, accountNumber, step)
+		if err != nil {
+			m.logger.Errorf("mfaUseCase/Verify/UseStep :%v", err)
+			return err
+		}
+	} else {
+		ok, err = m.mfaRepository.UseRecoveryCode(ctx, accountNumber, hashRecoveryCode(code))
+		if err != nil {
+			m.logger.Errorf("mfaUseCase/Verify/UseRecoveryCode :%v", err)
+			return err
+		}
+	}
+
+	if !ok {
+		return domain.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+func (m mfaUseCase) VerifyStepUp(ctx context.Context, accountNumber int, amount domain.Money, code string) error {
+	if m.mfaPolicy.StepUpAmount <= 0 {
+		return nil
+	}
+
+	required, err := m.stepUpRequired(ctx, amount)
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/VerifyStepUp/stepUpRequired :%v", err)
+		return err
+	}
+
+	if !required {
+		return nil
+	}
+
+	subject := stepUpSubject(accountNumber)
+
+	blockedFor, err := m.loginAttemptRepository.BlockedFor(ctx, subject)
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/VerifyStepUp/BlockedFor :%v", err)
+		return err
+	}
+
+	if blockedFor > 0 {
+		return domain.StepUpThrottledError{RetryAfter: blockedFor}
+	}
+
+	if code == "" {
+		return domain.ErrMFARequired
+	}
+
+	err = m.Verify(ctx, accountNumber, code)
+	if errors.Cause(err) == domain.ErrInvalidMFACode {
+		failErr := m.registerStepUpFailure(ctx, subject)
+		if failErr != nil {
+			m.logger.Errorf("mfaUseCase/VerifyStepUp/registerStepUpFailure :%v", failErr)
+			return failErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	err = m.loginAttemptRepository.Reset(ctx, subject)
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/VerifyStepUp/Reset :%v", err)
+		return err
+	}
+
+	return nil
+}
+
+// stepUpRequired reports whether amount reaches the step-up threshold. An
+// amount in another currency is compared with the converted threshold, without
+// a usable rate the code is asked for rather than the transfer refused.
+func (m mfaUseCase) stepUpRequired(ctx context.Context, amount domain.Money) (bool, error) {
+	threshold := domain.NewMoney(m.mfaPolicy.StepUpAmount, m.mfaPolicy.StepUpCurrency)
+	if amount.Currency != threshold.Currency {
+		converted, _, err := m.fxRateUseCase.Convert(ctx, threshold, amount.Currency)
+		switch errors.Cause(err) {
+		case nil:
+			threshold = converted
+		case domain.ErrFXRateNotFound, domain.ErrFXRateStale:
+			m.logger.Warnf("mfaUseCase/stepUpRequired/Convert :%v", err)
+			return true, nil
+		default:
+			return false, err
+		}
+	}
+
+	return amount.Amount >= threshold.Amount, nil
+}
+
+// registerStepUpFailure counts a wrong step-up code and locks the step-up of
+// the account once it reaches the limit. A limit of zero never locks.
+func (m mfaUseCase) registerStepUpFailure(ctx context.Context, subject string) error {
+	if m.mfaPolicy.StepUpMaxFailures <= 0 {
+		return nil
+	}
+
+	failures, err := m.loginAttemptRepository.RegisterFailure(ctx, subject, m.mfaPolicy.StepUpLockDuration)
+	if err != nil {
+		return err
+	}
+
+	if failures < int64(m.mfaPolicy.StepUpMaxFailures) {
+		return nil
+	}
+
+	return m.loginAttemptRepository.Block(ctx, subject, m.mfaPolicy.StepUpLockDuration)
+}
+
+func (m mfaUseCase) CreateChallenge(ctx context.Context, accountNumber int) (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/CreateChallenge/Read :%v", err)
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	err = m.mfaChallengeRepository.Store(ctx, token, accountNumber, m.mfaPolicy.ChallengeExpire)
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/CreateChallenge/Store :%v", err)
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (m mfaUseCase) ConsumeChallenge(ctx context.Context, token string) (int, error) {
+	accountNumber, err := m.mfaChallengeRepository.Consume(ctx, token)
+	if err != nil {
+		m.logger.Errorf("mfaUseCase/ConsumeChallenge/Consume :%v", err)
+		return 0, err
+	}
+
+	return accountNumber, nil
+}
+
+// generateRecoveryCodes returns the codes to show once and the hashes to
+// store.
+func (m mfaUseCase) generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, m.mfaPolicy.RecoveryCodes)
+	hashes := make([]string, m.mfaPolicy.RecoveryCodes)
+
+	for i := range codes {
+		b := make([]byte, 5)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+// stepUpSubject keys the failed step-up codes of an account.
+func stepUpSubject(accountNumber int) string {
+	return "step_up:" + strconv.Itoa(accountNumber)
+}
+
+// hashRecoveryCode hashes the code without its formatting, so "ABCD-EFGH" and
+// "abcdefgh" match the same code.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func NewMFAUseCase(m domain.MFARepository, mc domain.MFAChallengeRepository, tm domain.TransactionManager, la domain.LoginAttemptRepository, fx domain.FXRateUseCase, p domain.MFAPolicy, log *logrus.Logger) domain.MFAUseCase {
+	return &mfaUseCase{
+		mfaRepository:          m,
+		mfaChallengeRepository: mc,
+		transactionManager:     tm,
+		loginAttemptRepository: la,
+		fxRateUseCase:          fx,
+		mfaPolicy:              p,
+		logger:                 log,
+	}
+}
@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	database_mock "github.com/oniharnantyo/golang-backend-example/database/mock"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	fxrate_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/fxrate/usecase/mock"
+	loginattempt_repository_mock "github.com/oniharnantyo/golang-backend-example/services/loginattempt/repository/mock"
+	repository_mfa_mock "github.com/oniharnantyo/golang-backend-example/services/mfa/repository/mock"
+	"github.com/oniharnantyo/golang-backend-example/totp"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	mfaPolicy = domain.MFAPolicy{
+		Issuer:             "Bank",
+		ChallengeExpire:    5 * time.Minute,
+		StepUpAmount:       1000000,
+		StepUpCurrency:     "IDR",
+		StepUpMaxFailures:  3,
+		StepUpLockDuration: 15 * time.Minute,
+		RecoveryCodes:      10,
+	}
+
+	secret = "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+)
+
+func currentCode(t *testing.T) string {
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	assert.NoError(t, err)
+
+	return code
+}
+
+func TestMFAUseCase_Enroll(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(domain.TOTP{}, sql.ErrNoRows).Once()
+		mockMFARepo.On("StoreTOTP", mock.Anything, mock.MatchedBy(func(t *domain.TOTP) bool {
+			return t.AccountNumber == 555001 && t.Secret != "" && !t.Enabled
+		})).Return(nil).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		enrollment, err := m.Enroll(context.Background(), 555001)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, enrollment.Secret)
+		assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Bank:555001?"))
+
+		mockMFARepo.AssertExpectations(t)
+	})
+
+	t.Run("Already-enabled", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(domain.TOTP{AccountNumber: 555001, Secret: secret, Enabled: true}, nil).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		_, err := m.Enroll(context.Background(), 555001)
+		assert.Equal(t, domain.ErrMFAAlreadyEnabled, err)
+	})
+}
+
+func TestMFAUseCase_Confirm(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockTransaction := new(database_mock.TransactionMockManager)
+
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(domain.TOTP{AccountNumber: 555001, Secret: secret}, nil).Once()
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+		mockMFARepo.On("UseStep", mock.Anything, 555001, mock.AnythingOfType("int64")).Return(true, nil).Once()
+		mockMFARepo.On("EnableTOTP", mock.Anything, 555001).Return(nil).Once()
+		mockMFARepo.On("StoreRecoveryCodes", mock.Anything, 555001, mock.MatchedBy(func(hashes []string) bool {
+			return len(hashes) == mfaPolicy.RecoveryCodes
+		})).Return(nil).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), mockTransaction, new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		response, err := m.Confirm(context.Background(), 555001, currentCode(t))
+		assert.NoError(t, err)
+		assert.Len(t, response.RecoveryCodes, mfaPolicy.RecoveryCodes)
+
+		mockMFARepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong-code", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(domain.TOTP{AccountNumber: 555001, Secret: secret}, nil).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		_, err := m.Confirm(context.Background(), 555001, "000000x")
+		assert.Equal(t, domain.ErrInvalidMFACode, err)
+	})
+
+	t.Run("Not-enrolled", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(domain.TOTP{}, sql.ErrNoRows).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		_, err := m.Confirm(context.Background(), 555001, currentCode(t))
+		assert.Equal(t, domain.ErrMFANotEnrolled, err)
+	})
+}
+
+func TestMFAUseCase_Verify(t *testing.T) {
+	logger := logrus.New()
+
+	enabled := domain.TOTP{AccountNumber: 555001, Secret: secret, Enabled: true}
+
+	t.Run("TOTP-code", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(enabled, nil).Once()
+		mockMFARepo.On("UseStep", mock.Anything, 555001, mock.AnythingOfType("int64")).Return(true, nil).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		err := m.Verify(context.Background(), 555001, currentCode(t))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Reused-TOTP-code", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(enabled, nil).Once()
+		mockMFARepo.On("UseStep", mock.Anything, 555001, mock.AnythingOfType("int64")).Return(false, nil).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		err := m.Verify(context.Background(), 555001, currentCode(t))
+		assert.Equal(t, domain.ErrInvalidMFACode, err)
+	})
+
+	t.Run("Recovery-code", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(enabled, nil).Once()
+		mockMFARepo.On("UseRecoveryCode", mock.Anything, 555001, hashRecoveryCode("abcd-efgh")).Return(true, nil).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		err := m.Verify(context.Background(), 555001, "ABCD EFGH")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Not-enabled", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(domain.TOTP{AccountNumber: 555001, Secret: secret}, nil).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		err := m.Verify(context.Background(), 555001, currentCode(t))
+		assert.Equal(t, domain.ErrMFANotEnrolled, err)
+	})
+}
+
+func TestMFAUseCase_VerifyStepUp(t *testing.T) {
+	logger := logrus.New()
+
+	enabled := domain.TOTP{AccountNumber: 555001, Secret: secret, Enabled: true}
+
+	newLoginAttemptRepo := func(blockedFor time.Duration) *loginattempt_repository_mock.LoginAttemptMockRepository {
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+		mockLoginAttemptRepo.On("BlockedFor", mock.Anything, "step_up:555001").Return(blockedFor, nil).Maybe()
+
+		return mockLoginAttemptRepo
+	}
+
+	t.Run("Below-threshold", func(t *testing.T) {
+		m := NewMFAUseCase(new(repository_mfa_mock.MFAMockRepository), new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		err := m.VerifyStepUp(context.Background(), 555001, domain.NewMoney(mfaPolicy.StepUpAmount-1, "IDR"), "")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Missing-code", func(t *testing.T) {
+		m := NewMFAUseCase(new(repository_mfa_mock.MFAMockRepository), new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), newLoginAttemptRepo(0), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		err := m.VerifyStepUp(context.Background(), 555001, domain.NewMoney(mfaPolicy.StepUpAmount, "IDR"), "")
+		assert.Equal(t, domain.ErrMFARequired, err)
+	})
+
+	t.Run("Valid-code", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(enabled, nil).Once()
+		mockMFARepo.On("UseStep", mock.Anything, 555001, mock.AnythingOfType("int64")).Return(true, nil).Once()
+
+		mockLoginAttemptRepo := newLoginAttemptRepo(0)
+		mockLoginAttemptRepo.On("Reset", mock.Anything, "step_up:555001").Return(nil).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), mockLoginAttemptRepo, new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		err := m.VerifyStepUp(context.Background(), 555001, domain.NewMoney(mfaPolicy.StepUpAmount, "IDR"), currentCode(t))
+		assert.NoError(t, err)
+		mockLoginAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong-code", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(enabled, nil).Once()
+		mockMFARepo.On("UseRecoveryCode", mock.Anything, 555001, hashRecoveryCode("wrong-code")).Return(false, nil).Once()
+
+		mockLoginAttemptRepo := newLoginAttemptRepo(0)
+		mockLoginAttemptRepo.On("RegisterFailure", mock.Anything, "step_up:555001", mfaPolicy.StepUpLockDuration).Return(int64(1), nil).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), mockLoginAttemptRepo, new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		err := m.VerifyStepUp(context.Background(), 555001, domain.NewMoney(mfaPolicy.StepUpAmount, "IDR"), "wrong-code")
+		assert.Equal(t, domain.ErrInvalidMFACode, err)
+		mockLoginAttemptRepo.AssertExpectations(t)
+		mockLoginAttemptRepo.AssertNotCalled(t, "Block", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Wrong-code-locks", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, 555001).Return(enabled, nil).Once()
+		mockMFARepo.On("UseRecoveryCode", mock.Anything, 555001, hashRecoveryCode("wrong-code")).Return(false, nil).Once()
+
+		mockLoginAttemptRepo := newLoginAttemptRepo(0)
+		mockLoginAttemptRepo.On("RegisterFailure", mock.Anything, "step_up:555001", mfaPolicy.StepUpLockDuration).Return(int64(mfaPolicy.StepUpMaxFailures), nil).Once()
+		mockLoginAttemptRepo.On("Block", mock.Anything, "step_up:555001", mfaPolicy.StepUpLockDuration).Return(nil).Once()
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), mockLoginAttemptRepo, new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		err := m.VerifyStepUp(context.Background(), 555001, domain.NewMoney(mfaPolicy.StepUpAmount, "IDR"), "wrong-code")
+		assert.Equal(t, domain.ErrInvalidMFACode, err)
+		mockLoginAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		mockMFARepo := new(repository_mfa_mock.MFAMockRepository)
+
+		m := NewMFAUseCase(mockMFARepo, new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), newLoginAttemptRepo(10*time.Minute), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+		err := m.VerifyStepUp(context.Background(), 555001, domain.NewMoney(mfaPolicy.StepUpAmount, "IDR"), currentCode(t))
+		assert.Equal(t, domain.StepUpThrottledError{RetryAfter: 10 * time.Minute}, err)
+		mockMFARepo.AssertNotCalled(t, "GetTOTP", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Other-currency", func(t *testing.T) {
+		mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(mfaPolicy.StepUpAmount, "IDR"), "USD").Return(domain.NewMoney(70, "USD"), domain.FXRate{}, nil).Twice()
+
+		m := NewMFAUseCase(new(repository_mfa_mock.MFAMockRepository), new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), newLoginAttemptRepo(0), mockFXRateUseCase, mfaPolicy, logger)
+
+		// USD 0.69 is below IDR 10,000.00, USD 0.70 is not
+		err := m.VerifyStepUp(context.Background(), 555001, domain.NewMoney(69, "USD"), "")
+		assert.NoError(t, err)
+
+		err = m.VerifyStepUp(context.Background(), 555001, domain.NewMoney(70, "USD"), "")
+		assert.Equal(t, domain.ErrMFARequired, err)
+		mockFXRateUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Other-currency-without-rate", func(t *testing.T) {
+		mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(mfaPolicy.StepUpAmount, "IDR"), "USD").Return(domain.Money{}, domain.FXRate{}, domain.ErrFXRateNotFound).Once()
+
+		mockLoginAttemptRepo := new(loginattempt_repository_mock.LoginAttemptMockRepository)
+		mockLoginAttemptRepo.On("BlockedFor", mock.Anything, "step_up:555001").Return(time.Duration(0), nil).Once()
+
+		m := NewMFAUseCase(new(repository_mfa_mock.MFAMockRepository), new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), mockLoginAttemptRepo, mockFXRateUseCase, mfaPolicy, logger)
+
+		// Without a rate to compare the amount the code is asked for
+		err := m.VerifyStepUp(context.Background(), 555001, domain.NewMoney(1, "USD"), "")
+		assert.Equal(t, domain.ErrMFARequired, err)
+		mockFXRateUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Other-currency-rate-failed", func(t *testing.T) {
+		mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(mfaPolicy.StepUpAmount, "IDR"), "USD").Return(domain.Money{}, domain.FXRate{}, errors.New("Unexpected")).Once()
+
+		m := NewMFAUseCase(new(repository_mfa_mock.MFAMockRepository), new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), mockFXRateUseCase, mfaPolicy, logger)
+
+		err := m.VerifyStepUp(context.Background(), 555001, domain.NewMoney(1, "USD"), "")
+		assert.Error(t, err)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		policy := mfaPolicy
+		policy.StepUpAmount = 0
+
+		m := NewMFAUseCase(new(repository_mfa_mock.MFAMockRepository), new(repository_mfa_mock.MFAChallengeMockRepository), new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), policy, logger)
+
+		err := m.VerifyStepUp(context.Background(), 555001, domain.NewMoney(100000000, "IDR"), "")
+		assert.NoError(t, err)
+	})
+}
+
+func TestMFAUseCase_Challenge(t *testing.T) {
+	logger := logrus.New()
+
+	mockChallengeRepo := new(repository_mfa_mock.MFAChallengeMockRepository)
+
+	var token string
+	mockChallengeRepo.On("Store", mock.Anything, mock.AnythingOfType("string"), 555001, mfaPolicy.ChallengeExpire).Run(func(args mock.Arguments) {
+		token = args.String(1)
+	}).Return(nil).Once()
+
+	m := NewMFAUseCase(new(repository_mfa_mock.MFAMockRepository), mockChallengeRepo, new(database_mock.TransactionMockManager), new(loginattempt_repository_mock.LoginAttemptMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), mfaPolicy, logger)
+
+	created, err := m.CreateChallenge(context.Background(), 555001)
+	assert.NoError(t, err)
+	assert.Equal(t, token, created)
+
+	mockChallengeRepo.On("Consume", mock.Anything, created).Return(555001, nil).Once()
+
+	accountNumber, err := m.ConsumeChallenge(context.Background(), created)
+	assert.NoError(t, err)
+	assert.Equal(t, 555001, accountNumber)
+}
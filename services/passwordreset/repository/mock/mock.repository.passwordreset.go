@@ -0,0 +1,24 @@
+package passwordreset_repository_mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PasswordResetMockRepository struct {
+	mock.Mock
+}
+
+func (p *PasswordResetMockRepository) Store(ctx context.Context, token string, accountNumber int, expire time.Duration) error {
+	args := p.Called(ctx, token, accountNumber, expire)
+
+	return args.Error(0)
+}
+
+func (p *PasswordResetMockRepository) Consume(ctx context.Context, token string) (int, error) {
+	args := p.Called(ctx, token)
+
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const tokenKeyPrefix = "password_reset:"
+
+type passwordResetRepository struct {
+	redisClient *redis.Client
+}
+
+// tokenKey keys the token by its hash, so the tokens cannot be read back from
+// Redis.
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return tokenKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+func (p passwordResetRepository) Store(ctx context.Context, token string, accountNumber int, expire time.Duration) error {
+	err := p.redisClient.Set(ctx, tokenKey(token), accountNumber, expire).Err()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p passwordResetRepository) Consume(ctx context.Context, token string) (int, error) {
+	var get *redis.StringCmd
+
+	// Reading and deleting in one transaction lets only one caller get the
+	// account of a token.
+	_, err := p.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, tokenKey(token))
+		pipe.Del(ctx, tokenKey(token))
+		return nil
+	})
+	if err == redis.Nil {
+		return 0, domain.ErrInvalidResetToken
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	accountNumber, err := strconv.Atoi(get.Val())
+	if err != nil {
+		return 0, err
+	}
+
+	return accountNumber, nil
+}
+
+func NewPasswordResetRepository(client *redis.Client) domain.PasswordResetRepository {
+	return &passwordResetRepository{redisClient: client}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-redis/redis/v8"
+	redismock "github.com/go-redis/redismock/v8"
+)
+
+var token = "reset-token"
+
+func TestPasswordResetRepository_Store(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRedis.ExpectSet(tokenKey(token), 555001, 30*time.Minute).SetVal("OK")
+
+		p := NewPasswordResetRepository(client)
+		err := p.Store(ctx, token, 555001, 30*time.Minute)
+
+		assert.NoError(t, err)
+		assert.NotContains(t, tokenKey(token), token)
+		assert.NoError(t, mockRedis.ExpectationsWereMet())
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRedis.ExpectSet(tokenKey(token), 555001, 30*time.Minute).SetErr(redis.ErrClosed)
+
+		p := NewPasswordResetRepository(client)
+		err := p.Store(ctx, token, 555001, 30*time.Minute)
+
+		assert.Error(t, err)
+		assert.NoError(t, mockRedis.ExpectationsWereMet())
+	})
+}
+
+func TestPasswordResetRepository_Consume(t *testing.T) {
+	ctx := context.Background()
+
+	client, mockRedis := redismock.NewClientMock()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRedis.ExpectTxPipeline()
+		mockRedis.ExpectGet(tokenKey(token)).SetVal("555001")
+		mockRedis.ExpectDel(tokenKey(token)).SetVal(1)
+		mockRedis.ExpectTxPipelineExec()
+
+		p := NewPasswordResetRepository(client)
+		accountNumber, err := p.Consume(ctx, token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 555001, accountNumber)
+		assert.NoError(t, mockRedis.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown-token", func(t *testing.T) {
+		mockRedis.ExpectTxPipeline()
+		mockRedis.ExpectGet(tokenKey(token)).RedisNil()
+		mockRedis.ExpectDel(tokenKey(token)).SetVal(0)
+		mockRedis.ExpectTxPipelineExec()
+
+		p := NewPasswordResetRepository(client)
+		_, err := p.Consume(ctx, token)
+
+		assert.Equal(t, domain.ErrInvalidResetToken, err)
+	})
+}
@@ -0,0 +1,181 @@
+package delivery_http_scheduledtransfer
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware"
+	"github.com/oniharnantyo/golang-backend-example/util"
+
+	"github.com/pkg/errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ScheduledTransferHandler struct {
+	scheduledTransferUseCase domain.ScheduledTransferUseCase
+	logger                   *logrus.Logger
+}
+
+func NewScheduledTransferHandler(r *gin.Engine, s domain.ScheduledTransferUseCase, au domain.AuthUseCase, l *logrus.Logger) *gin.Engine {
+	handler := &ScheduledTransferHandler{scheduledTransferUseCase: s, logger: l}
+
+	auth := middleware.JWT(au)
+	ownerOrStaff := middleware.RequireOwnerOrRole("account_number", domain.RoleTeller, domain.RoleAdmin)
+
+	r.GET("/account/:account_number/scheduled-transfers", auth, ownerOrStaff, handler.HandlerGetScheduledTransferList)
+	r.GET("/account/:account_number/scheduled-transfers/:scheduled_transfer_id", auth, ownerOrStaff, handler.HandlerGetScheduledTransfer)
+	r.POST("/account/:account_number/scheduled-transfers", auth, handler.HandlerScheduledTransferStore)
+	r.PUT("/account/:account_number/scheduled-transfers/:scheduled_transfer_id", auth, handler.HandlerScheduledTransferUpdate)
+	r.DELETE("/account/:account_number/scheduled-transfers/:scheduled_transfer_id", auth, ownerOrStaff, handler.HandlerScheduledTransferCancel)
+
+	return r
+}
+
+func (s *ScheduledTransferHandler) HandlerGetScheduledTransferList(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerGetScheduledTransferList/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	scheduledTransfers, err := s.scheduledTransferUseCase.List(ctx, accountNumber)
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerGetScheduledTransferList/List", err)
+		ctx.Error(err)
+		return
+	}
+
+	if scheduledTransfers == nil {
+		scheduledTransfers = []domain.ScheduledTransfer{}
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: scheduledTransfers})
+}
+
+func (s *ScheduledTransferHandler) HandlerGetScheduledTransfer(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerGetScheduledTransfer/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("scheduled_transfer_id"), 10, 64)
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerGetScheduledTransfer/parseScheduledTransferID", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	scheduledTransfer, err := s.scheduledTransferUseCase.Get(ctx, accountNumber, id)
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerGetScheduledTransfer/Get", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: scheduledTransfer})
+}
+
+func (s *ScheduledTransferHandler) HandlerScheduledTransferStore(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferStore/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	// Only the owner of the sender account may schedule money out of it
+	claims, ok := middleware.GetAccessClaims(ctx)
+	if !ok || claims.AccountNumber() != accountNumber {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferStore/checkOwner", errors.New("caller does not own sender account"))
+		ctx.Error(domain.ErrForbidden)
+		return
+	}
+
+	var param domain.ScheduledTransferParam
+	err = ctx.ShouldBindJSON(&param)
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferStore/ParseBodyData", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	scheduledTransfer, err := s.scheduledTransferUseCase.Store(ctx, accountNumber, param)
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferStore/Store", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, util.Response{Data: scheduledTransfer})
+}
+
+func (s *ScheduledTransferHandler) HandlerScheduledTransferUpdate(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferUpdate/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	claims, ok := middleware.GetAccessClaims(ctx)
+	if !ok || claims.AccountNumber() != accountNumber {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferUpdate/checkOwner", errors.New("caller does not own sender account"))
+		ctx.Error(domain.ErrForbidden)
+		return
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("scheduled_transfer_id"), 10, 64)
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferUpdate/parseScheduledTransferID", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	var param domain.ScheduledTransferParam
+	err = ctx.ShouldBindJSON(&param)
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferUpdate/ParseBodyData", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	scheduledTransfer, err := s.scheduledTransferUseCase.Update(ctx, accountNumber, id, param)
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferUpdate/Update", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: scheduledTransfer})
+}
+
+func (s *ScheduledTransferHandler) HandlerScheduledTransferCancel(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferCancel/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("scheduled_transfer_id"), 10, 64)
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferCancel/parseScheduledTransferID", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	err = s.scheduledTransferUseCase.Cancel(ctx, accountNumber, id)
+	if err != nil {
+		s.logger.Errorf("%s : %v", "ScheduledTransferHandler/HandlerScheduledTransferCancel/Cancel", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,183 @@
+package delivery_http_scheduledtransfer
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware/testutil"
+	auth_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/auth/usecase/mock"
+	scheduledtransfer_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/scheduledtransfer/usecase/mock"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func authAs(accountNumber string, role string) *auth_usecase_mock.AuthMockUseCase {
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Subject: accountNumber},
+		Role:           role,
+	}, nil)
+
+	return mockAuthUseCase
+}
+
+func TestScheduledTransferHandler_HandlerGetScheduledTransferList(t *testing.T) {
+	logger := logrus.New()
+
+	at := time.Date(2021, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name          string
+		accountNumber string
+		role          string
+		status        int
+	}{
+		{"Owner", "555001", domain.RoleCustomer, http.StatusOK},
+		{"Teller", "555009", domain.RoleTeller, http.StatusOK},
+		{"Other-customer", "555002", domain.RoleCustomer, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockScheduledTransferUseCase := new(scheduledtransfer_usecase_mock.ScheduledTransferMockUseCase)
+			mockScheduledTransferUseCase.On("List", mock.Anything, 555001).Return([]domain.ScheduledTransfer{
+				{ID: 3, FromAccountNumber: 555001, ToAccountNumber: 555002, Amount: domain.NewMoney(5000, "IDR"), Recurrence: "0 9 1 * *",
+					NextRunAt: at, Status: domain.ScheduledTransferStatusActive, CreatedAt: at, UpdatedAt: at},
+			}, nil).Maybe()
+
+			r := testutil.NewRouter()
+			r = NewScheduledTransferHandler(r, mockScheduledTransferUseCase, authAs(c.accountNumber, c.role), logger)
+
+			req, err := http.NewRequest(http.MethodGet, "/account/555001/scheduled-transfers", nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+			if c.status == http.StatusOK {
+				assert.JSONEq(t, `{"data":[{"id":3,"from_account_number":555001,"to_account_number":555002,"amount":{"amount":"50.00","currency":"IDR"},"recurrence":"0 9 1 * *","next_run_at":"2021-05-01T09:00:00Z","status":"active","attempts":0,"created_at":"2021-05-01T09:00:00Z","updated_at":"2021-05-01T09:00:00Z"}]}`, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestScheduledTransferHandler_HandlerScheduledTransferStore(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name          string
+		accountNumber string
+		role          string
+		body          string
+		err           error
+		status        int
+	}{
+		{"Success", "555001", domain.RoleCustomer, `{"to_account_number":"555002","amount":{"amount":"50.00","currency":"IDR"},"recurrence":"0 9 1 * *"}`, nil, http.StatusCreated},
+		{"Invalid-recurrence", "555001", domain.RoleCustomer, `{"to_account_number":"555002","amount":{"amount":"50.00","currency":"IDR"},"recurrence":"soon"}`, domain.ErrInvalidRecurrence, http.StatusBadRequest},
+		{"Missing-receiver", "555001", domain.RoleCustomer, `{"amount":{"amount":"50.00","currency":"IDR"},"run_at":"2030-01-01T00:00:00Z"}`, nil, http.StatusBadRequest},
+		{"Teller", "555009", domain.RoleTeller, `{"to_account_number":"555002","amount":{"amount":"50.00","currency":"IDR"},"recurrence":"0 9 1 * *"}`, nil, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockScheduledTransferUseCase := new(scheduledtransfer_usecase_mock.ScheduledTransferMockUseCase)
+			mockScheduledTransferUseCase.On("Store", mock.Anything, 555001, mock.AnythingOfType("domain.ScheduledTransferParam")).Return(domain.ScheduledTransfer{ID: 3}, c.err).Maybe()
+
+			r := testutil.NewRouter()
+			r = NewScheduledTransferHandler(r, mockScheduledTransferUseCase, authAs(c.accountNumber, c.role), logger)
+
+			req, err := http.NewRequest(http.MethodPost, "/account/555001/scheduled-transfers", bytes.NewBufferString(c.body))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+		})
+	}
+}
+
+func TestScheduledTransferHandler_HandlerScheduledTransferUpdate(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		path   string
+		err    error
+		status int
+	}{
+		{"Success", "/account/555001/scheduled-transfers/3", nil, http.StatusOK},
+		{"Closed", "/account/555001/scheduled-transfers/3", domain.ErrScheduledTransferClosed, http.StatusConflict},
+		{"Invalid-id", "/account/555001/scheduled-transfers/x", nil, http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockScheduledTransferUseCase := new(scheduledtransfer_usecase_mock.ScheduledTransferMockUseCase)
+			mockScheduledTransferUseCase.On("Update", mock.Anything, 555001, int64(3), mock.AnythingOfType("domain.ScheduledTransferParam")).Return(domain.ScheduledTransfer{ID: 3}, c.err).Maybe()
+
+			r := testutil.NewRouter()
+			r = NewScheduledTransferHandler(r, mockScheduledTransferUseCase, authAs("555001", domain.RoleCustomer), logger)
+
+			req, err := http.NewRequest(http.MethodPut, c.path, bytes.NewBufferString(`{"to_account_number":"555002","amount":{"amount":"50.00","currency":"IDR"},"run_at":"2030-01-01T00:00:00Z"}`))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+		})
+	}
+}
+
+func TestScheduledTransferHandler_HandlerScheduledTransferCancel(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Success", nil, http.StatusNoContent},
+		{"Not-found", domain.ErrScheduledTransferNotFound, http.StatusNotFound},
+		{"Closed", domain.ErrScheduledTransferClosed, http.StatusConflict},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockScheduledTransferUseCase := new(scheduledtransfer_usecase_mock.ScheduledTransferMockUseCase)
+			mockScheduledTransferUseCase.On("Cancel", mock.Anything, 555001, int64(3)).Return(c.err).Maybe()
+
+			r := testutil.NewRouter()
+			r = NewScheduledTransferHandler(r, mockScheduledTransferUseCase, authAs("555009", domain.RoleTeller), logger)
+
+			req, err := http.NewRequest(http.MethodDelete, "/account/555001/scheduled-transfers/3", nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+		})
+	}
+}
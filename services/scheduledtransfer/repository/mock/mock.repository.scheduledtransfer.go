@@ -0,0 +1,50 @@
+package repository_scheduledtransfer_mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ScheduledTransferMockRepository struct {
+	mock.Mock
+}
+
+func (r *ScheduledTransferMockRepository) List(ctx context.Context, accountNumber int) ([]domain.ScheduledTransfer, error) {
+	args := r.Called(ctx, accountNumber)
+
+	return args.Get(0).([]domain.ScheduledTransfer), args.Error(1)
+}
+
+func (r *ScheduledTransferMockRepository) Get(ctx context.Context, accountNumber int, id int64) (domain.ScheduledTransfer, error) {
+	args := r.Called(ctx, accountNumber, id)
+
+	return args.Get(0).(domain.ScheduledTransfer), args.Error(1)
+}
+
+func (r *ScheduledTransferMockRepository) Store(ctx context.Context, s *domain.ScheduledTransfer) error {
+	args := r.Called(ctx, s)
+
+	return args.Error(0)
+}
+
+func (r *ScheduledTransferMockRepository) Update(ctx context.Context, s *domain.ScheduledTransfer) error {
+	args := r.Called(ctx, s)
+
+	return args.Error(0)
+}
+
+func (r *ScheduledTransferMockRepository) ClaimDue(ctx context.Context, now time.Time) (domain.ScheduledTransfer, error) {
+	args := r.Called(ctx, now)
+
+	return args.Get(0).(domain.ScheduledTransfer), args.Error(1)
+}
+
+func (r *ScheduledTransferMockRepository) UpdateRun(ctx context.Context, s *domain.ScheduledTransfer, dueAt time.Time) error {
+	args := r.Called(ctx, s, dueAt)
+
+	return args.Error(0)
+}
@@ -0,0 +1,323 @@
+package repository_scheduledtransfer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/database"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+)
+
+type scheduledTransferRepository struct {
+	dbPool *sql.DB
+}
+
+// List returns the scheduled transfers of the account, the newest first.
+func (r scheduledTransferRepository) List(ctx context.Context, accountNumber int) ([]domain.ScheduledTransfer, error) {
+	stmt, err := database.Conn(ctx, r.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			id,
+			from_account_number,
+			to_account_number,
+			amount,
+			currency,
+			COALESCE(description, ''),
+			COALESCE(recurrence, ''),
+			next_run_at,
+			status,
+			attempts,
+			COALESCE(last_error, ''),
+			COALESCE(last_transfer_id::TEXT, ''),
+			created_at,
+			updated_at
+		FROM scheduled_transfer
+		WHERE
+			from_account_number = $1
+		ORDER BY id DESC
+	`))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var scheduledTransfers []domain.ScheduledTransfer
+	for rows.Next() {
+		var s domain.ScheduledTransfer
+		err := rows.Scan(
+			&s.ID,
+			&s.FromAccountNumber,
+			&s.ToAccountNumber,
+			&s.Amount.Amount,
+			&s.Amount.Currency,
+			&s.Description,
+			&s.Recurrence,
+			&s.NextRunAt,
+			&s.Status,
+			&s.Attempts,
+			&s.LastError,
+			&s.LastTransferID,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		scheduledTransfers = append(scheduledTransfers, s)
+	}
+
+	return scheduledTransfers, nil
+}
+
+func (r scheduledTransferRepository) Get(ctx context.Context, accountNumber int, id int64) (domain.ScheduledTransfer, error) {
+	stmt, err := database.Conn(ctx, r.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			id,
+			from_account_number,
+			to_account_number,
+			amount,
+			currency,
+			COALESCE(description, ''),
+			COALESCE(recurrence, ''),
+			next_run_at,
+			status,
+			attempts,
+			COALESCE(last_error, ''),
+			COALESCE(last_transfer_id::TEXT, ''),
+			created_at,
+			updated_at
+		FROM scheduled_transfer
+		WHERE
+			id = $1 AND from_account_number = $2
+	`))
+	if err != nil {
+		return domain.ScheduledTransfer{}, err
+	}
+
+	var s domain.ScheduledTransfer
+	err = stmt.QueryRowContext(ctx, id, accountNumber).Scan(
+		&s.ID,
+		&s.FromAccountNumber,
+		&s.ToAccountNumber,
+		&s.Amount.Amount,
+		&s.Amount.Currency,
+		&s.Description,
+		&s.Recurrence,
+		&s.NextRunAt,
+		&s.Status,
+		&s.Attempts,
+		&s.LastError,
+		&s.LastTransferID,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return domain.ScheduledTransfer{}, err
+	}
+
+	return s, nil
+}
+
+func (r scheduledTransferRepository) Store(ctx context.Context, s *domain.ScheduledTransfer) error {
+	stmt, err := database.Conn(ctx, r.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		INSERT INTO scheduled_transfer (
+			from_account_number,
+			to_account_number,
+			amount,
+			currency,
+			description,
+			recurrence,
+			next_run_at,
+			status,
+			attempts,
+			created_at,
+			updated_at
+		) VALUES (
+			$1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, $10, $11
+		)
+		RETURNING id`))
+	if err != nil {
+		return err
+	}
+
+	err = stmt.QueryRowContext(ctx,
+		s.FromAccountNumber,
+		s.ToAccountNumber,
+		s.Amount.Amount,
+		s.Amount.Currency,
+		s.Description,
+		s.Recurrence,
+		s.NextRunAt,
+		s.Status,
+		s.Attempts,
+		s.CreatedAt,
+		s.UpdatedAt,
+	).Scan(&s.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r scheduledTransferRepository) Update(ctx context.Context, s *domain.ScheduledTransfer) error {
+	stmt, err := database.Conn(ctx, r.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE scheduled_transfer
+		SET
+			to_account_number = $1,
+			amount = $2,
+			currency = $3,
+			description = NULLIF($4, ''),
+			recurrence = NULLIF($5, ''),
+			next_run_at = $6,
+			status = $7,
+			attempts = $8,
+			last_error = NULLIF($9, ''),
+			updated_at = $10
+		WHERE
+			id = $11 AND from_account_number = $12 AND status IN ('active', 'failed')
+	`))
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx,
+		s.ToAccountNumber,
+		s.Amount.Amount,
+		s.Amount.Currency,
+		s.Description,
+		s.Recurrence,
+		s.NextRunAt,
+		s.Status,
+		s.Attempts,
+		s.LastError,
+		s.UpdatedAt,
+		s.ID,
+		s.FromAccountNumber,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClaimDue takes the row lock with SKIP LOCKED, so every replica running the
+// scheduler claims a different transfer and none runs one twice.
+func (r scheduledTransferRepository) ClaimDue(ctx context.Context, now time.Time) (domain.ScheduledTransfer, error) {
+	stmt, err := database.Conn(ctx, r.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			id,
+			from_account_number,
+			to_account_number,
+			amount,
+			currency,
+			COALESCE(description, ''),
+			COALESCE(recurrence, ''),
+			next_run_at,
+			status,
+			attempts,
+			COALESCE(last_error, ''),
+			COALESCE(last_transfer_id::TEXT, ''),
+			created_at,
+			updated_at
+		FROM scheduled_transfer
+		WHERE
+			status = 'active' AND next_run_at <= $1
+		ORDER BY next_run_at ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`))
+	if err != nil {
+		return domain.ScheduledTransfer{}, err
+	}
+
+	var s domain.ScheduledTransfer
+	err = stmt.QueryRowContext(ctx, now).Scan(
+		&s.ID,
+		&s.FromAccountNumber,
+		&s.ToAccountNumber,
+		&s.Amount.Amount,
+		&s.Amount.Currency,
+		&s.Description,
+		&s.Recurrence,
+		&s.NextRunAt,
+		&s.Status,
+		&s.Attempts,
+		&s.LastError,
+		&s.LastTransferID,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return domain.ScheduledTransfer{}, err
+	}
+
+	return s, nil
+}
+
+func (r scheduledTransferRepository) UpdateRun(ctx context.Context, s *domain.ScheduledTransfer, dueAt time.Time) error {
+	stmt, err := database.Conn(ctx, r.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		UPDATE scheduled_transfer
+		SET
+			next_run_at = $1,
+			status = $2,
+			attempts = $3,
+			last_error = NULLIF($4, ''),
+			last_transfer_id = NULLIF($5, '')::UUID,
+			updated_at = $6
+		WHERE
+			id = $7 AND status = 'active' AND next_run_at = $8
+	`))
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx,
+		s.NextRunAt,
+		s.Status,
+		s.Attempts,
+		s.LastError,
+		s.LastTransferID,
+		s.UpdatedAt,
+		s.ID,
+		dueAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func NewScheduledTransferRepository(db *sql.DB) domain.ScheduledTransferRepository {
+	return &scheduledTransferRepository{
+		dbPool: db,
+	}
+}
@@ -0,0 +1,301 @@
+package repository_scheduledtransfer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func initMock() (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		log.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	return db, mock
+}
+
+var columns = []string{"id", "from_account_number", "to_account_number", "amount", "currency", "description", "recurrence",
+	"next_run_at", "status", "attempts", "last_error", "last_transfer_id", "created_at", "updated_at"}
+
+func TestScheduledTransferRepository_Store(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		INSERT INTO scheduled_transfer (
+			from_account_number,
+			to_account_number,
+			amount,
+			currency,
+			description,
+			recurrence,
+			next_run_at,
+			status,
+			attempts,
+			created_at,
+			updated_at
+		) VALUES (
+			$1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, $10, $11
+		)
+		RETURNING id`)
+
+	now := time.Now()
+	nextRunAt := now.Add(time.Hour)
+	mock.ExpectPrepare(query).ExpectQuery().
+		WithArgs(555001, 555002, int64(5000), "IDR", "rent", "0 9 1 * *", nextRunAt, domain.ScheduledTransferStatusActive, 0, now, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	s := NewScheduledTransferRepository(db)
+
+	scheduledTransfer := domain.ScheduledTransfer{
+		FromAccountNumber: 555001,
+		ToAccountNumber:   555002,
+		Amount:            domain.NewMoney(5000, "IDR"),
+		Description:       "rent",
+		Recurrence:        "0 9 1 * *",
+		NextRunAt:         nextRunAt,
+		Status:            domain.ScheduledTransferStatusActive,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	err := s.Store(context.Background(), &scheduledTransfer)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), scheduledTransfer.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduledTransferRepository_List(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	now := time.Now()
+	rows := sqlmock.NewRows(columns).
+		AddRow(4, 555001, 555002, 5000, "IDR", "", "", now.Add(time.Hour), "active", 0, "", "", now, now).
+		AddRow(3, 555001, 555003, 1000, "IDR", "rent", "0 9 1 * *", now.Add(time.Hour), "active", 2, "insufficient_balance", "", now, now)
+
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			from_account_number,
+			to_account_number,
+			amount,
+			currency,
+			COALESCE(description, ''),
+			COALESCE(recurrence, ''),
+			next_run_at,
+			status,
+			attempts,
+			COALESCE(last_error, ''),
+			COALESCE(last_transfer_id::TEXT, ''),
+			created_at,
+			updated_at
+		FROM scheduled_transfer
+		WHERE
+			from_account_number = $1
+		ORDER BY id DESC
+	`)
+
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs(555001).WillReturnRows(rows)
+
+	s := NewScheduledTransferRepository(db)
+
+	scheduledTransfers, err := s.List(context.Background(), 555001)
+	assert.NoError(t, err)
+	assert.Len(t, scheduledTransfers, 2)
+	assert.Equal(t, "0 9 1 * *", scheduledTransfers[1].Recurrence)
+	assert.Equal(t, "insufficient_balance", scheduledTransfers[1].LastError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduledTransferRepository_Update(t *testing.T) {
+	query := fmt.Sprintf(`
+		UPDATE scheduled_transfer
+		SET
+			to_account_number = $1,
+			amount = $2,
+			currency = $3,
+			description = NULLIF($4, ''),
+			recurrence = NULLIF($5, ''),
+			next_run_at = $6,
+			status = $7,
+			attempts = $8,
+			last_error = NULLIF($9, ''),
+			updated_at = $10
+		WHERE
+			id = $11 AND from_account_number = $12 AND status IN ('active', 'failed')
+	`)
+
+	now := time.Now()
+	scheduledTransfer := domain.ScheduledTransfer{
+		ID:                3,
+		FromAccountNumber: 555001,
+		ToAccountNumber:   555002,
+		Amount:            domain.NewMoney(5000, "IDR"),
+		NextRunAt:         now.Add(time.Hour),
+		Status:            domain.ScheduledTransferStatusCancelled,
+		UpdatedAt:         now,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(555002, int64(5000), "IDR", "", "", now.Add(time.Hour), "cancelled", 0, "", now, int64(3), 555001).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		s := NewScheduledTransferRepository(db)
+
+		err := s.Update(context.Background(), &scheduledTransfer)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(555002, int64(5000), "IDR", "", "", now.Add(time.Hour), "cancelled", 0, "", now, int64(3), 555001).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		s := NewScheduledTransferRepository(db)
+
+		err := s.Update(context.Background(), &scheduledTransfer)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestScheduledTransferRepository_ClaimDue(t *testing.T) {
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			from_account_number,
+			to_account_number,
+			amount,
+			currency,
+			COALESCE(description, ''),
+			COALESCE(recurrence, ''),
+			next_run_at,
+			status,
+			attempts,
+			COALESCE(last_error, ''),
+			COALESCE(last_transfer_id::TEXT, ''),
+			created_at,
+			updated_at
+		FROM scheduled_transfer
+		WHERE
+			status = 'active' AND next_run_at <= $1
+		ORDER BY next_run_at ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`)
+
+	now := time.Now()
+
+	t.Run("Due", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		rows := sqlmock.NewRows(columns).
+			AddRow(3, 555001, 555002, 5000, "IDR", "rent", "0 9 1 * *", now.Add(-time.Minute), "active", 0, "", "", now, now)
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(now).WillReturnRows(rows)
+
+		s := NewScheduledTransferRepository(db)
+
+		scheduledTransfer, err := s.ClaimDue(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), scheduledTransfer.ID)
+		assert.Equal(t, domain.NewMoney(5000, "IDR"), scheduledTransfer.Amount)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Nothing-due", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(now).WillReturnRows(sqlmock.NewRows(columns))
+
+		s := NewScheduledTransferRepository(db)
+
+		_, err := s.ClaimDue(context.Background(), now)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestScheduledTransferRepository_UpdateRun(t *testing.T) {
+	query := fmt.Sprintf(`
+		UPDATE scheduled_transfer
+		SET
+			next_run_at = $1,
+			status = $2,
+			attempts = $3,
+			last_error = NULLIF($4, ''),
+			last_transfer_id = NULLIF($5, '')::UUID,
+			updated_at = $6
+		WHERE
+			id = $7 AND status = 'active' AND next_run_at = $8
+	`)
+
+	now := time.Now()
+	dueAt := now.Add(-time.Minute)
+	scheduledTransfer := domain.ScheduledTransfer{
+		ID:             3,
+		NextRunAt:      now.Add(time.Hour),
+		Status:         domain.ScheduledTransferStatusActive,
+		Attempts:       1,
+		LastError:      "insufficient_balance",
+		LastTransferID: "",
+		UpdatedAt:      now,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(now.Add(time.Hour), "active", 1, "insufficient_balance", "", now, int64(3), dueAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		s := NewScheduledTransferRepository(db)
+
+		err := s.UpdateRun(context.Background(), &scheduledTransfer, dueAt)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Moved-on", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectExec().
+			WithArgs(now.Add(time.Hour), "active", 1, "insufficient_balance", "", now, int64(3), dueAt).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		s := NewScheduledTransferRepository(db)
+
+		err := s.UpdateRun(context.Background(), &scheduledTransfer, dueAt)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
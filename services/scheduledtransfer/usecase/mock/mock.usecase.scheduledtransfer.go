@@ -0,0 +1,49 @@
+package scheduledtransfer_usecase_mock
+
+import (
+	"context"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ScheduledTransferMockUseCase struct {
+	mock.Mock
+}
+
+func (s *ScheduledTransferMockUseCase) List(ctx context.Context, accountNumber int) ([]domain.ScheduledTransfer, error) {
+	args := s.Called(ctx, accountNumber)
+
+	return args.Get(0).([]domain.ScheduledTransfer), args.Error(1)
+}
+
+func (s *ScheduledTransferMockUseCase) Get(ctx context.Context, accountNumber int, id int64) (domain.ScheduledTransfer, error) {
+	args := s.Called(ctx, accountNumber, id)
+
+	return args.Get(0).(domain.ScheduledTransfer), args.Error(1)
+}
+
+func (s *ScheduledTransferMockUseCase) Store(ctx context.Context, accountNumber int, param domain.ScheduledTransferParam) (domain.ScheduledTransfer, error) {
+	args := s.Called(ctx, accountNumber, param)
+
+	return args.Get(0).(domain.ScheduledTransfer), args.Error(1)
+}
+
+func (s *ScheduledTransferMockUseCase) Update(ctx context.Context, accountNumber int, id int64, param domain.ScheduledTransferParam) (domain.ScheduledTransfer, error) {
+	args := s.Called(ctx, accountNumber, id, param)
+
+	return args.Get(0).(domain.ScheduledTransfer), args.Error(1)
+}
+
+func (s *ScheduledTransferMockUseCase) Cancel(ctx context.Context, accountNumber int, id int64) error {
+	args := s.Called(ctx, accountNumber, id)
+
+	return args.Error(0)
+}
+
+func (s *ScheduledTransferMockUseCase) RunDue(ctx context.Context) (int, error) {
+	args := s.Called(ctx)
+
+	return args.Int(0), args.Error(1)
+}
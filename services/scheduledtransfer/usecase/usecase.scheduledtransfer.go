@@ -0,0 +1,410 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/cron"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type scheduledTransferUseCase struct {
+	scheduledTransferRepository domain.ScheduledTransferRepository
+	accountRepository           domain.AccountRepository
+	accountUseCase              domain.AccountUseCase
+	mfaUseCase                  domain.MFAUseCase
+	transactionManager          domain.TransactionManager
+	policy                      domain.SchedulerPolicy
+	logger                      *logrus.Logger
+}
+
+func (s scheduledTransferUseCase) List(ctx context.Context, accountNumber int) ([]domain.ScheduledTransfer, error) {
+	scheduledTransfers, err := s.scheduledTransferRepository.List(ctx, accountNumber)
+	if err != nil {
+		s.logger.Errorf("scheduledTransferUseCase/List/List :%v", err)
+		return nil, err
+	}
+
+	return scheduledTransfers, nil
+}
+
+func (s scheduledTransferUseCase) Get(ctx context.Context, accountNumber int, id int64) (domain.ScheduledTransfer, error) {
+	scheduledTransfer, err := s.scheduledTransferRepository.Get(ctx, accountNumber, id)
+	if err != nil {
+		s.logger.Errorf("scheduledTransferUseCase/Get/Get :%v", err)
+		if errors.Cause(err) == sql.ErrNoRows {
+			return domain.ScheduledTransfer{}, domain.ErrScheduledTransferNotFound
+		}
+		return domain.ScheduledTransfer{}, err
+	}
+
+	return scheduledTransfer, nil
+}
+
+// Store checks the transfer the way Transfer does, including the step-up, so
+// the runs themselves need no OTP. The code is used up in the same
+// transaction as the store.
+func (s scheduledTransferUseCase) Store(ctx context.Context, accountNumber int, param domain.ScheduledTransferParam) (domain.ScheduledTransfer, error) {
+	now := time.Now().UTC()
+
+	var scheduledTransfer domain.ScheduledTransfer
+	err := s.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		scheduledTransfer, err = s.plan(ctx, accountNumber, param, now)
+		if err != nil {
+			return err
+		}
+
+		scheduledTransfer.Status = domain.ScheduledTransferStatusActive
+		scheduledTransfer.CreatedAt = now
+		scheduledTransfer.UpdatedAt = now
+
+		err = s.scheduledTransferRepository.Store(ctx, &scheduledTransfer)
+		if err != nil {
+			s.logger.Errorf("scheduledTransferUseCase/Store/Store :%v", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.ScheduledTransfer{}, err
+	}
+
+	return scheduledTransfer, nil
+}
+
+func (s scheduledTransferUseCase) Update(ctx context.Context, accountNumber int, id int64, param domain.ScheduledTransferParam) (domain.ScheduledTransfer, error) {
+	current, err := s.Get(ctx, accountNumber, id)
+	if err != nil {
+		return domain.ScheduledTransfer{}, err
+	}
+
+	if closed(current) {
+		return domain.ScheduledTransfer{}, domain.ErrScheduledTransferClosed
+	}
+
+	now := time.Now().UTC()
+
+	var scheduledTransfer domain.ScheduledTransfer
+	err = s.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		scheduledTransfer, err = s.plan(ctx, accountNumber, param, now)
+		if err != nil {
+			return err
+		}
+
+		scheduledTransfer.ID = current.ID
+		scheduledTransfer.Status = domain.ScheduledTransferStatusActive
+		scheduledTransfer.LastTransferID = current.LastTransferID
+		scheduledTransfer.CreatedAt = current.CreatedAt
+		scheduledTransfer.UpdatedAt = now
+
+		err = s.scheduledTransferRepository.Update(ctx, &scheduledTransfer)
+		if err != nil {
+			s.logger.Errorf("scheduledTransferUseCase/Update/Update :%v", err)
+			// Completed or cancelled since it was read
+			if errors.Cause(err) == sql.ErrNoRows {
+				return domain.ErrScheduledTransferClosed
+			}
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.ScheduledTransfer{}, err
+	}
+
+	return scheduledTransfer, nil
+}
+
+func (s scheduledTransferUseCase) Cancel(ctx context.Context, accountNumber int, id int64) error {
+	scheduledTransfer, err := s.Get(ctx, accountNumber, id)
+	if err != nil {
+		return err
+	}
+
+	if closed(scheduledTransfer) {
+		return domain.ErrScheduledTransferClosed
+	}
+
+	scheduledTransfer.Status = domain.ScheduledTransferStatusCancelled
+	scheduledTransfer.UpdatedAt = time.Now().UTC()
+
+	err = s.scheduledTransferRepository.Update(ctx, &scheduledTransfer)
+	if err != nil {
+		s.logger.Errorf("scheduledTransferUseCase/Cancel/Update :%v", err)
+		if errors.Cause(err) == sql.ErrNoRows {
+			return domain.ErrScheduledTransferClosed
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RunDue runs due transfers one transaction each, so a failed transfer does
+// not hold back the others and a crash loses at most the one in flight.
+func (s scheduledTransferUseCase) RunDue(ctx context.Context) (int, error) {
+	ran := 0
+	for ran < s.policy.BatchSize {
+		if err := ctx.Err(); err != nil {
+			return ran, err
+		}
+
+		found, err := s.runNext(ctx)
+		if err != nil {
+			return ran, err
+		}
+
+		if !found {
+			break
+		}
+
+		ran++
+	}
+
+	return ran, nil
+}
+
+// runNext claims the transfer that is due the longest, runs it and stores the
+// outcome in the same transaction. It reports false when nothing is due.
+func (s scheduledTransferUseCase) runNext(ctx context.Context) (bool, error) {
+	now := time.Now().UTC()
+
+	var claimed domain.ScheduledTransfer
+	var transferErr error
+
+	err := s.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		scheduledTransfer, err := s.scheduledTransferRepository.ClaimDue(ctx, now)
+		if err != nil {
+			return err
+		}
+
+		claimed = scheduledTransfer
+
+		// The owner passed the step-up when scheduling the transfer
+		transfer, err := s.accountUseCase.Transfer(domain.WithStepUpVerified(ctx), scheduledTransfer.FromAccountNumber, domain.TransferParam{
+			ToAccountNumber: strconv.Itoa(scheduledTransfer.ToAccountNumber),
+			Amount:          scheduledTransfer.Amount,
+			Description:     scheduledTransfer.Description,
+		})
+		if err != nil {
+			transferErr = err
+			return err
+		}
+
+		dueAt := scheduledTransfer.NextRunAt
+
+		scheduledTransfer.Attempts = 0
+		scheduledTransfer.LastError = ""
+		scheduledTransfer.LastTransferID = transfer.ID
+		scheduledTransfer.UpdatedAt = now
+		s.advance(&scheduledTransfer, now, domain.ScheduledTransferStatusCompleted)
+
+		return s.scheduledTransferRepository.UpdateRun(ctx, &scheduledTransfer, dueAt)
+	})
+	if transferErr != nil {
+		s.logger.Errorf("scheduledTransferUseCase/runNext/Transfer :%v", transferErr)
+		return true, s.recordFailure(ctx, claimed, transferErr, now)
+	}
+
+	if err != nil {
+		if claimed.ID == 0 && errors.Cause(err) == sql.ErrNoRows {
+			return false, nil
+		}
+
+		s.logger.Errorf("scheduledTransferUseCase/runNext/WithinTransaction :%v", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// recordFailure stores a failed run after its transaction rolled back. The
+// transfer is retried with an exponential backoff until it ran MaxAttempts
+// times; a recurring transfer then waits for its next match, a one-off one
+// fails.
+func (s scheduledTransferUseCase) recordFailure(ctx context.Context, scheduledTransfer domain.ScheduledTransfer, transferErr error, now time.Time) error {
+	dueAt := scheduledTransfer.NextRunAt
+
+	scheduledTransfer.Attempts++
+	switch cause := errors.Cause(transferErr).(type) {
+	case *domain.Error:
+		scheduledTransfer.LastError = cause.Code
+	case domain.TransferLimitExceededError:
+		scheduledTransfer.LastError = domain.TransferLimitExceededCode
+	default:
+		scheduledTransfer.LastError = domain.ErrInternal.Code
+	}
+	scheduledTransfer.UpdatedAt = now
+
+	if scheduledTransfer.Attempts < s.policy.MaxAttempts {
+		scheduledTransfer.NextRunAt = now.Add(s.retryDelay(scheduledTransfer.Attempts))
+	} else {
+		s.advance(&scheduledTransfer, now, domain.ScheduledTransferStatusFailed)
+		// The next match starts over
+		if scheduledTransfer.Status == domain.ScheduledTransferStatusActive {
+			scheduledTransfer.Attempts = 0
+		}
+	}
+
+	err := s.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.scheduledTransferRepository.UpdateRun(ctx, &scheduledTransfer, dueAt)
+	})
+	if err != nil {
+		// Another replica ran or changed it in the meantime
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil
+		}
+
+		s.logger.Errorf("scheduledTransferUseCase/recordFailure/UpdateRun :%v", err)
+		return err
+	}
+
+	return nil
+}
+
+// advance moves a recurring transfer to its next match. A one-off transfer, or
+// one whose rule matches no more, gets the status instead.
+func (s scheduledTransferUseCase) advance(scheduledTransfer *domain.ScheduledTransfer, now time.Time, status string) {
+	if scheduledTransfer.Recurrence == "" {
+		scheduledTransfer.Status = status
+		return
+	}
+
+	schedule, err := cron.Parse(scheduledTransfer.Recurrence)
+	if err != nil {
+		s.logger.Errorf("scheduledTransferUseCase/advance/Parse :%v", err)
+		scheduledTransfer.Status = status
+		return
+	}
+
+	next, err := schedule.Next(now)
+	if err != nil {
+		s.logger.Errorf("scheduledTransferUseCase/advance/Next :%v", err)
+		scheduledTransfer.Status = status
+		return
+	}
+
+	scheduledTransfer.NextRunAt = next
+}
+
+func (s scheduledTransferUseCase) retryDelay(attempts int) time.Duration {
+	delay := s.policy.RetryBaseDelay
+	for i := 1; i < attempts && delay < s.policy.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > s.policy.RetryMaxDelay {
+		delay = s.policy.RetryMaxDelay
+	}
+
+	return delay
+}
+
+// plan validates param and returns the scheduled transfer it describes, due
+// at RunAt or else at the next match of the recurrence.
+func (s scheduledTransferUseCase) plan(ctx context.Context, accountNumber int, param domain.ScheduledTransferParam, now time.Time) (domain.ScheduledTransfer, error) {
+	toAccountNumber, err := strconv.Atoi(param.ToAccountNumber)
+	if err != nil {
+		s.logger.Errorf("scheduledTransferUseCase/plan/parseToAccountNumber :%v", err)
+		return domain.ScheduledTransfer{}, domain.ErrBadRequest
+	}
+
+	if accountNumber == toAccountNumber {
+		return domain.ScheduledTransfer{}, domain.ErrSameAccountTransfer
+	}
+
+	err = param.Amount.Validate()
+	if err != nil {
+		return domain.ScheduledTransfer{}, err
+	}
+
+	if !param.Amount.IsPositive() {
+		return domain.ScheduledTransfer{}, domain.ErrInvalidAmount
+	}
+
+	nextRunAt := param.RunAt.UTC()
+	if param.Recurrence != "" {
+		schedule, err := cron.Parse(param.Recurrence)
+		if err != nil {
+			s.logger.Errorf("scheduledTransferUseCase/plan/Parse :%v", err)
+			return domain.ScheduledTransfer{}, domain.ErrInvalidRecurrence
+		}
+
+		next, err := schedule.Next(now)
+		if err != nil {
+			s.logger.Errorf("scheduledTransferUseCase/plan/Next :%v", err)
+			return domain.ScheduledTransfer{}, domain.ErrInvalidRecurrence
+		}
+
+		if param.RunAt.IsZero() {
+			nextRunAt = next
+		}
+	}
+
+	if !nextRunAt.After(now) {
+		return domain.ScheduledTransfer{}, domain.ErrInvalidRunAt
+	}
+
+	senderAccount, err := s.accountRepository.GetByAccountNumber(ctx, accountNumber)
+	if err != nil {
+		s.logger.Errorf("scheduledTransferUseCase/plan/senderAccount/GetByAccountNumber :%v", err)
+		if errors.Cause(err) == sql.ErrNoRows {
+			return domain.ScheduledTransfer{}, domain.ErrSenderAccountNotFound
+		}
+		return domain.ScheduledTransfer{}, err
+	}
+
+	if senderAccount.Balance.Currency != param.Amount.Currency {
+		return domain.ScheduledTransfer{}, domain.ErrCurrencyMismatch
+	}
+
+	_, err = s.accountRepository.GetByAccountNumber(ctx, toAccountNumber)
+	if err != nil {
+		s.logger.Errorf("scheduledTransferUseCase/plan/receiverAccount/GetByAccountNumber :%v", err)
+		if errors.Cause(err) == sql.ErrNoRows {
+			return domain.ScheduledTransfer{}, domain.ErrReceiverAccountNotFound
+		}
+		return domain.ScheduledTransfer{}, err
+	}
+
+	err = s.mfaUseCase.VerifyStepUp(ctx, accountNumber, param.Amount, param.OTP)
+	if err != nil {
+		s.logger.Errorf("scheduledTransferUseCase/plan/VerifyStepUp :%v", err)
+		return domain.ScheduledTransfer{}, err
+	}
+
+	return domain.ScheduledTransfer{
+		FromAccountNumber: accountNumber,
+		ToAccountNumber:   toAccountNumber,
+		Amount:            param.Amount,
+		Description:       param.Description,
+		Recurrence:        param.Recurrence,
+		NextRunAt:         nextRunAt,
+	}, nil
+}
+
+func closed(scheduledTransfer domain.ScheduledTransfer) bool {
+	return scheduledTransfer.Status == domain.ScheduledTransferStatusCompleted ||
+		scheduledTransfer.Status == domain.ScheduledTransferStatusCancelled
+}
+
+func NewScheduledTransferUseCase(sr domain.ScheduledTransferRepository, a domain.AccountRepository, au domain.AccountUseCase, m domain.MFAUseCase, t domain.TransactionManager, policy domain.SchedulerPolicy, log *logrus.Logger) domain.ScheduledTransferUseCase {
+	return &scheduledTransferUseCase{
+		scheduledTransferRepository: sr,
+		accountRepository:           a,
+		accountUseCase:              au,
+		mfaUseCase:                  m,
+		transactionManager:          t,
+		policy:                      policy,
+		logger:                      log,
+	}
+}
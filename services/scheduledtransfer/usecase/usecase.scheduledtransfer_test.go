@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	database_mock "github.com/oniharnantyo/golang-backend-example/database/mock"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	repository_account_mock "github.com/oniharnantyo/golang-backend-example/services/account/repository/mock"
+	account_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/account/usecase/mock"
+	mfa_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/mfa/usecase/mock"
+	repository_scheduledtransfer_mock "github.com/oniharnantyo/golang-backend-example/services/scheduledtransfer/repository/mock"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var schedulerPolicy = domain.SchedulerPolicy{
+	Interval:       time.Minute,
+	BatchSize:      10,
+	MaxAttempts:    3,
+	RetryBaseDelay: 5 * time.Minute,
+	RetryMaxDelay:  time.Hour,
+}
+
+var (
+	sender   = domain.Account{AccountNumber: 555001, Balance: domain.NewMoney(100000, "IDR")}
+	receiver = domain.Account{AccountNumber: 555002, Balance: domain.NewMoney(0, "IDR")}
+)
+
+func stepUpVerified() interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return domain.StepUpVerified(ctx)
+	})
+}
+
+func TestScheduledTransferUseCase_Store(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("One-off", func(t *testing.T) {
+		mockScheduledTransferRepo := new(repository_scheduledtransfer_mock.ScheduledTransferMockRepository)
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockMFAUseCase := new(mfa_usecase_mock.MFAMockUseCase)
+
+		runAt := time.Now().Add(24 * time.Hour)
+
+		inTransaction := false
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			inTransaction = true
+		}).Once()
+
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(sender, nil).Once()
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555002).Return(receiver, nil).Once()
+		// The code is used up in the transaction of the store
+		mockMFAUseCase.On("VerifyStepUp", mock.Anything, 555001, domain.NewMoney(5000, "IDR"), "123456").Return(nil).Run(func(args mock.Arguments) {
+			assert.True(t, inTransaction)
+		}).Once()
+		mockScheduledTransferRepo.On("Store", mock.Anything, mock.MatchedBy(func(s *domain.ScheduledTransfer) bool {
+			return s.FromAccountNumber == 555001 && s.ToAccountNumber == 555002 && s.NextRunAt.Equal(runAt) &&
+				s.Status == domain.ScheduledTransferStatusActive && s.Recurrence == ""
+		})).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.ScheduledTransfer).ID = 3
+		}).Once()
+
+		s := NewScheduledTransferUseCase(mockScheduledTransferRepo, mockAccountRepo, new(account_usecase_mock.AccountMockUseCase), mockMFAUseCase, mockTransaction, schedulerPolicy, logger)
+
+		scheduledTransfer, err := s.Store(context.Background(), 555001, domain.ScheduledTransferParam{
+			ToAccountNumber: "555002",
+			Amount:          domain.NewMoney(5000, "IDR"),
+			RunAt:           runAt,
+			OTP:             "123456",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), scheduledTransfer.ID)
+
+		mockScheduledTransferRepo.AssertExpectations(t)
+		mockAccountRepo.AssertExpectations(t)
+		mockMFAUseCase.AssertExpectations(t)
+		mockTransaction.AssertExpectations(t)
+	})
+
+	t.Run("Recurring", func(t *testing.T) {
+		mockScheduledTransferRepo := new(repository_scheduledtransfer_mock.ScheduledTransferMockRepository)
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockMFAUseCase := new(mfa_usecase_mock.MFAMockUseCase)
+
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(sender, nil).Once()
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555002).Return(receiver, nil).Once()
+		mockMFAUseCase.On("VerifyStepUp", mock.Anything, 555001, domain.NewMoney(5000, "IDR"), "").Return(nil).Once()
+		mockScheduledTransferRepo.On("Store", mock.Anything, mock.MatchedBy(func(s *domain.ScheduledTransfer) bool {
+			// First of the next month at 09:00 UTC
+			return s.NextRunAt.Day() == 1 && s.NextRunAt.Hour() == 9 && s.NextRunAt.Minute() == 0 &&
+				s.NextRunAt.Location() == time.UTC && s.NextRunAt.After(time.Now())
+		})).Return(nil).Once()
+
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+
+		s := NewScheduledTransferUseCase(mockScheduledTransferRepo, mockAccountRepo, new(account_usecase_mock.AccountMockUseCase), mockMFAUseCase, mockTransaction, schedulerPolicy, logger)
+
+		_, err := s.Store(context.Background(), 555001, domain.ScheduledTransferParam{
+			ToAccountNumber: "555002",
+			Amount:          domain.NewMoney(5000, "IDR"),
+			Recurrence:      "0 9 1 * *",
+		})
+		assert.NoError(t, err)
+
+		mockScheduledTransferRepo.AssertExpectations(t)
+	})
+
+	cases := []struct {
+		name  string
+		param domain.ScheduledTransferParam
+		err   error
+	}{
+		{"Same-account", domain.ScheduledTransferParam{ToAccountNumber: "555001", Amount: domain.NewMoney(5000, "IDR"), RunAt: time.Now().Add(time.Hour)}, domain.ErrSameAccountTransfer},
+		{"Zero-amount", domain.ScheduledTransferParam{ToAccountNumber: "555002", Amount: domain.NewMoney(0, "IDR"), RunAt: time.Now().Add(time.Hour)}, domain.ErrInvalidAmount},
+		{"Invalid-recurrence", domain.ScheduledTransferParam{ToAccountNumber: "555002", Amount: domain.NewMoney(5000, "IDR"), Recurrence: "every monday"}, domain.ErrInvalidRecurrence},
+		{"Never-matches", domain.ScheduledTransferParam{ToAccountNumber: "555002", Amount: domain.NewMoney(5000, "IDR"), Recurrence: "0 0 30 2 *"}, domain.ErrInvalidRecurrence},
+		{"Past-run-at", domain.ScheduledTransferParam{ToAccountNumber: "555002", Amount: domain.NewMoney(5000, "IDR"), RunAt: time.Now().Add(-time.Hour)}, domain.ErrInvalidRunAt},
+		{"No-run-at", domain.ScheduledTransferParam{ToAccountNumber: "555002", Amount: domain.NewMoney(5000, "IDR")}, domain.ErrInvalidRunAt},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockScheduledTransferRepo := new(repository_scheduledtransfer_mock.ScheduledTransferMockRepository)
+			mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+
+			mockTransaction := new(database_mock.TransactionMockManager)
+			mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+
+			s := NewScheduledTransferUseCase(mockScheduledTransferRepo, mockAccountRepo, new(account_usecase_mock.AccountMockUseCase), new(mfa_usecase_mock.MFAMockUseCase), mockTransaction, schedulerPolicy, logger)
+
+			_, err := s.Store(context.Background(), 555001, c.param)
+			assert.Equal(t, c.err, err)
+
+			mockScheduledTransferRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("Receiver-not-found", func(t *testing.T) {
+		mockScheduledTransferRepo := new(repository_scheduledtransfer_mock.ScheduledTransferMockRepository)
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(sender, nil).Once()
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555002).Return(domain.Account{}, sql.ErrNoRows).Once()
+
+		mockTransaction := new(database_mock.TransactionMockManager)
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil).Once()
+
+		s := NewScheduledTransferUseCase(mockScheduledTransferRepo, mockAccountRepo, new(account_usecase_mock.AccountMockUseCase), new(mfa_usecase_mock.MFAMockUseCase), mockTransaction, schedulerPolicy, logger)
+
+		_, err := s.Store(context.Background(), 555001, domain.ScheduledTransferParam{
+			ToAccountNumber: "555002",
+			Amount:          domain.NewMoney(5000, "IDR"),
+			RunAt:           time.Now().Add(time.Hour),
+		})
+		assert.Equal(t, domain.ErrReceiverAccountNotFound, err)
+
+		mockScheduledTransferRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+	})
+}
+
+func TestScheduledTransferUseCase_Cancel(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name    string
+		current domain.ScheduledTransfer
+		getErr  error
+		err     error
+	}{
+		{"Success", domain.ScheduledTransfer{ID: 3, FromAccountNumber: 555001, Status: domain.ScheduledTransferStatusFailed}, nil, nil},
+		{"Completed", domain.ScheduledTransfer{ID: 3, FromAccountNumber: 555001, Status: domain.ScheduledTransferStatusCompleted}, nil, domain.ErrScheduledTransferClosed},
+		{"Not-found", domain.ScheduledTransfer{}, sql.ErrNoRows, domain.ErrScheduledTransferNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockScheduledTransferRepo := new(repository_scheduledtransfer_mock.ScheduledTransferMockRepository)
+
+			mockScheduledTransferRepo.On("Get", mock.Anything, 555001, int64(3)).Return(c.current, c.getErr).Once()
+			mockScheduledTransferRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.ScheduledTransfer) bool {
+				return s.ID == 3 && s.Status == domain.ScheduledTransferStatusCancelled
+			})).Return(nil).Maybe()
+
+			s := NewScheduledTransferUseCase(mockScheduledTransferRepo, new(repository_account_mock.AccountMockRepository), new(account_usecase_mock.AccountMockUseCase), new(mfa_usecase_mock.MFAMockUseCase), new(database_mock.TransactionMockManager), schedulerPolicy, logger)
+
+			err := s.Cancel(context.Background(), 555001, 3)
+			assert.Equal(t, c.err, err)
+
+			if c.err == nil {
+				mockScheduledTransferRepo.AssertCalled(t, "Update", mock.Anything, mock.Anything)
+			} else {
+				mockScheduledTransferRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestScheduledTransferUseCase_RunDue(t *testing.T) {
+	logger := logrus.New()
+
+	dueAt := time.Now().UTC().Add(-time.Minute)
+	oneOff := domain.ScheduledTransfer{
+		ID:                3,
+		FromAccountNumber: 555001,
+		ToAccountNumber:   555002,
+		Amount:            domain.NewMoney(5000, "IDR"),
+		NextRunAt:         dueAt,
+		Status:            domain.ScheduledTransferStatusActive,
+	}
+	recurring := oneOff
+	recurring.ID = 4
+	recurring.Recurrence = "0 9 1 * *"
+
+	transferParam := domain.TransferParam{ToAccountNumber: "555002", Amount: domain.NewMoney(5000, "IDR")}
+
+	t.Run("Success", func(t *testing.T) {
+		mockScheduledTransferRepo := new(repository_scheduledtransfer_mock.ScheduledTransferMockRepository)
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil)
+		mockScheduledTransferRepo.On("ClaimDue", mock.Anything, mock.Anything).Return(oneOff, nil).Once()
+		mockScheduledTransferRepo.On("ClaimDue", mock.Anything, mock.Anything).Return(recurring, nil).Once()
+		mockScheduledTransferRepo.On("ClaimDue", mock.Anything, mock.Anything).Return(domain.ScheduledTransfer{}, sql.ErrNoRows).Once()
+		mockAccountUseCase.On("Transfer", stepUpVerified(), 555001, transferParam).Return(domain.Transfer{ID: "c0d1f1a2-8a4b-4b8e-9d55-2f9c4a1e7b01"}, nil).Twice()
+		mockScheduledTransferRepo.On("UpdateRun", mock.Anything, mock.MatchedBy(func(s *domain.ScheduledTransfer) bool {
+			return s.ID == 3 && s.Status == domain.ScheduledTransferStatusCompleted && s.LastTransferID != ""
+		}), dueAt).Return(nil).Once()
+		mockScheduledTransferRepo.On("UpdateRun", mock.Anything, mock.MatchedBy(func(s *domain.ScheduledTransfer) bool {
+			return s.ID == 4 && s.Status == domain.ScheduledTransferStatusActive && s.NextRunAt.Day() == 1 && s.NextRunAt.Hour() == 9
+		}), dueAt).Return(nil).Once()
+
+		s := NewScheduledTransferUseCase(mockScheduledTransferRepo, new(repository_account_mock.AccountMockRepository), mockAccountUseCase, new(mfa_usecase_mock.MFAMockUseCase), mockTransaction, schedulerPolicy, logger)
+
+		ran, err := s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, ran)
+
+		mockScheduledTransferRepo.AssertExpectations(t)
+		mockAccountUseCase.AssertExpectations(t)
+	})
+
+	cases := []struct {
+		name      string
+		claimed   domain.ScheduledTransfer
+		attempts  int
+		status    string
+		nextRunAt func(next time.Time) bool
+	}{
+		{"Retry", oneOff, 1, domain.ScheduledTransferStatusActive, func(next time.Time) bool {
+			return next.Sub(time.Now()) > 4*time.Minute && next.Sub(time.Now()) <= 5*time.Minute
+		}},
+		{"Retry-backoff", withAttempts(oneOff, 1), 2, domain.ScheduledTransferStatusActive, func(next time.Time) bool {
+			return next.Sub(time.Now()) > 9*time.Minute && next.Sub(time.Now()) <= 10*time.Minute
+		}},
+		{"One-off-exhausted", withAttempts(oneOff, 2), 3, domain.ScheduledTransferStatusFailed, func(next time.Time) bool {
+			return next.Equal(dueAt)
+		}},
+		{"Recurring-exhausted", withAttempts(recurring, 2), 0, domain.ScheduledTransferStatusActive, func(next time.Time) bool {
+			return next.Day() == 1 && next.Hour() == 9
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockScheduledTransferRepo := new(repository_scheduledtransfer_mock.ScheduledTransferMockRepository)
+			mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+			mockTransaction := new(database_mock.TransactionMockManager)
+
+			mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil)
+			mockScheduledTransferRepo.On("ClaimDue", mock.Anything, mock.Anything).Return(c.claimed, nil).Once()
+			mockScheduledTransferRepo.On("ClaimDue", mock.Anything, mock.Anything).Return(domain.ScheduledTransfer{}, sql.ErrNoRows).Once()
+			mockAccountUseCase.On("Transfer", stepUpVerified(), 555001, transferParam).Return(domain.Transfer{}, domain.ErrInsufficientBalance).Once()
+			mockScheduledTransferRepo.On("UpdateRun", mock.Anything, mock.MatchedBy(func(s *domain.ScheduledTransfer) bool {
+				return s.ID == c.claimed.ID && s.Attempts == c.attempts && s.Status == c.status &&
+					s.LastError == domain.ErrInsufficientBalance.Code && c.nextRunAt(s.NextRunAt)
+			}), dueAt).Return(nil).Once()
+
+			s := NewScheduledTransferUseCase(mockScheduledTransferRepo, new(repository_account_mock.AccountMockRepository), mockAccountUseCase, new(mfa_usecase_mock.MFAMockUseCase), mockTransaction, schedulerPolicy, logger)
+
+			ran, err := s.RunDue(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 1, ran)
+
+			mockScheduledTransferRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Moved-on", func(t *testing.T) {
+		mockScheduledTransferRepo := new(repository_scheduledtransfer_mock.ScheduledTransferMockRepository)
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil)
+		mockScheduledTransferRepo.On("ClaimDue", mock.Anything, mock.Anything).Return(oneOff, nil).Once()
+		mockScheduledTransferRepo.On("ClaimDue", mock.Anything, mock.Anything).Return(domain.ScheduledTransfer{}, sql.ErrNoRows).Once()
+		mockAccountUseCase.On("Transfer", stepUpVerified(), 555001, transferParam).Return(domain.Transfer{}, domain.ErrInsufficientBalance).Once()
+		mockScheduledTransferRepo.On("UpdateRun", mock.Anything, mock.Anything, dueAt).Return(sql.ErrNoRows).Once()
+
+		s := NewScheduledTransferUseCase(mockScheduledTransferRepo, new(repository_account_mock.AccountMockRepository), mockAccountUseCase, new(mfa_usecase_mock.MFAMockUseCase), mockTransaction, schedulerPolicy, logger)
+
+		ran, err := s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, ran)
+	})
+
+	t.Run("Batch-size", func(t *testing.T) {
+		mockScheduledTransferRepo := new(repository_scheduledtransfer_mock.ScheduledTransferMockRepository)
+		mockAccountUseCase := new(account_usecase_mock.AccountMockUseCase)
+		mockTransaction := new(database_mock.TransactionMockManager)
+
+		mockTransaction.On("WithinTransaction", mock.Anything, mock.Anything).Return(nil)
+		mockScheduledTransferRepo.On("ClaimDue", mock.Anything, mock.Anything).Return(oneOff, nil).Times(2)
+		mockAccountUseCase.On("Transfer", stepUpVerified(), 555001, transferParam).Return(domain.Transfer{ID: "c0d1f1a2-8a4b-4b8e-9d55-2f9c4a1e7b01"}, nil).Times(2)
+		mockScheduledTransferRepo.On("UpdateRun", mock.Anything, mock.Anything, dueAt).Return(nil).Times(2)
+
+		policy := schedulerPolicy
+		policy.BatchSize = 2
+
+		s := NewScheduledTransferUseCase(mockScheduledTransferRepo, new(repository_account_mock.AccountMockRepository), mockAccountUseCase, new(mfa_usecase_mock.MFAMockUseCase), mockTransaction, policy, logger)
+
+		ran, err := s.RunDue(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, ran)
+
+		mockScheduledTransferRepo.AssertExpectations(t)
+	})
+}
+
+func withAttempts(s domain.ScheduledTransfer, attempts int) domain.ScheduledTransfer {
+	s.Attempts = attempts
+	return s
+}
@@ -0,0 +1,45 @@
+package repository_transfer_mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type TransferMockRepository struct {
+	mock.Mock
+}
+
+func (t *TransferMockRepository) Store(ctx context.Context, a *domain.Transfer) error {
+	args := t.Called(ctx, a)
+
+	return args.Error(0)
+}
+
+func (t *TransferMockRepository) ListByAccountNumber(ctx context.Context, accountNumber int, param domain.TransferListParam) ([]domain.Transfer, error) {
+	args := t.Called(ctx, accountNumber, param)
+	result := args.Get(0)
+
+	return result.([]domain.Transfer), args.Error(1)
+}
+
+func (t *TransferMockRepository) CountByAccountNumber(ctx context.Context, accountNumber int, param domain.TransferListParam) (int, error) {
+	args := t.Called(ctx, accountNumber, param)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (t *TransferMockRepository) OutgoingTotal(ctx context.Context, accountNumber int, since time.Time) (int64, error) {
+	args := t.Called(ctx, accountNumber, since)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (t *TransferMockRepository) OutgoingCount(ctx context.Context, accountNumber int, since time.Time) (int, error) {
+	args := t.Called(ctx, accountNumber, since)
+
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,261 @@
+package repository_transfer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/database"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/util"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the postgres error code of a unique constraint violation.
+const uniqueViolation = "23505"
+
+type transferRepository struct {
+	dbPool *sql.DB
+}
+
+func (t transferRepository) Store(ctx context.Context, a *domain.Transfer) error {
+	stmt, err := database.Conn(ctx, t.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		INSERT INTO transfer (
+			id,
+			type,
+			from_account_number,
+			to_account_number,
+			amount,
+			currency,
+			converted_amount,
+			converted_currency,
+			fx_rate,
+			status,
+			description,
+			reference,
+			channel,
+			reason_code,
+			created_at,
+			updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')::NUMERIC, $10, NULLIF($11, ''),
+			NULLIF($12, ''), NULLIF($13, ''), NULLIF($14, ''), $15, $16
+		)`))
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx,
+		a.ID,
+		a.Type,
+		a.FromAccountNumber,
+		a.ToAccountNumber,
+		a.Amount.Amount,
+		a.Amount.Currency,
+		a.ConvertedAmount.Amount,
+		a.ConvertedAmount.Currency,
+		a.FXRate,
+		a.Status,
+		a.Description,
+		a.Reference,
+		a.Channel,
+		a.ReasonCode,
+		a.CreatedAt,
+		a.UpdatedAt,
+	)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation && pqErr.Constraint == "transfer_channel_reference_unique" {
+		return domain.ErrDuplicateReference
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// listQuery has the conditions ListByAccountNumber and CountByAccountNumber
+// share.
+func listQuery(accountNumber int, param domain.TransferListParam) *util.Query {
+	query := util.NewQuery()
+
+	account := query.Bind(accountNumber)
+	query.Where(fmt.Sprintf(`(from_account_number = %s OR to_account_number = %s)`, account, account))
+
+	if !param.StartDate.IsZero() {
+		query.Where(`created_at >= ?`, param.StartDate)
+	}
+
+	if !param.EndDate.IsZero() {
+		query.Where(`created_at < ?`, param.EndDate.AddDate(0, 0, 1))
+	}
+
+	return query
+}
+
+// cursorValues returns the values of the sort columns in after.
+func cursorValues(after *domain.TransferCursor, sort []util.SortField) []interface{} {
+	values := make([]interface{}, len(sort))
+	for i, field := range sort {
+		switch field.Column {
+		case "created_at":
+			values[i] = after.CreatedAt
+		case "amount":
+			values[i] = after.Amount
+		case "id":
+			values[i] = after.ID
+		}
+	}
+
+	return values
+}
+
+// ListByAccountNumber pages by offset, or by keyset on the sort columns after
+// param.After.
+func (t transferRepository) ListByAccountNumber(ctx context.Context, accountNumber int, param domain.TransferListParam) ([]domain.Transfer, error) {
+	sort, err := domain.TransferSort.Parse(param.Sort, param.Order)
+	if err != nil {
+		return nil, domain.ErrInvalidSort
+	}
+
+	query := listQuery(accountNumber, param)
+
+	if param.After != nil {
+		query.After(sort, cursorValues(param.After, sort)...)
+	}
+
+	query.OrderBy(sort).Page(param.Limit, param.Offset)
+
+	stmt, err := database.Conn(ctx, t.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			id,
+			type,
+			from_account_number,
+			to_account_number,
+			amount,
+			currency,
+			converted_amount,
+			converted_currency,
+			COALESCE(fx_rate::TEXT, ''),
+			status,
+			COALESCE(description, ''),
+			COALESCE(reference, ''),
+			COALESCE(channel, ''),
+			COALESCE(reason_code, ''),
+			created_at,
+			updated_at
+		FROM transfer
+			%s
+	`, query))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, query.Args()...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var transfers []domain.Transfer
+	for rows.Next() {
+		var transfer domain.Transfer
+		err := rows.Scan(
+			&transfer.ID,
+			&transfer.Type,
+			&transfer.FromAccountNumber,
+			&transfer.ToAccountNumber,
+			&transfer.Amount.Amount,
+			&transfer.Amount.Currency,
+			&transfer.ConvertedAmount.Amount,
+			&transfer.ConvertedAmount.Currency,
+			&transfer.FXRate,
+			&transfer.Status,
+			&transfer.Description,
+			&transfer.Reference,
+			&transfer.Channel,
+			&transfer.ReasonCode,
+			&transfer.CreatedAt,
+			&transfer.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+func (t transferRepository) CountByAccountNumber(ctx context.Context, accountNumber int, param domain.TransferListParam) (int, error) {
+	query := listQuery(accountNumber, param)
+
+	stmt, err := database.Conn(ctx, t.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			COUNT(*)
+		FROM transfer
+			%s
+	`, query))
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	err = stmt.QueryRowContext(ctx, query.Args()...).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (t transferRepository) OutgoingTotal(ctx context.Context, accountNumber int, since time.Time) (int64, error) {
+	stmt, err := database.Conn(ctx, t.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			COALESCE(SUM(amount), 0)
+		FROM transfer
+		WHERE
+			from_account_number = $1 AND type IN ('transfer', 'withdrawal') AND created_at >= $2
+	`))
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	err = stmt.QueryRowContext(ctx, accountNumber, since).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (t transferRepository) OutgoingCount(ctx context.Context, accountNumber int, since time.Time) (int, error) {
+	stmt, err := database.Conn(ctx, t.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			COUNT(*)
+		FROM transfer
+		WHERE
+			from_account_number = $1 AND type = 'transfer' AND created_at >= $2
+	`))
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = stmt.QueryRowContext(ctx, accountNumber, since).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func NewTransferRepository(db *sql.DB) domain.TransferRepository {
+	return &transferRepository{
+		dbPool: db,
+	}
+}
@@ -0,0 +1,384 @@
+package repository_transfer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/util"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func initMock() (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		log.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	return db, mock
+}
+
+func TestTransferRepository_Store(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		INSERT INTO transfer (
+			id,
+			type,
+			from_account_number,
+			to_account_number,
+			amount,
+			currency,
+			converted_amount,
+			converted_currency,
+			fx_rate,
+			status,
+			description,
+			reference,
+			channel,
+			reason_code,
+			created_at,
+			updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')::NUMERIC, $10, NULLIF($11, ''),
+			NULLIF($12, ''), NULLIF($13, ''), NULLIF($14, ''), $15, $16
+		)`)
+
+	now := time.Now()
+	transfer := domain.Transfer{
+		ID:                "6f1c1a4e-3f0a-4d4b-8f59-1f1d2b7c8a90",
+		Type:              domain.TransferTypeTransfer,
+		FromAccountNumber: 555001,
+		ToAccountNumber:   555002,
+		Amount:            domain.NewMoney(1000, "USD"),
+		ConvertedAmount:   domain.NewMoney(15500000, "IDR"),
+		FXRate:            "15500",
+		Status:            domain.TransferStatusCompleted,
+		Description:       "rent",
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(transfer.ID, transfer.Type, transfer.FromAccountNumber, transfer.ToAccountNumber, transfer.Amount.Amount,
+		transfer.Amount.Currency, transfer.ConvertedAmount.Amount, transfer.ConvertedAmount.Currency, transfer.FXRate, transfer.Status, transfer.Description,
+		transfer.Reference, transfer.Channel, transfer.ReasonCode, now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	r := NewTransferRepository(db)
+
+	err := r.Store(context.Background(), &transfer)
+	assert.NoError(t, err)
+
+	t.Run("Duplicate-reference", func(t *testing.T) {
+		deposit := domain.Transfer{
+			ID:                "0b8e5a7c-64a4-4ad2-9a0e-5c1f3e2b7d11",
+			Type:              domain.TransferTypeDeposit,
+			FromAccountNumber: domain.LedgerExternalAccountNumber,
+			ToAccountNumber:   555001,
+			Amount:            domain.NewMoney(1000, "IDR"),
+			ConvertedAmount:   domain.NewMoney(1000, "IDR"),
+			Status:            domain.TransferStatusCompleted,
+			Reference:         "BR-0001",
+			Channel:           domain.ChannelBranch,
+			ReasonCode:        "CASH",
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+
+		prep := mock.ExpectPrepare(query)
+		prep.ExpectExec().WithArgs(deposit.ID, deposit.Type, deposit.FromAccountNumber, deposit.ToAccountNumber, deposit.Amount.Amount,
+			deposit.Amount.Currency, deposit.ConvertedAmount.Amount, deposit.ConvertedAmount.Currency, deposit.FXRate, deposit.Status, deposit.Description,
+			deposit.Reference, deposit.Channel, deposit.ReasonCode, now, now).
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "transfer_channel_reference_unique"})
+
+		err := r.Store(context.Background(), &deposit)
+		assert.Equal(t, domain.ErrDuplicateReference, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransferRepository_ListByAccountNumber(t *testing.T) {
+	now := time.Now()
+	columns := []string{"id", "type", "from_account_number", "to_account_number", "amount", "currency", "converted_amount", "converted_currency", "fx_rate", "status", "description", "reference", "channel", "reason_code", "created_at", "updated_at"}
+
+	t.Run("Without-date-range", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		rows := sqlmock.NewRows(columns).
+			AddRow("6f1c1a4e-3f0a-4d4b-8f59-1f1d2b7c8a90", "transfer", 555001, 555002, 1000, "IDR", 1000, "IDR", "", "completed", "", "", "", "", now, now)
+
+		query := fmt.Sprintf(`
+			SELECT
+				id,
+				type,
+				from_account_number,
+				to_account_number,
+				amount,
+				currency,
+				converted_amount,
+				converted_currency,
+				COALESCE(fx_rate::TEXT, ''),
+				status,
+				COALESCE(description, ''),
+				COALESCE(reference, ''),
+				COALESCE(channel, ''),
+				COALESCE(reason_code, ''),
+				created_at,
+				updated_at
+			FROM transfer
+			WHERE (from_account_number = $1 OR to_account_number = $1)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2 OFFSET $3`)
+
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(555001, 10, 0).WillReturnRows(rows)
+
+		r := NewTransferRepository(db)
+
+		transfers, err := r.ListByAccountNumber(context.Background(), 555001, domain.TransferListParam{
+			Filter: util.Filter{
+				Limit:  10,
+				Offset: 0,
+			},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, transfers, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("With-date-range", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		rows := sqlmock.NewRows(columns).
+			AddRow("6f1c1a4e-3f0a-4d4b-8f59-1f1d2b7c8a90", "transfer", 555001, 555002, 1000, "IDR", 1000, "IDR", "", "completed", "rent", "", "", "", now, now)
+
+		query := fmt.Sprintf(`
+			SELECT
+				id,
+				type,
+				from_account_number,
+				to_account_number,
+				amount,
+				currency,
+				converted_amount,
+				converted_currency,
+				COALESCE(fx_rate::TEXT, ''),
+				status,
+				COALESCE(description, ''),
+				COALESCE(reference, ''),
+				COALESCE(channel, ''),
+				COALESCE(reason_code, ''),
+				created_at,
+				updated_at
+			FROM transfer
+			WHERE (from_account_number = $1 OR to_account_number = $1) AND created_at >= $2 AND created_at < $3
+			ORDER BY created_at ASC, id ASC
+			LIMIT $4 OFFSET $5`)
+
+		startDate := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+		endDate := time.Date(2021, 4, 30, 0, 0, 0, 0, time.UTC)
+
+		mock.ExpectPrepare(query).ExpectQuery().
+			WithArgs(555001, startDate, time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC), 10, 0).
+			WillReturnRows(rows)
+
+		r := NewTransferRepository(db)
+
+		transfers, err := r.ListByAccountNumber(context.Background(), 555001, domain.TransferListParam{
+			Filter: util.Filter{
+				Limit:  10,
+				Offset: 0,
+				Order:  "asc",
+			},
+			StartDate: startDate,
+			EndDate:   endDate,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, transfers, 1)
+		assert.Equal(t, "rent", transfers[0].Description)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("After-cursor", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		rows := sqlmock.NewRows(columns).
+			AddRow("6f1c1a4e-3f0a-4d4b-8f59-1f1d2b7c8a90", "transfer", 555001, 555002, 1000, "IDR", 1000, "IDR", "", "completed", "", "", "", "", now, now)
+
+		query := fmt.Sprintf(`
+			SELECT
+				id,
+				type,
+				from_account_number,
+				to_account_number,
+				amount,
+				currency,
+				converted_amount,
+				converted_currency,
+				COALESCE(fx_rate::TEXT, ''),
+				status,
+				COALESCE(description, ''),
+				COALESCE(reference, ''),
+				COALESCE(channel, ''),
+				COALESCE(reason_code, ''),
+				created_at,
+				updated_at
+			FROM transfer
+			WHERE (from_account_number = $1 OR to_account_number = $1) AND (created_at, id) < ($2, $3)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $4 OFFSET $5`)
+
+		after := domain.TransferCursor{CreatedAt: now, ID: "7a2d2b5f-4a1b-4e5c-9a60-2e2e3c8d9ba1"}
+
+		mock.ExpectPrepare(query).ExpectQuery().
+			WithArgs(555001, after.CreatedAt, after.ID, 10, 0).
+			WillReturnRows(rows)
+
+		r := NewTransferRepository(db)
+
+		transfers, err := r.ListByAccountNumber(context.Background(), 555001, domain.TransferListParam{
+			Filter: util.Filter{Limit: 10},
+			After:  &after,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, transfers, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTransferRepository_ListByAccountNumberSortedByAmount(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "type", "from_account_number", "to_account_number", "amount", "currency", "converted_amount", "converted_currency", "fx_rate", "status", "description", "reference", "channel", "reason_code", "created_at", "updated_at"}).
+		AddRow("6f1c1a4e-3f0a-4d4b-8f59-1f1d2b7c8a90", "transfer", 555001, 555002, 1000, "IDR", 1000, "IDR", "", "completed", "", "", "", "", now, now)
+
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			type,
+			from_account_number,
+			to_account_number,
+			amount,
+			currency,
+			converted_amount,
+			converted_currency,
+			COALESCE(fx_rate::TEXT, ''),
+			status,
+			COALESCE(description, ''),
+			COALESCE(reference, ''),
+			COALESCE(channel, ''),
+			COALESCE(reason_code, ''),
+			created_at,
+			updated_at
+		FROM transfer
+		WHERE (from_account_number = $1 OR to_account_number = $1) AND (amount, id) < ($2, $3)
+		ORDER BY amount DESC, id DESC
+		LIMIT $4 OFFSET $5`)
+
+	mock.ExpectPrepare(query).ExpectQuery().
+		WithArgs(555001, 2000, "7a2d2b5f-4a1b-4e5c-9a60-2e2e3c8d9ba1", 10, 0).
+		WillReturnRows(rows)
+
+	r := NewTransferRepository(db)
+
+	transfers, err := r.ListByAccountNumber(context.Background(), 555001, domain.TransferListParam{
+		Filter: util.Filter{Limit: 10, Sort: "-amount"},
+		After:  &domain.TransferCursor{Amount: 2000, ID: "7a2d2b5f-4a1b-4e5c-9a60-2e2e3c8d9ba1"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransferRepository_CountByAccountNumber(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*)
+		FROM transfer
+		WHERE (from_account_number = $1 OR to_account_number = $1)`)
+
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs(555001).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	r := NewTransferRepository(db)
+
+	total, err := r.CountByAccountNumber(context.Background(), 555001, domain.TransferListParam{
+		Filter: util.Filter{Limit: 10},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransferRepository_OutgoingTotal(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		SELECT
+			COALESCE(SUM(amount), 0)
+		FROM transfer
+		WHERE
+			from_account_number = $1 AND type IN ('transfer', 'withdrawal') AND created_at >= $2
+	`)
+
+	since := time.Date(2021, 4, 20, 0, 0, 0, 0, time.UTC)
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs(555001, since).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(15000))
+
+	r := NewTransferRepository(db)
+
+	total, err := r.OutgoingTotal(context.Background(), 555001, since)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15000), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransferRepository_OutgoingCount(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*)
+		FROM transfer
+		WHERE
+			from_account_number = $1 AND type = 'transfer' AND created_at >= $2
+	`)
+
+	since := time.Date(2021, 4, 20, 9, 0, 0, 0, time.UTC)
+	mock.ExpectPrepare(query).ExpectQuery().WithArgs(555001, since).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+	r := NewTransferRepository(db)
+
+	count, err := r.OutgoingCount(context.Background(), 555001, since)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
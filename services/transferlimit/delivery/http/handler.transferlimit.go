@@ -0,0 +1,94 @@
+package delivery_http_transferlimit
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware"
+	"github.com/oniharnantyo/golang-backend-example/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+type TransferLimitHandler struct {
+	transferLimitUseCase domain.TransferLimitUseCase
+	logger               *logrus.Logger
+}
+
+func NewTransferLimitHandler(r *gin.Engine, t domain.TransferLimitUseCase, au domain.AuthUseCase, l *logrus.Logger) *gin.Engine {
+	handler := &TransferLimitHandler{transferLimitUseCase: t, logger: l}
+
+	auth := middleware.JWT(au)
+	admin := middleware.RequireRole(domain.RoleAdmin)
+	ownerOrStaff := middleware.RequireOwnerOrRole("account_number", domain.RoleTeller, domain.RoleAdmin)
+
+	r.GET("/account/:account_number/limits", auth, ownerOrStaff, handler.HandlerGetTransferLimits)
+	r.PUT("/account/:account_number/limits", auth, admin, handler.HandlerTransferLimitSet)
+	r.DELETE("/account/:account_number/limits", auth, admin, handler.HandlerTransferLimitReset)
+
+	return r
+}
+
+func (t *TransferLimitHandler) HandlerGetTransferLimits(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		t.logger.Errorf("%s : %v", "TransferLimitHandler/HandlerGetTransferLimits/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	limits, err := t.transferLimitUseCase.Get(ctx, accountNumber)
+	if err != nil {
+		t.logger.Errorf("%s : %v", "TransferLimitHandler/HandlerGetTransferLimits/Get", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: limits})
+}
+
+func (t *TransferLimitHandler) HandlerTransferLimitSet(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		t.logger.Errorf("%s : %v", "TransferLimitHandler/HandlerTransferLimitSet/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	var param domain.AccountLimitParam
+	err = ctx.ShouldBindJSON(&param)
+	if err != nil {
+		t.logger.Errorf("%s : %v", "TransferLimitHandler/HandlerTransferLimitSet/ParseBodyData", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	limits, err := t.transferLimitUseCase.Set(ctx, accountNumber, param)
+	if err != nil {
+		t.logger.Errorf("%s : %v", "TransferLimitHandler/HandlerTransferLimitSet/Set", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, util.Response{Data: limits})
+}
+
+func (t *TransferLimitHandler) HandlerTransferLimitReset(ctx *gin.Context) {
+	accountNumber, err := strconv.Atoi(ctx.Param("account_number"))
+	if err != nil {
+		t.logger.Errorf("%s : %v", "TransferLimitHandler/HandlerTransferLimitReset/parseAccountNumber", err)
+		ctx.Error(domain.ErrBadRequest)
+		return
+	}
+
+	err = t.transferLimitUseCase.Reset(ctx, accountNumber)
+	if err != nil {
+		t.logger.Errorf("%s : %v", "TransferLimitHandler/HandlerTransferLimitReset/Reset", err)
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,147 @@
+package delivery_http_transferlimit
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	"github.com/oniharnantyo/golang-backend-example/middleware/testutil"
+	auth_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/auth/usecase/mock"
+	transferlimit_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/transferlimit/usecase/mock"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func authAs(accountNumber string, role string) *auth_usecase_mock.AuthMockUseCase {
+	mockAuthUseCase := new(auth_usecase_mock.AuthMockUseCase)
+	mockAuthUseCase.On("ValidateAccessToken", mock.Anything, "token").Return(domain.AccessClaims{
+		StandardClaims: jwt.StandardClaims{Subject: accountNumber},
+		Role:           role,
+	}, nil)
+
+	return mockAuthUseCase
+}
+
+func TestTransferLimitHandler_HandlerGetTransferLimits(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name          string
+		accountNumber string
+		role          string
+		status        int
+	}{
+		{"Owner", "555001", domain.RoleCustomer, http.StatusOK},
+		{"Teller", "555009", domain.RoleTeller, http.StatusOK},
+		{"Other-customer", "555002", domain.RoleCustomer, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockTransferLimitUseCase := new(transferlimit_usecase_mock.TransferLimitMockUseCase)
+			mockTransferLimitUseCase.On("Get", mock.Anything, 555001).Return(domain.TransferLimits{
+				AccountNumber: 555001,
+				Tier:          "standard",
+				PerTransfer:   domain.NewMoney(5000000, "IDR"),
+				Daily:         domain.NewMoney(10000000, "IDR"),
+				Monthly:       domain.NewMoney(0, "IDR"),
+				HourlyCount:   10,
+				Overrides:     []string{domain.TransferLimitMonthly},
+			}, nil).Maybe()
+
+			r := testutil.NewRouter()
+			r = NewTransferLimitHandler(r, mockTransferLimitUseCase, authAs(c.accountNumber, c.role), logger)
+
+			req, err := http.NewRequest(http.MethodGet, "/account/555001/limits", nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+			if c.status == http.StatusOK {
+				assert.JSONEq(t, `{"data":{"account_number":555001,"tier":"standard","per_transfer":{"amount":"50000.00","currency":"IDR"},"daily":{"amount":"100000.00","currency":"IDR"},"monthly":{"amount":"0.00","currency":"IDR"},"hourly_count":10,"overrides":["monthly"]}}`, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTransferLimitHandler_HandlerTransferLimitSet(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		role   string
+		body   string
+		err    error
+		status int
+	}{
+		{"Success", domain.RoleAdmin, `{"tier":"premium","daily":{"amount":"2500.00","currency":"IDR"}}`, nil, http.StatusOK},
+		{"Unknown-tier", domain.RoleAdmin, `{"tier":"gold"}`, domain.ErrInvalidTier, http.StatusBadRequest},
+		{"Negative-count", domain.RoleAdmin, `{"hourly_count":-1}`, nil, http.StatusBadRequest},
+		{"Teller", domain.RoleTeller, `{"tier":"premium"}`, nil, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockTransferLimitUseCase := new(transferlimit_usecase_mock.TransferLimitMockUseCase)
+			mockTransferLimitUseCase.On("Set", mock.Anything, 555001, mock.AnythingOfType("domain.AccountLimitParam")).Return(domain.TransferLimits{AccountNumber: 555001}, c.err).Maybe()
+
+			r := testutil.NewRouter()
+			r = NewTransferLimitHandler(r, mockTransferLimitUseCase, authAs("555009", c.role), logger)
+
+			req, err := http.NewRequest(http.MethodPut, "/account/555001/limits", bytes.NewBufferString(c.body))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+		})
+	}
+}
+
+func TestTransferLimitHandler_HandlerTransferLimitReset(t *testing.T) {
+	logger := logrus.New()
+
+	cases := []struct {
+		name   string
+		role   string
+		status int
+	}{
+		{"Admin", domain.RoleAdmin, http.StatusNoContent},
+		{"Teller", domain.RoleTeller, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockTransferLimitUseCase := new(transferlimit_usecase_mock.TransferLimitMockUseCase)
+			mockTransferLimitUseCase.On("Reset", mock.Anything, 555001).Return(nil).Maybe()
+
+			r := testutil.NewRouter()
+			r = NewTransferLimitHandler(r, mockTransferLimitUseCase, authAs("555009", c.role), logger)
+
+			req, err := http.NewRequest(http.MethodDelete, "/account/555001/limits", nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer token")
+
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, c.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package repository_transferlimit_mock
+
+import (
+	"context"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type TransferLimitMockRepository struct {
+	mock.Mock
+}
+
+func (r *TransferLimitMockRepository) Get(ctx context.Context, accountNumber int) (domain.AccountLimit, error) {
+	args := r.Called(ctx, accountNumber)
+
+	return args.Get(0).(domain.AccountLimit), args.Error(1)
+}
+
+func (r *TransferLimitMockRepository) Upsert(ctx context.Context, limit domain.AccountLimit) error {
+	args := r.Called(ctx, limit)
+
+	return args.Error(0)
+}
+
+func (r *TransferLimitMockRepository) Delete(ctx context.Context, accountNumber int) error {
+	args := r.Called(ctx, accountNumber)
+
+	return args.Error(0)
+}
@@ -0,0 +1,138 @@
+package repository_transferlimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/oniharnantyo/golang-backend-example/database"
+	"github.com/oniharnantyo/golang-backend-example/domain"
+)
+
+type transferLimitRepository struct {
+	dbPool *sql.DB
+}
+
+func (r transferLimitRepository) Get(ctx context.Context, accountNumber int) (domain.AccountLimit, error) {
+	stmt, err := database.Conn(ctx, r.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		SELECT
+			account_number,
+			tier,
+			per_transfer,
+			daily,
+			monthly,
+			hourly_count,
+			updated_at
+		FROM account_limit
+		WHERE
+			account_number = $1
+	`))
+	if err != nil {
+		return domain.AccountLimit{}, err
+	}
+
+	var limit domain.AccountLimit
+	var perTransfer, daily, monthly, hourlyCount sql.NullInt64
+	err = stmt.QueryRowContext(ctx, accountNumber).Scan(
+		&limit.AccountNumber,
+		&limit.Tier,
+		&perTransfer,
+		&daily,
+		&monthly,
+		&hourlyCount,
+		&limit.UpdatedAt,
+	)
+	if err != nil {
+		return domain.AccountLimit{}, err
+	}
+
+	if perTransfer.Valid {
+		limit.PerTransfer = &perTransfer.Int64
+	}
+	if daily.Valid {
+		limit.Daily = &daily.Int64
+	}
+	if monthly.Valid {
+		limit.Monthly = &monthly.Int64
+	}
+	if hourlyCount.Valid {
+		count := int(hourlyCount.Int64)
+		limit.HourlyCount = &count
+	}
+
+	return limit, nil
+}
+
+func (r transferLimitRepository) Upsert(ctx context.Context, limit domain.AccountLimit) error {
+	stmt, err := database.Conn(ctx, r.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		INSERT INTO account_limit (
+			account_number,
+			tier,
+			per_transfer,
+			daily,
+			monthly,
+			hourly_count,
+			updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)
+		ON CONFLICT (account_number) DO UPDATE SET
+			tier = EXCLUDED.tier,
+			per_transfer = EXCLUDED.per_transfer,
+			daily = EXCLUDED.daily,
+			monthly = EXCLUDED.monthly,
+			hourly_count = EXCLUDED.hourly_count,
+			updated_at = EXCLUDED.updated_at
+	`))
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx,
+		limit.AccountNumber,
+		limit.Tier,
+		limit.PerTransfer,
+		limit.Daily,
+		limit.Monthly,
+		limit.HourlyCount,
+		limit.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r transferLimitRepository) Delete(ctx context.Context, accountNumber int) error {
+	stmt, err := database.Conn(ctx, r.dbPool).PrepareContext(ctx, fmt.Sprintf(`
+		DELETE FROM account_limit
+		WHERE
+			account_number = $1
+	`))
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, accountNumber)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func NewTransferLimitRepository(db *sql.DB) domain.TransferLimitRepository {
+	return &transferLimitRepository{
+		dbPool: db,
+	}
+}
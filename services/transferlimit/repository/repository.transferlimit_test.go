@@ -0,0 +1,157 @@
+package repository_transferlimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func initMock() (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		log.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	return db, mock
+}
+
+func TestTransferLimitRepository_Get(t *testing.T) {
+	query := fmt.Sprintf(`
+		SELECT
+			account_number,
+			tier,
+			per_transfer,
+			daily,
+			monthly,
+			hourly_count,
+			updated_at
+		FROM account_limit
+		WHERE
+			account_number = $1
+	`)
+
+	columns := []string{"account_number", "tier", "per_transfer", "daily", "monthly", "hourly_count", "updated_at"}
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		now := time.Now()
+		rows := sqlmock.NewRows(columns).AddRow(555001, "premium", nil, 200000, nil, 5, now)
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(555001).WillReturnRows(rows)
+
+		r := NewTransferLimitRepository(db)
+
+		limit, err := r.Get(context.Background(), 555001)
+		assert.NoError(t, err)
+		assert.Equal(t, "premium", limit.Tier)
+		assert.Nil(t, limit.PerTransfer)
+		assert.Equal(t, int64(200000), *limit.Daily)
+		assert.Nil(t, limit.Monthly)
+		assert.Equal(t, 5, *limit.HourlyCount)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Default-tier", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(555001).WillReturnRows(sqlmock.NewRows(columns))
+
+		r := NewTransferLimitRepository(db)
+
+		_, err := r.Get(context.Background(), 555001)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTransferLimitRepository_Upsert(t *testing.T) {
+	db, mock := initMock()
+
+	defer db.Close()
+
+	query := fmt.Sprintf(`
+		INSERT INTO account_limit (
+			account_number,
+			tier,
+			per_transfer,
+			daily,
+			monthly,
+			hourly_count,
+			updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)
+		ON CONFLICT (account_number) DO UPDATE SET
+			tier = EXCLUDED.tier,
+			per_transfer = EXCLUDED.per_transfer,
+			daily = EXCLUDED.daily,
+			monthly = EXCLUDED.monthly,
+			hourly_count = EXCLUDED.hourly_count,
+			updated_at = EXCLUDED.updated_at
+	`)
+
+	now := time.Now()
+	daily := int64(200000)
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(555001, "premium", nil, daily, nil, nil, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := NewTransferLimitRepository(db)
+
+	err := r.Upsert(context.Background(), domain.AccountLimit{
+		AccountNumber: 555001,
+		Tier:          "premium",
+		Daily:         &daily,
+		UpdatedAt:     now,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransferLimitRepository_Delete(t *testing.T) {
+	query := fmt.Sprintf(`
+		DELETE FROM account_limit
+		WHERE
+			account_number = $1
+	`)
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(555001).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		r := NewTransferLimitRepository(db)
+
+		err := r.Delete(context.Background(), 555001)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Default-tier", func(t *testing.T) {
+		db, mock := initMock()
+
+		defer db.Close()
+
+		mock.ExpectPrepare(query).ExpectExec().WithArgs(555001).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := NewTransferLimitRepository(db)
+
+		err := r.Delete(context.Background(), 555001)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
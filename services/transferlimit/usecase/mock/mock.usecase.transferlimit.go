@@ -0,0 +1,37 @@
+package transferlimit_usecase_mock
+
+import (
+	"context"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type TransferLimitMockUseCase struct {
+	mock.Mock
+}
+
+func (t *TransferLimitMockUseCase) Get(ctx context.Context, accountNumber int) (domain.TransferLimits, error) {
+	args := t.Called(ctx, accountNumber)
+
+	return args.Get(0).(domain.TransferLimits), args.Error(1)
+}
+
+func (t *TransferLimitMockUseCase) Set(ctx context.Context, accountNumber int, param domain.AccountLimitParam) (domain.TransferLimits, error) {
+	args := t.Called(ctx, accountNumber, param)
+
+	return args.Get(0).(domain.TransferLimits), args.Error(1)
+}
+
+func (t *TransferLimitMockUseCase) Reset(ctx context.Context, accountNumber int) error {
+	args := t.Called(ctx, accountNumber)
+
+	return args.Error(0)
+}
+
+func (t *TransferLimitMockUseCase) Check(ctx context.Context, account domain.Account, amount domain.Money) error {
+	args := t.Called(ctx, account, amount)
+
+	return args.Error(0)
+}
@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type transferLimitUseCase struct {
+	transferLimitRepository domain.TransferLimitRepository
+	accountRepository       domain.AccountRepository
+	transferRepository      domain.TransferRepository
+	fxRateUseCase           domain.FXRateUseCase
+	policy                  domain.LimitPolicy
+	logger                  *logrus.Logger
+}
+
+func (t transferLimitUseCase) Get(ctx context.Context, accountNumber int) (domain.TransferLimits, error) {
+	account, err := t.accountRepository.GetByAccountNumber(ctx, accountNumber)
+	if err != nil {
+		t.logger.Errorf("transferLimitUseCase/Get/GetByAccountNumber :%v", err)
+		if errors.Cause(err) == sql.ErrNoRows {
+			return domain.TransferLimits{}, domain.ErrAccountNotFound
+		}
+		return domain.TransferLimits{}, err
+	}
+
+	limit, err := t.accountLimit(ctx, accountNumber)
+	if err != nil {
+		return domain.TransferLimits{}, err
+	}
+
+	return t.transferLimits(ctx, limit, account.Balance.Currency)
+}
+
+func (t transferLimitUseCase) Set(ctx context.Context, accountNumber int, param domain.AccountLimitParam) (domain.TransferLimits, error) {
+	tier := param.Tier
+	if tier == "" {
+		tier = t.policy.DefaultTier
+	}
+
+	if _, ok := t.policy.Tiers[tier]; !ok {
+		return domain.TransferLimits{}, domain.ErrInvalidTier
+	}
+
+	if param.HourlyCount != nil && *param.HourlyCount < 0 {
+		return domain.TransferLimits{}, domain.ErrInvalidTransferLimit
+	}
+
+	account, err := t.accountRepository.GetByAccountNumber(ctx, accountNumber)
+	if err != nil {
+		t.logger.Errorf("transferLimitUseCase/Set/GetByAccountNumber :%v", err)
+		if errors.Cause(err) == sql.ErrNoRows {
+			return domain.TransferLimits{}, domain.ErrAccountNotFound
+		}
+		return domain.TransferLimits{}, err
+	}
+
+	limit := domain.AccountLimit{
+		AccountNumber: accountNumber,
+		Tier:          tier,
+		HourlyCount:   param.HourlyCount,
+		UpdatedAt:     time.Now().UTC(),
+	}
+
+	amounts := []struct {
+		param *domain.Money
+		limit **int64
+	}{
+		{param.PerTransfer, &limit.PerTransfer},
+		{param.Daily, &limit.Daily},
+		{param.Monthly, &limit.Monthly},
+	}
+	for _, amount := range amounts {
+		if amount.param == nil {
+			continue
+		}
+
+		if amount.param.Currency != account.Balance.Currency {
+			return domain.TransferLimits{}, domain.ErrCurrencyMismatch
+		}
+
+		if amount.param.IsNegative() {
+			return domain.TransferLimits{}, domain.ErrInvalidTransferLimit
+		}
+
+		value := amount.param.Amount
+		*amount.limit = &value
+	}
+
+	err = t.transferLimitRepository.Upsert(ctx, limit)
+	if err != nil {
+		t.logger.Errorf("transferLimitUseCase/Set/Upsert :%v", err)
+		return domain.TransferLimits{}, err
+	}
+
+	return t.transferLimits(ctx, limit, account.Balance.Currency)
+}
+
+func (t transferLimitUseCase) Reset(ctx context.Context, accountNumber int) error {
+	err := t.transferLimitRepository.Delete(ctx, accountNumber)
+	if err != nil {
+		// Already in the default tier without overrides
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil
+		}
+
+		t.logger.Errorf("transferLimitUseCase/Reset/Delete :%v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Check counts the day and the month in UTC from their start, and the hour as
+// the last sixty minutes. Withdrawals count towards the day and the month.
+func (t transferLimitUseCase) Check(ctx context.Context, account domain.Account, amount domain.Money) error {
+	limit, err := t.accountLimit(ctx, account.AccountNumber)
+	if err != nil {
+		return err
+	}
+
+	limits, err := t.transferLimits(ctx, limit, account.Balance.Currency)
+	if err != nil {
+		return err
+	}
+
+	if limits.PerTransfer.IsPositive() && amount.Amount > limits.PerTransfer.Amount {
+		return domain.TransferLimitExceededError{Limit: domain.TransferLimitPerTransfer, Remaining: &limits.PerTransfer}
+	}
+
+	now := time.Now().UTC()
+
+	windows := []struct {
+		name  string
+		limit domain.Money
+		since time.Time
+	}{
+		{domain.TransferLimitDaily, limits.Daily, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)},
+		{domain.TransferLimitMonthly, limits.Monthly, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, window := range windows {
+		if !window.limit.IsPositive() {
+			continue
+		}
+
+		total, err := t.transferRepository.OutgoingTotal(ctx, account.AccountNumber, window.since)
+		if err != nil {
+			t.logger.Errorf("transferLimitUseCase/Check/OutgoingTotal :%v", err)
+			return err
+		}
+
+		remaining := domain.NewMoney(window.limit.Amount-total, window.limit.Currency)
+		if amount.Amount > remaining.Amount {
+			if remaining.IsNegative() {
+				remaining.Amount = 0
+			}
+			return domain.TransferLimitExceededError{Limit: window.name, Remaining: &remaining}
+		}
+	}
+
+	if limits.HourlyCount > 0 {
+		count, err := t.transferRepository.OutgoingCount(ctx, account.AccountNumber, now.Add(-time.Hour))
+		if err != nil {
+			t.logger.Errorf("transferLimitUseCase/Check/OutgoingCount :%v", err)
+			return err
+		}
+
+		if count >= limits.HourlyCount {
+			remaining := 0
+			return domain.TransferLimitExceededError{Limit: domain.TransferLimitHourlyCount, RemainingCount: &remaining}
+		}
+	}
+
+	return nil
+}
+
+// accountLimit returns the limits of the account of its own, or the default
+// tier without overrides.
+func (t transferLimitUseCase) accountLimit(ctx context.Context, accountNumber int) (domain.AccountLimit, error) {
+	limit, err := t.transferLimitRepository.Get(ctx, accountNumber)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return domain.AccountLimit{AccountNumber: accountNumber, Tier: t.policy.DefaultTier}, nil
+		}
+
+		t.logger.Errorf("transferLimitUseCase/accountLimit/Get :%v", err)
+		return domain.AccountLimit{}, err
+	}
+
+	return limit, nil
+}
+
+// transferLimits applies the overrides of limit to its tier, in currency. A
+// tier that was removed from the policy since has no limits but the
+// overrides.
+func (t transferLimitUseCase) transferLimits(ctx context.Context, limit domain.AccountLimit, currency string) (domain.TransferLimits, error) {
+	tier := t.policy.Tiers[limit.Tier]
+
+	// Amounts given for the currency itself need no exchange rate
+	tierCurrency := t.policy.Currency
+	tierAmounts := domain.TierAmounts{PerTransfer: tier.PerTransfer, Daily: tier.Daily, Monthly: tier.Monthly}
+	if own, ok := tier.Currencies[currency]; ok {
+		tierCurrency = currency
+		tierAmounts = own
+	}
+
+	limits := domain.TransferLimits{
+		AccountNumber: limit.AccountNumber,
+		Tier:          limit.Tier,
+		HourlyCount:   tier.HourlyCount,
+	}
+
+	amounts := []struct {
+		name     string
+		tier     int64
+		override *int64
+		limit    *domain.Money
+	}{
+		{domain.TransferLimitPerTransfer, tierAmounts.PerTransfer, limit.PerTransfer, &limits.PerTransfer},
+		{domain.TransferLimitDaily, tierAmounts.Daily, limit.Daily, &limits.Daily},
+		{domain.TransferLimitMonthly, tierAmounts.Monthly, limit.Monthly, &limits.Monthly},
+	}
+	for _, amount := range amounts {
+		// Overrides are in the currency of the account already
+		if amount.override != nil {
+			*amount.limit = domain.NewMoney(*amount.override, currency)
+			limits.Overrides = append(limits.Overrides, amount.name)
+			continue
+		}
+
+		converted, err := t.tierAmount(ctx, domain.NewMoney(amount.tier, tierCurrency), currency)
+		if err != nil {
+			return domain.TransferLimits{}, err
+		}
+		*amount.limit = converted
+	}
+
+	if limit.HourlyCount != nil {
+		limits.HourlyCount = *limit.HourlyCount
+		limits.Overrides = append(limits.Overrides, domain.TransferLimitHourlyCount)
+	}
+
+	return limits, nil
+}
+
+// tierAmount converts an amount of a tier into currency at the stored
+// exchange rate. Without a usable rate the limits are unavailable, not the
+// exchange rate, as no money is converted.
+func (t transferLimitUseCase) tierAmount(ctx context.Context, amount domain.Money, currency string) (domain.Money, error) {
+	if amount.Amount == 0 || amount.Currency == currency {
+		return domain.NewMoney(amount.Amount, currency), nil
+	}
+
+	converted, _, err := t.fxRateUseCase.Convert(ctx, amount, currency)
+	if err != nil {
+		t.logger.Errorf("transferLimitUseCase/tierAmount/Convert :%v", err)
+		if cause := errors.Cause(err); cause == domain.ErrFXRateNotFound || cause == domain.ErrFXRateStale {
+			return domain.Money{}, domain.ErrTransferLimitsUnavailable
+		}
+		return domain.Money{}, err
+	}
+
+	return converted, nil
+}
+
+func NewTransferLimitUseCase(tl domain.TransferLimitRepository, a domain.AccountRepository, t domain.TransferRepository, fx domain.FXRateUseCase, policy domain.LimitPolicy, log *logrus.Logger) domain.TransferLimitUseCase {
+	return &transferLimitUseCase{
+		transferLimitRepository: tl,
+		accountRepository:       a,
+		transferRepository:      t,
+		fxRateUseCase:           fx,
+		policy:                  policy,
+		logger:                  log,
+	}
+}
@@ -0,0 +1,322 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/oniharnantyo/golang-backend-example/domain"
+	repository_account_mock "github.com/oniharnantyo/golang-backend-example/services/account/repository/mock"
+	fxrate_usecase_mock "github.com/oniharnantyo/golang-backend-example/services/fxrate/usecase/mock"
+	repository_transfer_mock "github.com/oniharnantyo/golang-backend-example/services/transfer/repository/mock"
+	repository_transferlimit_mock "github.com/oniharnantyo/golang-backend-example/services/transferlimit/repository/mock"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var limitPolicy = domain.LimitPolicy{
+	DefaultTier: "standard",
+	Currency:    "IDR",
+	Tiers: map[string]domain.TierLimits{
+		"standard": {PerTransfer: 50000, Daily: 100000, Monthly: 500000, HourlyCount: 5},
+		"premium":  {PerTransfer: 500000, Daily: 1000000},
+	},
+}
+
+var account = domain.Account{AccountNumber: 555001, Balance: domain.NewMoney(1000000, "IDR")}
+
+func TestTransferLimitUseCase_Get(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Default-tier", func(t *testing.T) {
+		mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(account, nil).Once()
+		mockTransferLimitRepo.On("Get", mock.Anything, 555001).Return(domain.AccountLimit{}, sql.ErrNoRows).Once()
+
+		l := NewTransferLimitUseCase(mockTransferLimitRepo, mockAccountRepo, new(repository_transfer_mock.TransferMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), limitPolicy, logger)
+
+		limits, err := l.Get(context.Background(), 555001)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.TransferLimits{
+			AccountNumber: 555001,
+			Tier:          "standard",
+			PerTransfer:   domain.NewMoney(50000, "IDR"),
+			Daily:         domain.NewMoney(100000, "IDR"),
+			Monthly:       domain.NewMoney(500000, "IDR"),
+			HourlyCount:   5,
+		}, limits)
+	})
+
+	t.Run("Overrides", func(t *testing.T) {
+		mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+
+		daily := int64(0)
+		hourlyCount := 20
+
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(account, nil).Once()
+		mockTransferLimitRepo.On("Get", mock.Anything, 555001).Return(domain.AccountLimit{
+			AccountNumber: 555001,
+			Tier:          "premium",
+			Daily:         &daily,
+			HourlyCount:   &hourlyCount,
+		}, nil).Once()
+
+		l := NewTransferLimitUseCase(mockTransferLimitRepo, mockAccountRepo, new(repository_transfer_mock.TransferMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), limitPolicy, logger)
+
+		limits, err := l.Get(context.Background(), 555001)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.NewMoney(500000, "IDR"), limits.PerTransfer)
+		assert.Equal(t, domain.NewMoney(0, "IDR"), limits.Daily)
+		assert.Equal(t, 20, limits.HourlyCount)
+		assert.Equal(t, []string{domain.TransferLimitDaily, domain.TransferLimitHourlyCount}, limits.Overrides)
+	})
+
+	t.Run("Other-currency", func(t *testing.T) {
+		mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+
+		daily := int64(2000)
+
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555002).Return(domain.Account{AccountNumber: 555002, Balance: domain.NewMoney(0, "USD")}, nil).Once()
+		mockTransferLimitRepo.On("Get", mock.Anything, 555002).Return(domain.AccountLimit{AccountNumber: 555002, Tier: "standard", Daily: &daily}, nil).Once()
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(50000, "IDR"), "USD").Return(domain.NewMoney(3, "USD"), domain.FXRate{}, nil).Once()
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(500000, "IDR"), "USD").Return(domain.NewMoney(33, "USD"), domain.FXRate{}, nil).Once()
+
+		l := NewTransferLimitUseCase(mockTransferLimitRepo, mockAccountRepo, new(repository_transfer_mock.TransferMockRepository), mockFXRateUseCase, limitPolicy, logger)
+
+		limits, err := l.Get(context.Background(), 555002)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.NewMoney(3, "USD"), limits.PerTransfer)
+		// Overrides are in the account currency and not converted
+		assert.Equal(t, domain.NewMoney(2000, "USD"), limits.Daily)
+		assert.Equal(t, domain.NewMoney(33, "USD"), limits.Monthly)
+		mockFXRateUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Other-currency-without-rate", func(t *testing.T) {
+		mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555002).Return(domain.Account{AccountNumber: 555002, Balance: domain.NewMoney(0, "USD")}, nil).Once()
+		mockTransferLimitRepo.On("Get", mock.Anything, 555002).Return(domain.AccountLimit{}, sql.ErrNoRows).Once()
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(50000, "IDR"), "USD").Return(domain.Money{}, domain.FXRate{}, domain.ErrFXRateNotFound).Once()
+
+		l := NewTransferLimitUseCase(mockTransferLimitRepo, mockAccountRepo, new(repository_transfer_mock.TransferMockRepository), mockFXRateUseCase, limitPolicy, logger)
+
+		_, err := l.Get(context.Background(), 555002)
+		assert.Equal(t, domain.ErrTransferLimitsUnavailable, err)
+	})
+
+	t.Run("Currency-of-tier", func(t *testing.T) {
+		mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+		mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+
+		policy := limitPolicy
+		policy.Tiers = map[string]domain.TierLimits{
+			"standard": {PerTransfer: 50000, Daily: 100000, HourlyCount: 5, Currencies: map[string]domain.TierAmounts{
+				"USD": {PerTransfer: 300, Daily: 700},
+			}},
+		}
+
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555002).Return(domain.Account{AccountNumber: 555002, Balance: domain.NewMoney(0, "USD")}, nil).Once()
+		mockTransferLimitRepo.On("Get", mock.Anything, 555002).Return(domain.AccountLimit{}, sql.ErrNoRows).Once()
+
+		l := NewTransferLimitUseCase(mockTransferLimitRepo, mockAccountRepo, new(repository_transfer_mock.TransferMockRepository), mockFXRateUseCase, policy, logger)
+
+		limits, err := l.Get(context.Background(), 555002)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.NewMoney(300, "USD"), limits.PerTransfer)
+		assert.Equal(t, domain.NewMoney(700, "USD"), limits.Daily)
+		assert.Equal(t, domain.NewMoney(0, "USD"), limits.Monthly)
+		assert.Equal(t, 5, limits.HourlyCount)
+		mockFXRateUseCase.AssertNotCalled(t, "Convert", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Account-not-found", func(t *testing.T) {
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(domain.Account{}, sql.ErrNoRows).Once()
+
+		l := NewTransferLimitUseCase(new(repository_transferlimit_mock.TransferLimitMockRepository), mockAccountRepo, new(repository_transfer_mock.TransferMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), limitPolicy, logger)
+
+		_, err := l.Get(context.Background(), 555001)
+		assert.Equal(t, domain.ErrAccountNotFound, err)
+	})
+}
+
+func TestTransferLimitUseCase_Set(t *testing.T) {
+	logger := logrus.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+		mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+
+		mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(account, nil).Once()
+		mockTransferLimitRepo.On("Upsert", mock.Anything, mock.MatchedBy(func(l domain.AccountLimit) bool {
+			return l.AccountNumber == 555001 && l.Tier == "standard" && l.PerTransfer == nil &&
+				l.Daily != nil && *l.Daily == 250000 && l.Monthly == nil && l.HourlyCount == nil && !l.UpdatedAt.IsZero()
+		})).Return(nil).Once()
+
+		l := NewTransferLimitUseCase(mockTransferLimitRepo, mockAccountRepo, new(repository_transfer_mock.TransferMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), limitPolicy, logger)
+
+		daily := domain.NewMoney(250000, "IDR")
+		limits, err := l.Set(context.Background(), 555001, domain.AccountLimitParam{Daily: &daily})
+		assert.NoError(t, err)
+		assert.Equal(t, daily, limits.Daily)
+		assert.Equal(t, []string{domain.TransferLimitDaily}, limits.Overrides)
+
+		mockTransferLimitRepo.AssertExpectations(t)
+	})
+
+	usd := domain.NewMoney(1000, "USD")
+	negative := domain.NewMoney(-1000, "IDR")
+
+	cases := []struct {
+		name  string
+		param domain.AccountLimitParam
+		err   error
+	}{
+		{"Unknown-tier", domain.AccountLimitParam{Tier: "gold"}, domain.ErrInvalidTier},
+		{"Currency-mismatch", domain.AccountLimitParam{PerTransfer: &usd}, domain.ErrCurrencyMismatch},
+		{"Negative", domain.AccountLimitParam{Monthly: &negative}, domain.ErrInvalidTransferLimit},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+			mockAccountRepo := new(repository_account_mock.AccountMockRepository)
+
+			mockAccountRepo.On("GetByAccountNumber", mock.Anything, 555001).Return(account, nil).Maybe()
+
+			l := NewTransferLimitUseCase(mockTransferLimitRepo, mockAccountRepo, new(repository_transfer_mock.TransferMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), limitPolicy, logger)
+
+			_, err := l.Set(context.Background(), 555001, c.param)
+			assert.Equal(t, c.err, err)
+
+			mockTransferLimitRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTransferLimitUseCase_Reset(t *testing.T) {
+	logger := logrus.New()
+
+	for _, deleteErr := range []error{nil, sql.ErrNoRows} {
+		mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+		mockTransferLimitRepo.On("Delete", mock.Anything, 555001).Return(deleteErr).Once()
+
+		l := NewTransferLimitUseCase(mockTransferLimitRepo, new(repository_account_mock.AccountMockRepository), new(repository_transfer_mock.TransferMockRepository), new(fxrate_usecase_mock.FXRateMockUseCase), limitPolicy, logger)
+
+		err := l.Reset(context.Background(), 555001)
+		assert.NoError(t, err)
+	}
+}
+
+func TestTransferLimitUseCase_Check(t *testing.T) {
+	logger := logrus.New()
+
+	remaining := func(amount int64) *domain.Money {
+		m := domain.NewMoney(amount, "IDR")
+		return &m
+	}
+	none := 0
+
+	cases := []struct {
+		name         string
+		amount       int64
+		dailyTotal   int64
+		monthlyTotal int64
+		hourlyCount  int
+		err          error
+	}{
+		{"Within-limits", 20000, 50000, 300000, 4, nil},
+		{"Per-transfer", 60000, 0, 0, 0, domain.TransferLimitExceededError{Limit: domain.TransferLimitPerTransfer, Remaining: remaining(50000)}},
+		{"Daily", 20000, 90000, 90000, 0, domain.TransferLimitExceededError{Limit: domain.TransferLimitDaily, Remaining: remaining(10000)}},
+		{"Monthly", 20000, 0, 490000, 0, domain.TransferLimitExceededError{Limit: domain.TransferLimitMonthly, Remaining: remaining(10000)}},
+		{"Hourly-count", 20000, 0, 0, 5, domain.TransferLimitExceededError{Limit: domain.TransferLimitHourlyCount, RemainingCount: &none}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+			mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+
+			mockTransferLimitRepo.On("Get", mock.Anything, 555001).Return(domain.AccountLimit{}, sql.ErrNoRows).Once()
+			// The day is summed before the month
+			mockTransferRepo.On("OutgoingTotal", mock.Anything, 555001, mock.Anything).Return(c.dailyTotal, nil).Once()
+			mockTransferRepo.On("OutgoingTotal", mock.Anything, 555001, mock.Anything).Return(c.monthlyTotal, nil).Maybe()
+			mockTransferRepo.On("OutgoingCount", mock.Anything, 555001, mock.Anything).Return(c.hourlyCount, nil).Maybe()
+
+			l := NewTransferLimitUseCase(mockTransferLimitRepo, new(repository_account_mock.AccountMockRepository), mockTransferRepo, new(fxrate_usecase_mock.FXRateMockUseCase), limitPolicy, logger)
+
+			err := l.Check(context.Background(), account, domain.NewMoney(c.amount, "IDR"))
+			assert.Equal(t, c.err, err)
+		})
+	}
+
+	t.Run("Other-currency", func(t *testing.T) {
+		mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+
+		usdAccount := domain.Account{AccountNumber: 555002, Balance: domain.NewMoney(1000000, "USD")}
+
+		// IDR 500.00 per transfer is USD 0.03, a USD 500.00 transfer is far over it
+		mockTransferLimitRepo.On("Get", mock.Anything, 555002).Return(domain.AccountLimit{}, sql.ErrNoRows).Once()
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(50000, "IDR"), "USD").Return(domain.NewMoney(3, "USD"), domain.FXRate{}, nil).Once()
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(100000, "IDR"), "USD").Return(domain.NewMoney(7, "USD"), domain.FXRate{}, nil).Once()
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(500000, "IDR"), "USD").Return(domain.NewMoney(33, "USD"), domain.FXRate{}, nil).Once()
+
+		l := NewTransferLimitUseCase(mockTransferLimitRepo, new(repository_account_mock.AccountMockRepository), mockTransferRepo, mockFXRateUseCase, limitPolicy, logger)
+
+		err := l.Check(context.Background(), usdAccount, domain.NewMoney(50000, "USD"))
+		remaining := domain.NewMoney(3, "USD")
+		assert.Equal(t, domain.TransferLimitExceededError{Limit: domain.TransferLimitPerTransfer, Remaining: &remaining}, err)
+	})
+
+	t.Run("Other-currency-without-rate", func(t *testing.T) {
+		mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+		mockFXRateUseCase := new(fxrate_usecase_mock.FXRateMockUseCase)
+
+		usdAccount := domain.Account{AccountNumber: 555002, Balance: domain.NewMoney(1000000, "USD")}
+
+		mockTransferLimitRepo.On("Get", mock.Anything, 555002).Return(domain.AccountLimit{}, sql.ErrNoRows).Once()
+		mockFXRateUseCase.On("Convert", mock.Anything, domain.NewMoney(50000, "IDR"), "USD").Return(domain.Money{}, domain.FXRate{}, domain.ErrFXRateStale).Once()
+
+		l := NewTransferLimitUseCase(mockTransferLimitRepo, new(repository_account_mock.AccountMockRepository), mockTransferRepo, mockFXRateUseCase, limitPolicy, logger)
+
+		err := l.Check(context.Background(), usdAccount, domain.NewMoney(100, "USD"))
+		assert.Equal(t, domain.ErrTransferLimitsUnavailable, err)
+		mockTransferRepo.AssertNotCalled(t, "OutgoingTotal", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("No-limits", func(t *testing.T) {
+		mockTransferLimitRepo := new(repository_transferlimit_mock.TransferLimitMockRepository)
+		mockTransferRepo := new(repository_transfer_mock.TransferMockRepository)
+
+		zero := int64(0)
+		mockTransferLimitRepo.On("Get", mock.Anything, 555001).Return(domain.AccountLimit{
+			AccountNumber: 555001,
+			Tier:          "premium",
+			PerTransfer:   &zero,
+			Daily:         &zero,
+		}, nil).Once()
+
+		l := NewTransferLimitUseCase(mockTransferLimitRepo, new(repository_account_mock.AccountMockRepository), mockTransferRepo, new(fxrate_usecase_mock.FXRateMockUseCase), limitPolicy, logger)
+
+		err := l.Check(context.Background(), account, domain.NewMoney(900000, "IDR"))
+		assert.NoError(t, err)
+
+		mockTransferRepo.AssertNotCalled(t, "OutgoingTotal", mock.Anything, mock.Anything, mock.Anything)
+		mockTransferRepo.AssertNotCalled(t, "OutgoingCount", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
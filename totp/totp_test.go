@@ -0,0 +1,85 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The SHA1 secret of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to 6 digits.
+	cases := []struct {
+		time int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, c := range cases {
+		code, err := Code(rfcSecret, Step(time.Unix(c.time, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, c.code, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	t.Run("Current-step", func(t *testing.T) {
+		step, ok := Validate(rfcSecret, "050471", now, 1)
+		assert.True(t, ok)
+		assert.Equal(t, Step(now), step)
+	})
+
+	t.Run("Previous-step-within-skew", func(t *testing.T) {
+		previous, err := Code(rfcSecret, Step(now)-1)
+		assert.NoError(t, err)
+
+		step, ok := Validate(rfcSecret, previous, now, 1)
+		assert.True(t, ok)
+		assert.Equal(t, Step(now)-1, step)
+	})
+
+	t.Run("Outside-skew", func(t *testing.T) {
+		old, err := Code(rfcSecret, Step(now)-2)
+		assert.NoError(t, err)
+
+		_, ok := Validate(rfcSecret, old, now, 1)
+		assert.False(t, ok)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, ok := Validate(rfcSecret, "12345", now, 1)
+		assert.False(t, ok)
+	})
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+
+	_, err = Code(secret, 1)
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("Bank", "555001", "SECRET"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Bank:555001", uri.Path)
+	assert.Equal(t, "SECRET", uri.Query().Get("secret"))
+	assert.Equal(t, "Bank", uri.Query().Get("issuer"))
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidCursor is returned for a cursor that was not issued by the signer
+// or belongs to another listing.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorSigner turns the sort key of the last item of a page into an opaque
+// cursor and back. Cursors are signed, so clients cannot forge a position.
+type CursorSigner struct {
+	secret []byte
+}
+
+func NewCursorSigner(secret string) CursorSigner {
+	return CursorSigner{secret: []byte(secret)}
+}
+
+// Encode returns the cursor of key for the listing kind.
+func (s CursorSigner) Encode(kind string, key interface{}) (string, error) {
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(b)
+
+	return payload + "." + s.sign(kind, payload), nil
+}
+
+// Decode verifies cursor for the listing kind and reads its key into key.
+func (s CursorSigner) Decode(kind string, cursor string, key interface{}) error {
+	parts := strings.Split(cursor, ".")
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(s.sign(kind, parts[0]))) {
+		return ErrInvalidCursor
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	if json.Unmarshal(b, key) != nil {
+		return ErrInvalidCursor
+	}
+
+	return nil
+}
+
+func (s CursorSigner) sign(kind, payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
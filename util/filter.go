@@ -1,10 +1,37 @@
 package util
 
+const (
+	// DefaultLimit is the page size of a list request without a limit.
+	DefaultLimit = 20
+	// MaxLimit caps the page size a client can ask for.
+	MaxLimit = 100
+)
+
 type (
+	// Filter pages a list either by Offset or, when Cursor is set, after the
+	// item the cursor points at. Offset is ignored with a cursor. Sort lists
+	// the fields to sort by, like -balance,account_number, Order is the
+	// direction of the default sort.
 	Filter struct {
 		Limit  int    `json:"limit" form:"limit"`
 		Offset int    `json:"offset" form:"offset"`
+		Cursor string `json:"cursor" form:"cursor"`
 		Search string `json:"search" form:"search"`
+		Sort   string `json:"sort" form:"sort"`
 		Order  string `json:"order" form:"order"`
 	}
 )
+
+// Paginate applies DefaultLimit to a missing limit, caps it at MaxLimit and
+// drops a negative offset, or any offset when a cursor is given.
+func (f *Filter) Paginate() {
+	if f.Limit <= 0 {
+		f.Limit = DefaultLimit
+	}
+	if f.Limit > MaxLimit {
+		f.Limit = MaxLimit
+	}
+	if f.Offset < 0 || f.Cursor != "" {
+		f.Offset = 0
+	}
+}
@@ -1,7 +1,6 @@
 package util
 
 import (
-	"html"
 	"net/http"
 	"strings"
 
@@ -21,8 +20,5 @@ func ParseQueryParams(r *http.Request) (Filter, error) {
 		return Filter{}, errors.Wrap(err, "Invalid order value")
 	}
 
-	// Escaping value to prevent SQL Injection
-	filter.Search = html.EscapeString(filter.Search)
-
 	return filter, nil
 }
@@ -1,23 +1,244 @@
 package util
 
-import "fmt"
-
-func BuildFilterQuery(filters []string) string {
-	var filtersQuery string
-	if len(filters) != 0 {
-		filtersQuery = "WHERE"
-		if len(filters) == 1 {
-			filtersQuery = fmt.Sprintf(`%s %s`, filtersQuery, filters[0])
-		} else {
-			for i, filter := range filters {
-				if i == 0 {
-					filtersQuery = fmt.Sprintf(`%s %s`, filtersQuery, filter)
-				}
-				if i > 0 {
-					filtersQuery = fmt.Sprintf(`%s AND %s`, filtersQuery, filter)
-				}
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Condition is a filter a listing declares. When its list parameter Param
+// is set, SQL is added with the ? bound to the value of the parameter.
+type Condition struct {
+	Param string
+	SQL   string
+}
+
+// Query builds the WHERE, ORDER BY and LIMIT parts of a statement. Values are
+// only bound to numbered placeholders, never written into the SQL, and the
+// SQL it writes is limited to the columns the repository passes in.
+type Query struct {
+	conditions []string
+	orderBy    []string
+	limit      string
+	args       []interface{}
+}
+
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Bind adds value to the arguments and returns its placeholder.
+func (q *Query) Bind(value interface{}) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// Where adds a condition. Every ? in it is bound to the next of args.
+func (q *Query) Where(condition string, args ...interface{}) *Query {
+	if strings.Count(condition, "?") != len(args) {
+		panic(fmt.Sprintf("util: %q needs %d arguments", condition, strings.Count(condition, "?")))
+	}
+
+	var b strings.Builder
+	for _, r := range condition {
+		if r == '?' {
+			b.WriteString(q.Bind(args[0]))
+			args = args[1:]
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	q.conditions = append(q.conditions, b.String())
+	return q
+}
+
+// Search adds a case insensitive substring match of term on any of columns.
+// An empty term matches everything.
+func (q *Query) Search(term string, columns ...string) *Query {
+	if term == "" || len(columns) == 0 {
+		return q
+	}
+
+	placeholder := q.Bind("%" + EscapeLike(strings.ToLower(term)) + "%")
+
+	matches := make([]string, len(columns))
+	for i, column := range columns {
+		matches[i] = fmt.Sprintf(`LOWER(%s) LIKE %s`, column, placeholder)
+	}
+
+	q.conditions = append(q.conditions, "("+strings.Join(matches, " OR ")+")")
+	return q
+}
+
+// Match adds the conditions whose list parameter is set in param, a struct
+// of form tagged fields. A parameter is set when it is not a nil pointer or
+// the zero value.
+func (q *Query) Match(param interface{}, conditions ...Condition) *Query {
+	v := reflect.Indirect(reflect.ValueOf(param))
+
+	for _, condition := range conditions {
+		value, ok := formValue(v, condition.Param)
+		if !ok {
+			panic(fmt.Sprintf("util: %s has no %q parameter", v.Type(), condition.Param))
+		}
+
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		} else if value.IsZero() {
+			continue
+		}
+
+		q.Where(condition.SQL, value.Interface())
+	}
+
+	return q
+}
+
+// formValue finds the field of v, or of a struct embedded in it, whose form
+// tag is name.
+func formValue(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if value, ok := formValue(v.Field(i), name); ok {
+				return value, true
 			}
+			continue
+		}
+
+		if strings.Split(field.Tag.Get("form"), ",")[0] == name {
+			return v.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// After adds the keyset condition that continues a listing sorted by sort
+// behind the row whose sort columns hold values.
+func (q *Query) After(sort []SortField, values ...interface{}) *Query {
+	columns := make([]string, len(sort))
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		columns[i] = sort[i].Column
+		placeholders[i] = q.Bind(value)
+	}
+
+	if len(sort) == 1 {
+		q.conditions = append(q.conditions, fmt.Sprintf(`%s %s %s`,
+			columns[0], keysetOperator(sort[0].Desc), placeholders[0]))
+		return q
+	}
+
+	if sameDirection(sort) {
+		q.conditions = append(q.conditions, fmt.Sprintf(`(%s) %s (%s)`,
+			strings.Join(columns, ", "), keysetOperator(sort[0].Desc), strings.Join(placeholders, ", ")))
+		return q
+	}
+
+	// Rows can't be compared as a whole when the directions differ, a row
+	// comes later when it ties on the leading columns and is behind on the
+	// next one.
+	alternatives := make([]string, len(sort))
+	for i := range sort {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, fmt.Sprintf(`%s = %s`, columns[j], placeholders[j]))
 		}
+		terms = append(terms, fmt.Sprintf(`%s %s %s`, columns[i], keysetOperator(sort[i].Desc), placeholders[i]))
+
+		alternatives[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+
+	q.conditions = append(q.conditions, "("+strings.Join(alternatives, " OR ")+")")
+	return q
+}
+
+// OrderBy sorts by sort, whose columns have to come from a SortSpec.
+func (q *Query) OrderBy(sort []SortField) *Query {
+	for _, field := range sort {
+		direction := "ASC"
+		if field.Desc {
+			direction = "DESC"
+		}
+		q.orderBy = append(q.orderBy, field.Column+" "+direction)
+	}
+
+	return q
+}
+
+// Page limits the rows to limit rows after offset.
+func (q *Query) Page(limit, offset int) *Query {
+	q.limit = fmt.Sprintf(`LIMIT %s OFFSET %s`, q.Bind(limit), q.Bind(offset))
+	return q
+}
+
+// String returns the clauses to append to the SELECT ... FROM of the
+// statement.
+func (q *Query) String() string {
+	var clauses []string
+
+	if len(q.conditions) != 0 {
+		clauses = append(clauses, "WHERE "+strings.Join(q.conditions, " AND "))
+	}
+
+	if len(q.orderBy) != 0 {
+		clauses = append(clauses, "ORDER BY "+strings.Join(q.orderBy, ", "))
 	}
-	return filtersQuery
+
+	if q.limit != "" {
+		clauses = append(clauses, q.limit)
+	}
+
+	return strings.Join(clauses, "\n")
+}
+
+// Args returns the values bound to the placeholders, in order.
+func (q *Query) Args() []interface{} {
+	return q.args
+}
+
+// EscapeLike escapes the wildcards of a LIKE pattern, so s only matches
+// itself.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SortOrder returns ASC or DESC for order, fallback for anything else. The
+// result is safe to put into a query.
+func SortOrder(order string, fallback string) string {
+	switch strings.ToUpper(order) {
+	case "ASC":
+		return "ASC"
+	case "DESC":
+		return "DESC"
+	}
+
+	return fallback
+}
+
+// keysetOperator compares a row with the sort key of a cursor, so a page
+// continues behind the cursor.
+func keysetOperator(desc bool) string {
+	if desc {
+		return "<"
+	}
+
+	return ">"
+}
+
+func sameDirection(sort []SortField) bool {
+	for _, field := range sort {
+		if field.Desc != sort[0].Desc {
+			return false
+		}
+	}
+
+	return true
 }
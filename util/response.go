@@ -1,8 +1,81 @@
 package util
 
+import (
+	"net/url"
+	"strconv"
+)
+
 type (
+	// Response wraps every JSON answer. Failed requests carry a stable Code
+	// and human readable Errors, list requests a Meta block.
 	Response struct {
 		Data   interface{} `json:"data,omitempty"`
+		Meta   *Meta       `json:"meta,omitempty"`
+		Code   string      `json:"code,omitempty"`
 		Errors []string    `json:"errors,omitempty"`
 	}
+
+	// Meta describes a page of a list. Next and Prev link to the neighbouring
+	// pages and are left out at either end. NextCursor continues after the
+	// last item of the page, cursor pages only link forward.
+	Meta struct {
+		Total      int    `json:"total"`
+		Limit      int    `json:"limit"`
+		Offset     int    `json:"offset"`
+		NextCursor string `json:"next_cursor,omitempty"`
+		Next       string `json:"next,omitempty"`
+		Prev       string `json:"prev,omitempty"`
+	}
 )
+
+// NewMeta describes the page of filter out of total items, nextCursor is
+// empty on the last page. The links repeat the request u with only the page
+// parameters changed, a request by cursor gets cursor links.
+func NewMeta(u *url.URL, filter Filter, total int, nextCursor string) *Meta {
+	meta := &Meta{
+		Total:      total,
+		Limit:      filter.Limit,
+		Offset:     filter.Offset,
+		NextCursor: nextCursor,
+	}
+
+	if filter.Cursor != "" {
+		if nextCursor != "" {
+			meta.Next = cursorLink(u, filter.Limit, nextCursor)
+		}
+		return meta
+	}
+
+	if filter.Offset+filter.Limit < total {
+		meta.Next = pageLink(u, filter.Limit, filter.Offset+filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		prev := filter.Offset - filter.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		meta.Prev = pageLink(u, filter.Limit, prev)
+	}
+
+	return meta
+}
+
+func pageLink(u *url.URL, limit, offset int) string {
+	query := u.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+
+	link := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return link.String()
+}
+
+func cursorLink(u *url.URL, limit int, cursor string) string {
+	query := u.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("cursor", cursor)
+	query.Del("offset")
+
+	link := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return link.String()
+}
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidSort is returned for a sort on a field the listing does not
+// allow, or on the same field twice.
+var ErrInvalidSort = errors.New("invalid sort")
+
+type (
+	// SortField is one field of a sort, -balance sorts by balance descending.
+	SortField struct {
+		Column string
+		Desc   bool
+	}
+
+	// SortSpec declares what a listing can be sorted by. The first of Fields
+	// is the default. Unique is appended to every sort so rows never tie,
+	// which keyset pages rely on.
+	SortSpec struct {
+		Fields []string
+		Unique string
+		// Order is the direction of the default sort, ASC or DESC.
+		Order string
+	}
+)
+
+// Parse reads a sort like "-balance,account_number". Without one the listing
+// is sorted by the default field in order, which falls back to s.Order.
+func (s SortSpec) Parse(sort string, order string) ([]SortField, error) {
+	if sort == "" {
+		desc := SortOrder(order, s.Order) == "DESC"
+		return s.unique([]SortField{{Column: s.Fields[0], Desc: desc}}), nil
+	}
+
+	var fields []SortField
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+
+		field := SortField{Column: strings.TrimPrefix(name, "-"), Desc: strings.HasPrefix(name, "-")}
+		if !s.allows(field.Column) || seen[field.Column] {
+			return nil, ErrInvalidSort
+		}
+		seen[field.Column] = true
+
+		fields = append(fields, field)
+	}
+
+	return s.unique(fields), nil
+}
+
+// FormatSort writes sort the way Parse reads it. Cursors keep it to tell
+// which sort they belong to.
+func FormatSort(sort []SortField) string {
+	names := make([]string, len(sort))
+	for i, field := range sort {
+		names[i] = field.Column
+		if field.Desc {
+			names[i] = "-" + field.Column
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
+// unique breaks ties by Unique in the direction of the last field.
+func (s SortSpec) unique(fields []SortField) []SortField {
+	last := fields[len(fields)-1]
+	for _, field := range fields {
+		if field.Column == s.Unique {
+			return fields
+		}
+	}
+
+	return append(fields, SortField{Column: s.Unique, Desc: last.Desc})
+}
+
+func (s SortSpec) allows(column string) bool {
+	for _, field := range s.Fields {
+		if field == column {
+			return true
+		}
+	}
+
+	return false
+}